@@ -0,0 +1,217 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// VWAPResult holds an anchored VWAP with its volume-weighted standard
+// deviation and the bands at 1, 2 and 3 deviations around it.
+type VWAPResult struct {
+	VWAP   []float64
+	StdDev []float64
+	Upper1 []float64
+	Lower1 []float64
+	Upper2 []float64
+	Lower2 []float64
+	Upper3 []float64
+	Lower3 []float64
+}
+
+// AnchoredVWAP calculates the VWAP of price accumulated from each anchor
+// index, restarting at the next one. Bars before the first anchor are NaN.
+// With no anchors the VWAP is anchored at the first bar. Anchors must be in
+// ascending order.
+func AnchoredVWAP(price, volume []float64, anchors ...int) VWAPResult {
+	n := len(price)
+	if n != len(volume) {
+		return VWAPResult{}
+	}
+	if len(anchors) == 0 {
+		anchors = []int{0}
+	}
+	reset := make([]bool, n)
+	for _, a := range anchors {
+		if a >= 0 && a < n {
+			reset[a] = true
+		}
+	}
+	r := newVWAPResult(n)
+	s := NewAnchoredVWAP()
+	started := false
+	for i := 0; i < n; i++ {
+		if reset[i] {
+			s.Anchor()
+			started = true
+		}
+		if !started {
+			r.set(i, VWAPPoint{VWAP: math.NaN(), StdDev: math.NaN()})
+			continue
+		}
+		p, _ := s.Update(price[i], volume[i])
+		r.set(i, p)
+	}
+	return r
+}
+
+// AnchoredVWAPChecked is like AnchoredVWAP but reports invalid input as an
+// error instead of returning an empty result.
+func AnchoredVWAPChecked(price, volume []float64, anchors ...int) (VWAPResult, error) {
+	if err := firstError(
+		checkLengths("AnchoredVWAP", len(price), len(volume)),
+		checkAnchors("AnchoredVWAP", len(price), anchors),
+		checkData("AnchoredVWAP", len(price), AnchoredVWAPLookback(anchors...)),
+	); err != nil {
+		return VWAPResult{}, err
+	}
+	return AnchoredVWAP(price, volume, anchors...), nil
+}
+
+// AnchoredVWAPBars calculates AnchoredVWAP of the source price of b.
+func AnchoredVWAPBars(b *Bars, source PriceSource, anchors ...int) (VWAPResult, error) {
+	price, err := source.Series(b)
+	if err != nil {
+		return VWAPResult{}, err
+	}
+	return AnchoredVWAPChecked(price, b.volume, anchors...)
+}
+
+// AnchoredVWAPLookback returns the number of leading warm-up values of
+// AnchoredVWAP, the index of the first anchor.
+func AnchoredVWAPLookback(anchors ...int) int {
+	if len(anchors) == 0 {
+		return 0
+	}
+	return anchors[0]
+}
+
+// checkAnchors reports ErrInvalidPeriod unless anchors are ascending
+// indices of a series of n values.
+func checkAnchors(fn string, n int, anchors []int) error {
+	for i, a := range anchors {
+		if a < 0 || a >= n || (i > 0 && a <= anchors[i-1]) {
+			return &InputError{Func: fn, Err: ErrInvalidPeriod, Detail: fmt.Sprintf("anchor %d must be an ascending index below %d", a, n)}
+		}
+	}
+	return nil
+}
+
+// SessionVWAP calculates the VWAP of the source price of b, restarting at
+// each session open. Bars outside the session are NaN. b must have a Time
+// column in ascending order.
+func SessionVWAP(b *Bars, source PriceSource, session Session) (VWAPResult, error) {
+	if !b.HasTime() {
+		return VWAPResult{}, &InputError{Func: "SessionVWAP", Err: ErrMissingColumn, Detail: "Time"}
+	}
+	price, err := source.Series(b)
+	if err != nil {
+		return VWAPResult{}, err
+	}
+	if session.Location == nil {
+		session.Location = time.UTC
+	}
+	n := b.Len()
+	r := newVWAPResult(n)
+	s := NewAnchoredVWAP()
+	var current time.Time
+	for i, t := range b.time {
+		if i > 0 && t.Before(b.time[i-1]) {
+			return VWAPResult{}, &InputError{Func: "SessionVWAP", Err: ErrUnsortedTime, Detail: fmt.Sprintf("bar %d", i)}
+		}
+		start, _, ok := session.period(t, 24*time.Hour)
+		if !ok {
+			r.set(i, VWAPPoint{VWAP: math.NaN(), StdDev: math.NaN()})
+			continue
+		}
+		if !start.Equal(current) {
+			current = start
+			s.Anchor()
+		}
+		p, _ := s.Update(price[i], b.volume[i])
+		r.set(i, p)
+	}
+	return r, nil
+}
+
+func newVWAPResult(n int) VWAPResult {
+	return VWAPResult{
+		VWAP:   make([]float64, n),
+		StdDev: make([]float64, n),
+		Upper1: make([]float64, n),
+		Lower1: make([]float64, n),
+		Upper2: make([]float64, n),
+		Lower2: make([]float64, n),
+		Upper3: make([]float64, n),
+		Lower3: make([]float64, n),
+	}
+}
+
+func (r VWAPResult) set(i int, p VWAPPoint) {
+	r.VWAP[i], r.StdDev[i] = p.VWAP, p.StdDev
+	r.Upper1[i], r.Lower1[i] = p.VWAP+p.StdDev, p.VWAP-p.StdDev
+	r.Upper2[i], r.Lower2[i] = p.VWAP+2*p.StdDev, p.VWAP-2*p.StdDev
+	r.Upper3[i], r.Lower3[i] = p.VWAP+3*p.StdDev, p.VWAP-3*p.StdDev
+}
+
+// Columns returns the VWAP, its deviation and bands as named columns.
+func (r VWAPResult) Columns() []Column {
+	return []Column{
+		{Name: "vwap", Values: r.VWAP},
+		{Name: "vwap_stddev", Values: r.StdDev},
+		{Name: "vwap_upper1", Values: r.Upper1},
+		{Name: "vwap_lower1", Values: r.Lower1},
+		{Name: "vwap_upper2", Values: r.Upper2},
+		{Name: "vwap_lower2", Values: r.Lower2},
+		{Name: "vwap_upper3", Values: r.Upper3},
+		{Name: "vwap_lower3", Values: r.Lower3},
+	}
+}
+
+// VWAPPoint holds the anchored VWAP and its standard deviation for a single
+// bar. The bands are VWAP ± k*StdDev.
+type VWAPPoint struct {
+	VWAP   float64
+	StdDev float64
+}
+
+// AnchoredVWAPStream is the streaming counterpart of AnchoredVWAP. The mean
+// and variance are updated with the weighted form of Welford's algorithm,
+// which avoids the cancellation of accumulating price squared times volume.
+type AnchoredVWAPStream struct {
+	weight, mean, m2 float64
+}
+
+// NewAnchoredVWAP returns a streaming VWAP anchored at the first bar.
+func NewAnchoredVWAP() *AnchoredVWAPStream {
+	return &AnchoredVWAPStream{}
+}
+
+// Anchor restarts the VWAP at the next bar.
+func (s *AnchoredVWAPStream) Anchor() { s.Reset() }
+
+// Update adds a bar and returns the VWAP since the anchor. Both values are
+// NaN until some volume has traded.
+func (s *AnchoredVWAPStream) Update(price, volume float64) (VWAPPoint, bool) {
+	if volume > 0 {
+		s.weight += volume
+		delta := price - s.mean
+		s.mean += volume / s.weight * delta
+		s.m2 += volume * delta * (price - s.mean)
+	}
+	if !s.Ready() {
+		return VWAPPoint{VWAP: math.NaN(), StdDev: math.NaN()}, false
+	}
+	return VWAPPoint{VWAP: s.mean, StdDev: math.Sqrt(math.Max(s.m2/s.weight, 0))}, true
+}
+
+// Ready reports whether any volume has traded since the anchor.
+func (s *AnchoredVWAPStream) Ready() bool { return s.weight > 0 }
+
+// Reset clears the accumulated volume and prices.
+func (s *AnchoredVWAPStream) Reset() {
+	s.weight, s.mean, s.m2 = 0, 0, 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *AnchoredVWAPStream) Lookback() int { return 0 }
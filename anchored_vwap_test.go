@@ -0,0 +1,69 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAnchoredVWAP(t *testing.T) {
+	price := []float64{10, 11, 12, 13, 14}
+	volume := []float64{1, 2, 3, 4, 5}
+	r := AnchoredVWAP(price, volume, 1, 3)
+	want := []float64{
+		math.NaN(),
+		11,
+		(11*2 + 12*3) / 5.0,
+		13, // restarted at the second anchor
+		(13*4 + 14*5) / 9.0,
+	}
+	assertClose(t, "vwap", r.VWAP, want, 1e-12)
+	if r.StdDev[1] != 0 {
+		t.Errorf("stddev at the anchor = %v, want 0", r.StdDev[1])
+	}
+}
+
+func TestSessionVWAPRestartsEachSession(t *testing.T) {
+	b := testBars(72) // hourly bars over three days
+	r, err := SessionVWAP(b, SourceClose, Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tm := range b.time {
+		if tm.Hour() == 0 && r.VWAP[i] != b.close[i] {
+			t.Errorf("vwap[%d] at midnight = %v, want the close %v", i, r.VWAP[i], b.close[i])
+		}
+	}
+
+	noTime := &Bars{high: b.high, low: b.low, close: b.close, volume: b.volume}
+	if _, err := SessionVWAP(noTime, SourceClose, Session{Length: time.Hour}); err == nil {
+		t.Error("SessionVWAP without times succeeded")
+	}
+}
+
+func TestVWAPDescriptorSource(t *testing.T) {
+	b := testBars(100)
+	for _, name := range []string{"anchored_vwap", "session_vwap"} {
+		d, _ := Lookup(name)
+		for _, source := range []PriceSource{SourceHLC3, SourceClose, SourceHL2} {
+			p, err := d.Resolve(Params{"source": source.String()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cols, err := d.Compute(b, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want VWAPResult
+			if name == "anchored_vwap" {
+				want, err = AnchoredVWAPBars(b, source, 0)
+			} else {
+				want, err = SessionVWAP(b, source, Session{})
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, name+" "+source.String(), cols[0].Values, want.VWAP, 0)
+		}
+	}
+}
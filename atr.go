@@ -0,0 +1,115 @@
+package indicators
+
+import "math"
+
+// ATRMethod selects how the true range is smoothed into an Average True
+// Range.
+type ATRMethod int
+
+const (
+	// ATRWilder is Wilder's smoothing (RMA) of TA-Lib's ATR, seeded with the
+	// SMA of the first true ranges. The first bar has no true range.
+	ATRWilder ATRMethod = iota
+	// ATRSimple is the SMA of the true range, as ATRSMA.
+	ATRSimple
+	// ATRExponential is the EMA of the true range, seeded with its SMA.
+	ATRExponential
+)
+
+// String returns the method name, e.g. "wilder".
+func (m ATRMethod) String() string {
+	switch m {
+	case ATRWilder:
+		return "wilder"
+	case ATRSimple:
+		return "sma"
+	case ATRExponential:
+		return "ema"
+	}
+	return "unknown"
+}
+
+// ParseATRMethod returns the ATRMethod named name, as returned by String.
+// "rma" is accepted for ATRWilder.
+func ParseATRMethod(name string) (ATRMethod, bool) {
+	if name == "rma" {
+		return ATRWilder, true
+	}
+	for m := ATRWilder; m <= ATRExponential; m++ {
+		if m.String() == name {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// orWilder returns m, or ATRWilder if m is unknown.
+func (m ATRMethod) orWilder() ATRMethod {
+	if m < ATRWilder || m > ATRExponential {
+		return ATRWilder
+	}
+	return m
+}
+
+// lookback returns the number of leading warm-up values of an ATR of period
+// smoothed with m. Unknown methods are ATRWilder, as for newATRKernel.
+func (m ATRMethod) lookback(period int) int {
+	if m.orWilder() == ATRWilder {
+		return period
+	}
+	return period - 1
+}
+
+// atrKernel is an incremental ATR. Values during warm-up are 0, as TA-Lib
+// reports them.
+type atrKernel interface {
+	update(high, low, close float64) (float64, bool)
+	reset()
+}
+
+// newATRKernel returns an ATR of period smoothed with method. Unknown methods
+// fall back to ATRWilder.
+func newATRKernel(method ATRMethod, period int) atrKernel {
+	switch method.orWilder() {
+	case ATRSimple:
+		return atrSMAKernel{NewATRSMA(period)}
+	case ATRExponential:
+		return &atrEMAKernel{ema: newTalibEMA(period)}
+	}
+	return newTalibATR(period)
+}
+
+// atrSMAKernel adapts ATRSMAStream to atrKernel.
+type atrSMAKernel struct{ *ATRSMAStream }
+
+func (k atrSMAKernel) update(high, low, close float64) (float64, bool) {
+	v, ok := k.Update(high, low, close)
+	if !ok {
+		return 0, false
+	}
+	return v, true
+}
+
+func (k atrSMAKernel) reset() { k.Reset() }
+
+// atrEMAKernel smooths the true range with an EMA. Like ATRSMA, the true
+// range of the first bar is its high-low range.
+type atrEMAKernel struct {
+	ema       *talibEMA
+	prevClose float64
+	seen      bool
+}
+
+func (k *atrEMAKernel) update(high, low, close float64) (float64, bool) {
+	tr := high - low
+	if k.seen {
+		tr = math.Max(tr, math.Max(math.Abs(high-k.prevClose), math.Abs(low-k.prevClose)))
+	}
+	k.prevClose, k.seen = close, true
+	return k.ema.update(tr)
+}
+
+func (k *atrEMAKernel) reset() {
+	k.ema.reset()
+	k.prevClose, k.seen = 0, false
+}
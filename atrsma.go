@@ -29,17 +29,85 @@ func ATRSMA(highs, lows, closes []float64, period int) []float64 {
 
 	// Compute simple moving average over trueRanges with padding
 	atr := make([]float64, n)
+	sum := newRollingSum(period)
 	for i := 0; i < n; i++ {
+		sum.push(trueRanges[i])
 		if i < period-1 {
 			atr[i] = math.NaN() // not enough data
 			continue
 		}
-		sum := 0.0
-		for j := i - period + 1; j <= i; j++ {
-			sum += trueRanges[j]
-		}
-		atr[i] = sum / float64(period)
+		atr[i] = sum.value() / float64(period)
 	}
 
 	return atr
 }
+
+// ATRSMAChecked is like ATRSMA but reports invalid input as an error.
+func ATRSMAChecked(highs, lows, closes []float64, period int) ([]float64, error) {
+	if err := firstError(
+		checkLengths("ATRSMA", len(highs), len(lows), len(closes)),
+		checkPeriod("ATRSMA", "period", period, 1),
+		checkData("ATRSMA", len(highs), ATRSMALookback(period)),
+	); err != nil {
+		return nil, err
+	}
+	return ATRSMA(highs, lows, closes, period), nil
+}
+
+// ATRSMABars calculates ATRSMA over b.
+func ATRSMABars(b *Bars, period int) ([]float64, error) {
+	return ATRSMAChecked(b.high, b.low, b.close, period)
+}
+
+// ATRSMALookback returns the number of leading warm-up values of ATRSMA.
+func ATRSMALookback(period int) int {
+	return period - 1
+}
+
+// ATRSMAStream is the streaming counterpart of ATRSMA.
+type ATRSMAStream struct {
+	period     int
+	trueRanges *rollingSum
+	prevClose  float64
+	seen       int
+}
+
+// NewATRSMA returns a streaming SMA-smoothed Average True Range.
+func NewATRSMA(period int) *ATRSMAStream {
+	if period <= 0 {
+		panic("period must be greater than 0")
+	}
+	return &ATRSMAStream{period: period, trueRanges: newRollingSum(period)}
+}
+
+// Update adds a bar and returns the current ATR, or NaN during warm-up.
+func (s *ATRSMAStream) Update(high, low, close float64) (float64, bool) {
+	highLow := high - low
+	if s.seen > 0 {
+		highClose := math.Abs(high - s.prevClose)
+		lowClose := math.Abs(low - s.prevClose)
+		s.trueRanges.push(math.Max(highLow, math.Max(highClose, lowClose)))
+	} else {
+		s.trueRanges.push(highLow) // No previous close, so just use high - low
+	}
+	s.prevClose = close
+	s.seen++
+
+	if !s.trueRanges.full() {
+		return math.NaN(), false
+	}
+	return s.trueRanges.value() / float64(s.period), true
+}
+
+// Ready reports whether a full window has been seen.
+func (s *ATRSMAStream) Ready() bool { return s.trueRanges.full() }
+
+// Reset clears the window.
+func (s *ATRSMAStream) Reset() {
+	s.trueRanges.reset()
+	s.prevClose = 0
+	s.seen = 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *ATRSMAStream) Lookback() int { return s.period - 1 }
@@ -0,0 +1,323 @@
+// Package backtest simulates trading a strategy given as signal series over
+// indicators.Bars and reports its equity curve, trades and statistics.
+//
+// Signals are evaluated at the close of a bar. By default orders fill at the
+// open of the next bar, so a strategy cannot trade on a close it has not yet
+// seen; FillClose fills at the signal bar's close instead. At most one
+// position is held at a time.
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/blazer-org/indicators"
+)
+
+// ErrInvalidConfig reports a Config with out-of-range values.
+var ErrInvalidConfig = errors.New("backtest: invalid config")
+
+// Fill selects the price at which orders are filled.
+type Fill int
+
+const (
+	// FillNextOpen fills at the open of the bar after the signal.
+	FillNextOpen Fill = iota
+	// FillClose fills at the close of the signal bar.
+	FillClose
+)
+
+// Sizing selects how Config.Size is interpreted.
+type Sizing int
+
+const (
+	// SizePercentEquity invests the fraction Size of the current equity.
+	SizePercentEquity Sizing = iota
+	// SizeFixedCash invests Size units of cash.
+	SizeFixedCash
+	// SizeFixedQuantity trades Size units of the instrument.
+	SizeFixedQuantity
+)
+
+// Config holds the parameters of a backtest.
+type Config struct {
+	InitialCash float64 // starting equity; must be positive
+	Fill        Fill
+
+	Sizing     Sizing
+	Size       float64 // fraction, cash or quantity depending on Sizing
+	WholeUnits bool    // round quantities down to whole units
+
+	Commission         float64 // fraction of the traded notional, e.g. 0.001
+	CommissionPerTrade float64 // fixed amount per fill
+	Slippage           float64 // adverse price move per fill as a fraction, e.g. 0.0005
+
+	// PeriodsPerYear annualizes the Sharpe ratio, e.g. 252 for daily bars.
+	// If 0 the ratio is per bar.
+	PeriodsPerYear float64
+}
+
+// DefaultConfig returns a Config investing all of 10000 units of cash per
+// trade without costs, filling at the next open.
+func DefaultConfig() Config {
+	return Config{InitialCash: 10000, Sizing: SizePercentEquity, Size: 1}
+}
+
+func (c Config) validate() error {
+	switch {
+	case !(c.InitialCash > 0):
+		return fmt.Errorf("%w: InitialCash must be positive", ErrInvalidConfig)
+	case !(c.Size > 0):
+		return fmt.Errorf("%w: Size must be positive", ErrInvalidConfig)
+	case c.Sizing == SizePercentEquity && c.Size > 1:
+		return fmt.Errorf("%w: Size must be at most 1 with SizePercentEquity", ErrInvalidConfig)
+	case c.Commission < 0 || c.CommissionPerTrade < 0 || c.Slippage < 0:
+		return fmt.Errorf("%w: costs must not be negative", ErrInvalidConfig)
+	case c.Slippage >= 1:
+		return fmt.Errorf("%w: Slippage must be below 1", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Side is the direction of a trade.
+type Side int
+
+const (
+	Long  Side = 1
+	Short Side = -1
+)
+
+// String returns "long" or "short".
+func (s Side) String() string {
+	if s == Short {
+		return "short"
+	}
+	return "long"
+}
+
+// Trade is a round trip. Prices include slippage and Commission is the total
+// paid on both fills.
+type Trade struct {
+	Side       Side
+	EntryIndex int
+	ExitIndex  int
+	EntryTime  time.Time // zero unless the bars have a Time column
+	ExitTime   time.Time
+	EntryPrice float64
+	ExitPrice  float64
+	Quantity   float64
+	Commission float64
+	PnL        float64 // net of commission
+	Return     float64 // PnL relative to the entry notional
+}
+
+// Result is the outcome of a backtest.
+type Result struct {
+	// Equity is the cash plus the open position marked at each bar's close.
+	Equity []float64
+	// Position is the signed quantity held at each bar's close.
+	Position []float64
+	// Trades lists the closed trades in order.
+	Trades []Trade
+	// OpenTrade is the position still open after the last bar, marked at its
+	// close, or nil.
+	OpenTrade *Trade
+	Stats     Stats
+}
+
+// Run backtests s over b with cfg.
+func Run(b *indicators.Bars, s Signals, cfg Config) (*Result, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	n := b.Len()
+	lengths := []int{n, len(s.Entry), len(s.Exit)}
+	if s.ShortEntry != nil {
+		lengths = append(lengths, len(s.ShortEntry))
+	}
+	if s.ShortExit != nil {
+		lengths = append(lengths, len(s.ShortExit))
+	}
+	for _, l := range lengths[1:] {
+		if l != n {
+			return nil, &indicators.InputError{
+				Func:   "backtest.Run",
+				Err:    indicators.ErrLengthMismatch,
+				Detail: fmt.Sprint("bars and signal lengths ", lengths),
+			}
+		}
+	}
+	if cfg.Fill == FillNextOpen && !b.HasOpen() {
+		return nil, &indicators.InputError{
+			Func:   "backtest.Run",
+			Err:    indicators.ErrMissingColumn,
+			Detail: "FillNextOpen requires Open",
+		}
+	}
+
+	bt := &backtester{bars: b, cfg: cfg, cash: cfg.InitialCash}
+	res := &Result{Equity: make([]float64, n), Position: make([]float64, n)}
+
+	open, close := b.Open(), b.Close()
+	// Orders decided at the close of bar i-1 are pending until bar i.
+	var pending []Side
+	for i := 0; i < n; i++ {
+		if cfg.Fill == FillNextOpen {
+			for _, side := range pending {
+				bt.execute(side, i, open[i], res)
+			}
+			pending = pending[:0]
+		}
+
+		orders := bt.decide(s, i)
+		if cfg.Fill == FillClose {
+			for _, side := range orders {
+				bt.execute(side, i, close[i], res)
+			}
+		} else {
+			pending = append(pending, orders...)
+		}
+
+		res.Position[i] = bt.pos
+		res.Equity[i] = bt.cash + bt.pos*close[i]
+	}
+
+	if bt.open != nil && n > 0 {
+		t := *bt.open
+		bt.closeTrade(&t, n-1, close[n-1], 0)
+		res.OpenTrade = &t
+	}
+	res.Stats = computeStats(res, cfg)
+	return res, nil
+}
+
+// backtester holds the state of a running backtest.
+type backtester struct {
+	bars *indicators.Bars
+	cfg  Config
+	cash float64
+	pos  float64
+	open *Trade
+}
+
+// decide returns the orders triggered by the signals at bar i: an exit of the
+// current position, an entry, or both to reverse it. Entries that conflict
+// with an exit of the same side are ignored. The orders of earlier bars have
+// all been executed by then, so the position is that of the open trade; an
+// entry that could not be filled leaves it flat and a later signal retries.
+func (bt *backtester) decide(s Signals, i int) []Side {
+	long := active(s.Entry, i) && !active(s.Exit, i)
+	short := active(s.ShortEntry, i) && !active(s.ShortExit, i)
+	if long && short {
+		long, short = false, false
+	}
+
+	var orders []Side
+	var current Side
+	if bt.open != nil {
+		current = bt.open.Side
+	}
+	switch current {
+	case Long:
+		if active(s.Exit, i) || short {
+			orders = append(orders, 0)
+			current = 0
+		}
+	case Short:
+		if active(s.ShortExit, i) || long {
+			orders = append(orders, 0)
+			current = 0
+		}
+	}
+	if current == 0 {
+		switch {
+		case long:
+			orders = append(orders, Long)
+			current = Long
+		case short:
+			orders = append(orders, Short)
+			current = Short
+		}
+	}
+	return orders
+}
+
+// execute fills an order at price: side 0 closes the open position, Long and
+// Short open one.
+func (bt *backtester) execute(side Side, i int, price float64, res *Result) {
+	if side == 0 {
+		if bt.open == nil {
+			return
+		}
+		fill := bt.slipped(price, -bt.open.Side)
+		fee := bt.fee(bt.open.Quantity * fill)
+		bt.cash += bt.pos*fill - fee
+		bt.closeTrade(bt.open, i, fill, fee)
+		res.Trades = append(res.Trades, *bt.open)
+		bt.open, bt.pos = nil, 0
+		return
+	}
+
+	fill := bt.slipped(price, side)
+	qty := bt.quantity(fill)
+	if !(qty > 0) {
+		return
+	}
+	fee := bt.fee(qty * fill)
+	bt.pos = float64(side) * qty
+	bt.cash -= bt.pos*fill + fee
+	bt.open = &Trade{
+		Side:       side,
+		EntryIndex: i,
+		EntryTime:  bt.timeAt(i),
+		EntryPrice: fill,
+		Quantity:   qty,
+		Commission: fee,
+	}
+}
+
+// closeTrade completes t with an exit at bar i.
+func (bt *backtester) closeTrade(t *Trade, i int, price, fee float64) {
+	t.ExitIndex = i
+	t.ExitTime = bt.timeAt(i)
+	t.ExitPrice = price
+	t.Commission += fee
+	t.PnL = float64(t.Side)*t.Quantity*(t.ExitPrice-t.EntryPrice) - t.Commission
+	t.Return = t.PnL / (t.Quantity * t.EntryPrice)
+}
+
+// slipped moves price against an order on side.
+func (bt *backtester) slipped(price float64, side Side) float64 {
+	return price * (1 + float64(side)*bt.cfg.Slippage)
+}
+
+func (bt *backtester) fee(notional float64) float64 {
+	return math.Abs(notional)*bt.cfg.Commission + bt.cfg.CommissionPerTrade
+}
+
+// quantity returns the size of a new position filled at price. Positions
+// sized from cash leave room for the commission.
+func (bt *backtester) quantity(price float64) float64 {
+	var qty float64
+	switch bt.cfg.Sizing {
+	case SizeFixedQuantity:
+		qty = bt.cfg.Size
+	case SizeFixedCash:
+		qty = (bt.cfg.Size - bt.cfg.CommissionPerTrade) / (price * (1 + bt.cfg.Commission))
+	default:
+		qty = (bt.cash*bt.cfg.Size - bt.cfg.CommissionPerTrade) / (price * (1 + bt.cfg.Commission))
+	}
+	if bt.cfg.WholeUnits {
+		qty = math.Floor(qty)
+	}
+	return qty
+}
+
+func (bt *backtester) timeAt(i int) time.Time {
+	if !bt.bars.HasTime() {
+		return time.Time{}
+	}
+	return bt.bars.Time()[i]
+}
@@ -0,0 +1,158 @@
+package backtest
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/blazer-org/indicators"
+)
+
+// testBars returns bars whose open is the previous close.
+func testBars(t *testing.T, close []float64) *indicators.Bars {
+	t.Helper()
+	n := len(close)
+	open, volume := make([]float64, n), make([]float64, n)
+	for i := range close {
+		open[i] = close[max(i-1, 0)]
+		volume[i] = 1
+	}
+	b, err := indicators.NewBars(indicators.BarColumns{Open: open, High: close, Low: close, Close: close, Volume: volume})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// at returns a signal series of n bars with signals at idx.
+func at(n int, idx ...int) []float64 {
+	s := make([]float64, n)
+	for _, i := range idx {
+		s[i] = 1
+	}
+	return s
+}
+
+func TestRunLongRoundTrip(t *testing.T) {
+	close := []float64{10, 10, 12, 15, 14}
+	b := testBars(t, close)
+	s := Signals{Entry: at(5, 1), Exit: at(5, 3)}
+	cfg := DefaultConfig()
+	cfg.Fill = FillClose
+	res, err := Run(b, s, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Trades) != 1 {
+		t.Fatalf("%d trades, want 1", len(res.Trades))
+	}
+	tr := res.Trades[0]
+	if tr.EntryIndex != 1 || tr.ExitIndex != 3 || tr.EntryPrice != 10 || tr.ExitPrice != 15 {
+		t.Errorf("trade %+v", tr)
+	}
+	if want := 1000 * 5.0; math.Abs(tr.PnL-want) > 1e-9 {
+		t.Errorf("PnL = %v, want %v", tr.PnL, want)
+	}
+	if res.Equity[4] != 15000 || res.Position[2] != 1000 || res.Position[4] != 0 {
+		t.Errorf("equity %v, position %v", res.Equity, res.Position)
+	}
+}
+
+func TestRunFillsAtNextOpen(t *testing.T) {
+	close := []float64{10, 11, 12, 13, 14}
+	b := testBars(t, close)
+	res, err := Run(b, Signals{Entry: at(5, 0), Exit: at(5, 2)}, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := res.Trades[0]
+	if tr.EntryIndex != 1 || tr.EntryPrice != b.Open()[1] || tr.ExitIndex != 3 || tr.ExitPrice != b.Open()[3] {
+		t.Errorf("trade %+v", tr)
+	}
+	if res.Position[0] != 0 {
+		t.Errorf("position at the signal bar = %v, want 0", res.Position[0])
+	}
+}
+
+func TestRunRetriesRejectedEntry(t *testing.T) {
+	// 50 units of cash buy no whole unit at 100, but one at 40.
+	close := []float64{100, 100, 100, 40, 40, 40}
+	b := testBars(t, close)
+	cfg := Config{InitialCash: 1000, Fill: FillClose, Sizing: SizeFixedCash, Size: 50, WholeUnits: true}
+	res, err := Run(b, Signals{Entry: at(6, 1, 3), Exit: make([]float64, 6)}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.OpenTrade == nil || res.OpenTrade.EntryIndex != 3 || res.OpenTrade.Quantity != 1 {
+		t.Errorf("open trade %+v, want one unit entered at bar 3", res.OpenTrade)
+	}
+}
+
+func TestRunEntryPendingOnLastBar(t *testing.T) {
+	b := testBars(t, []float64{10, 11, 12})
+	res, err := Run(b, Signals{Entry: at(3, 2), Exit: make([]float64, 3)}, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.OpenTrade != nil || len(res.Trades) != 0 {
+		t.Errorf("an entry on the last bar filled: %+v", res)
+	}
+}
+
+func TestRunReversesWithShorts(t *testing.T) {
+	close := []float64{10, 12, 11, 8, 9, 10}
+	b := testBars(t, close)
+	s := DirectionSignals([]int{1, 1, -1, -1, 1, 1}, 0, true)
+	cfg := DefaultConfig()
+	cfg.Fill = FillClose
+	res, err := Run(b, s, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Trades) != 2 || res.Trades[0].Side != Long || res.Trades[1].Side != Short {
+		t.Fatalf("trades %+v, want a long then a short", res.Trades)
+	}
+	if short := res.Trades[1]; short.EntryPrice != 11 || short.ExitPrice != 9 || short.PnL <= 0 {
+		t.Errorf("short trade %+v", short)
+	}
+	if res.OpenTrade == nil || res.OpenTrade.Side != Long {
+		t.Errorf("open trade %+v, want a long", res.OpenTrade)
+	}
+}
+
+func TestDirectionSignalsSkipWarmup(t *testing.T) {
+	// The bullish assumption of the first three bars is no entry.
+	s := DirectionSignals([]int{1, 1, 1, -1, -1, 1}, 3, true)
+	for _, c := range []struct {
+		name      string
+		got, want []float64
+	}{
+		{"Entry", s.Entry, at(6, 5)},
+		{"Exit", s.Exit, at(6, 3)},
+		{"ShortEntry", s.ShortEntry, at(6, 3)},
+		{"ShortExit", s.ShortExit, at(6, 5)},
+	} {
+		if !slices.Equal(c.got, c.want) {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	if s := DirectionSignals([]int{1, 1, 1}, 1, false); !slices.Equal(s.Entry, at(3, 1)) || s.ShortEntry != nil {
+		t.Errorf("long only: %+v", s)
+	}
+}
+
+func TestRunSignalLengths(t *testing.T) {
+	b := testBars(t, []float64{10, 11, 12, 13})
+	// Only the short series that are set are checked.
+	if _, err := Run(b, Signals{Entry: at(4), Exit: at(4), ShortEntry: at(4, 1)}, DefaultConfig()); err != nil {
+		t.Errorf("ShortEntry without ShortExit: %v", err)
+	}
+	_, err := Run(b, Signals{Entry: at(4), Exit: at(4), ShortExit: at(3)}, DefaultConfig())
+	if !errors.Is(err, indicators.ErrLengthMismatch) {
+		t.Errorf("short ShortExit: %v, want ErrLengthMismatch", err)
+	}
+	if _, err := Run(b, Signals{Entry: at(4), Exit: at(4)}, Config{}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("zero Config: %v, want ErrInvalidConfig", err)
+	}
+}
@@ -0,0 +1,54 @@
+package backtest
+
+// Signals holds the signal series of a strategy, one value per bar. A value
+// that is neither 0 nor NaN is a signal, so the 1.0/0.0 series emitted by
+// indicators such as InstBlockTrade and HeadShoulders can be used directly.
+// The short series are optional and may be nil.
+type Signals struct {
+	Entry      []float64 // open a long position
+	Exit       []float64 // close a long position
+	ShortEntry []float64 // open a short position
+	ShortExit  []float64 // close a short position
+}
+
+// DirectionSignals derives signals from a trend direction series such as
+// Supertrend's, where 1 is an uptrend and -1 a downtrend. A long position is
+// held during uptrends and, if short is true, a short one during
+// downtrends. The first lookback bars, the warm-up of the indicator such as
+// SupertrendLookback, carry no signals; the position of the direction at the
+// first bar after them is entered there.
+func DirectionSignals(direction []int, lookback int, short bool) Signals {
+	n := len(direction)
+	s := Signals{Entry: make([]float64, n), Exit: make([]float64, n)}
+	if short {
+		s.ShortEntry = make([]float64, n)
+		s.ShortExit = make([]float64, n)
+	}
+	prev := 0
+	for i := max(lookback, 0); i < n; i++ {
+		d := direction[i]
+		if d == 1 && prev != 1 {
+			s.Entry[i] = 1
+			if short {
+				s.ShortExit[i] = 1
+			}
+		}
+		if d == -1 && prev != -1 {
+			s.Exit[i] = 1
+			if short {
+				s.ShortEntry[i] = 1
+			}
+		}
+		prev = d
+	}
+	return s
+}
+
+// active reports whether series has a signal at bar i.
+func active(series []float64, i int) bool {
+	if series == nil {
+		return false
+	}
+	v := series[i]
+	return v != 0 && v == v
+}
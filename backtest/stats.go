@@ -0,0 +1,86 @@
+package backtest
+
+import "math"
+
+// Stats summarizes a backtest. Trade statistics cover closed trades only.
+type Stats struct {
+	InitialEquity float64
+	FinalEquity   float64
+	TotalReturn   float64 // FinalEquity / InitialEquity - 1
+	MaxDrawdown   float64 // largest peak-to-trough decline of the equity, as a fraction
+	Sharpe        float64 // mean over standard deviation of the bar returns, annualized if configured
+	Exposure      float64 // fraction of bars with an open position
+
+	Trades          int
+	WinRate         float64 // fraction of trades with a positive PnL
+	ProfitFactor    float64 // gross profit over gross loss; +Inf without losing trades
+	AvgTradeReturn  float64
+	TotalCommission float64
+}
+
+func computeStats(res *Result, cfg Config) Stats {
+	st := Stats{InitialEquity: cfg.InitialCash, FinalEquity: cfg.InitialCash}
+	if n := len(res.Equity); n > 0 {
+		st.FinalEquity = res.Equity[n-1]
+	}
+	st.TotalReturn = st.FinalEquity/st.InitialEquity - 1
+
+	peak := cfg.InitialCash
+	prev := cfg.InitialCash
+	var sum, sumSq float64
+	var held int
+	for i, eq := range res.Equity {
+		peak = math.Max(peak, eq)
+		if dd := 1 - eq/peak; dd > st.MaxDrawdown {
+			st.MaxDrawdown = dd
+		}
+		r := eq/prev - 1
+		sum += r
+		sumSq += r * r
+		prev = eq
+		if res.Position[i] != 0 {
+			held++
+		}
+	}
+	if n := float64(len(res.Equity)); n > 1 {
+		mean := sum / n
+		variance := (sumSq - n*mean*mean) / (n - 1)
+		if variance > 0 {
+			st.Sharpe = mean / math.Sqrt(variance)
+			if cfg.PeriodsPerYear > 0 {
+				st.Sharpe *= math.Sqrt(cfg.PeriodsPerYear)
+			}
+		}
+	}
+	if len(res.Equity) > 0 {
+		st.Exposure = float64(held) / float64(len(res.Equity))
+	}
+
+	var wins int
+	var grossProfit, grossLoss, sumReturn float64
+	for _, t := range res.Trades {
+		if t.PnL > 0 {
+			wins++
+			grossProfit += t.PnL
+		} else {
+			grossLoss -= t.PnL
+		}
+		sumReturn += t.Return
+		st.TotalCommission += t.Commission
+	}
+	if res.OpenTrade != nil {
+		st.TotalCommission += res.OpenTrade.Commission
+	}
+	st.Trades = len(res.Trades)
+	if st.Trades > 0 {
+		st.WinRate = float64(wins) / float64(st.Trades)
+		st.AvgTradeReturn = sumReturn / float64(st.Trades)
+		switch {
+		case grossLoss > 0:
+			st.ProfitFactor = grossProfit / grossLoss
+		case grossProfit > 0:
+			st.ProfitFactor = math.Inf(1)
+		}
+	}
+	return st
+}
@@ -0,0 +1,118 @@
+package indicators
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bars is a columnar OHLCV series. High, Low, Close and Volume are always
+// present; Time and Open are optional. Bars are built by NewBars, which
+// checks once that all columns have the same length; as the columns cannot
+// be replaced afterwards, functions taking Bars rely on it. The zero value
+// holds no bars.
+type Bars struct {
+	time   []time.Time
+	open   []float64
+	high   []float64
+	low    []float64
+	close  []float64
+	volume []float64
+}
+
+// BarColumns holds the columns NewBars builds Bars from. Time and Open may
+// be nil.
+type BarColumns struct {
+	Time   []time.Time
+	Open   []float64
+	High   []float64
+	Low    []float64
+	Close  []float64
+	Volume []float64
+}
+
+// NewBars checks that the columns of c have the same length and returns
+// them as Bars. The column slices are shared, not copied.
+func NewBars(c BarColumns) (*Bars, error) {
+	n := len(c.Close)
+	for _, col := range []struct {
+		name     string
+		n        int
+		optional bool
+	}{
+		{"Time", len(c.Time), c.Time == nil},
+		{"Open", len(c.Open), c.Open == nil},
+		{"High", len(c.High), false},
+		{"Low", len(c.Low), false},
+		{"Volume", len(c.Volume), false},
+	} {
+		if col.optional {
+			continue
+		}
+		if col.n != n {
+			return nil, &InputError{
+				Func:   "NewBars",
+				Err:    ErrLengthMismatch,
+				Detail: fmt.Sprintf("%s has %d values, Close has %d", col.name, col.n, n),
+			}
+		}
+	}
+	return &Bars{time: c.Time, open: c.Open, high: c.High, low: c.Low, close: c.Close, volume: c.Volume}, nil
+}
+
+// Time returns the time column, or nil if there is none.
+func (b *Bars) Time() []time.Time { return b.time }
+
+// Open returns the open column, or nil if there is none.
+func (b *Bars) Open() []float64 { return b.open }
+
+// High returns the high column.
+func (b *Bars) High() []float64 { return b.high }
+
+// Low returns the low column.
+func (b *Bars) Low() []float64 { return b.low }
+
+// Close returns the close column.
+func (b *Bars) Close() []float64 { return b.close }
+
+// Volume returns the volume column.
+func (b *Bars) Volume() []float64 { return b.volume }
+
+// Len returns the number of bars.
+func (b *Bars) Len() int {
+	return len(b.close)
+}
+
+// HasOpen reports whether the Open column is present.
+func (b *Bars) HasOpen() bool {
+	return b.open != nil
+}
+
+// HasTime reports whether the Time column is present.
+func (b *Bars) HasTime() bool {
+	return b.time != nil
+}
+
+// Slice returns the bars in [i, j), sharing the underlying columns.
+func (b *Bars) Slice(i, j int) *Bars {
+	s := &Bars{
+		high:   b.high[i:j],
+		low:    b.low[i:j],
+		close:  b.close[i:j],
+		volume: b.volume[i:j],
+	}
+	if b.time != nil {
+		s.time = b.time[i:j]
+	}
+	if b.open != nil {
+		s.open = b.open[i:j]
+	}
+	return s
+}
+
+// requireOpen reports ErrMissingColumn if b has no Open column.
+func (b *Bars) requireOpen(fn string) error {
+	if b.open == nil {
+		return &InputError{Func: fn, Err: ErrMissingColumn, Detail: "Open"}
+	}
+	return nil
+}
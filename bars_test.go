@@ -0,0 +1,45 @@
+package indicators
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewBarsChecksLengths(t *testing.T) {
+	_, high, low, close, volume := testOHLCV(10)
+	if _, err := NewBars(BarColumns{High: high, Low: low, Close: close, Volume: volume}); err != nil {
+		t.Fatalf("NewBars without Time and Open: %v", err)
+	}
+	_, err := NewBars(BarColumns{High: high[:9], Low: low, Close: close, Volume: volume})
+	if !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("NewBars with a short High: %v, want ErrLengthMismatch", err)
+	}
+}
+
+func TestNewBarsKeepsLengths(t *testing.T) {
+	_, high, low, close, volume := testOHLCV(50)
+	c := BarColumns{High: high, Low: low, Close: close, Volume: volume}
+	b, err := NewBars(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reslicing the columns passed in does not change the bars.
+	c.Volume = c.Volume[:40]
+	if len(b.Volume()) != 50 || b.Len() != 50 {
+		t.Errorf("bars have %d volumes and length %d, want 50", len(b.Volume()), b.Len())
+	}
+	if b.HasOpen() || b.HasTime() {
+		t.Error("bars without Open and Time report them present")
+	}
+}
+
+func TestBarsSlice(t *testing.T) {
+	b := testBars(20)
+	s := b.Slice(5, 15)
+	if s.Len() != 10 || s.close[0] != b.close[5] || !s.time[0].Equal(b.time[5]) || s.open[9] != b.open[14] {
+		t.Errorf("Slice(5, 15) does not match the bars it covers")
+	}
+	if len(s.high) != 10 || len(s.low) != 10 || len(s.volume) != 10 {
+		t.Errorf("Slice(5, 15) columns have different lengths")
+	}
+}
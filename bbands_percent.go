@@ -1,16 +1,18 @@
 package indicators
 
-import cgotalib "github.com/blazer-org/cgo-talib"
+import "math"
 
+// BbandsPercent calculates Bollinger %B over 20 bars and two standard
+// deviations, in percent. Values during warm-up are NaN.
 func BbandsPercent(close []float64) []float64 {
-	bbUpper, _, bbLower := cgotalib.BBands(close, int32(20), 2.0, 2.0, 0)
+	bbUpper, _, bbLower := taBBands(close, int32(20), 2.0, 2.0, 0)
 
 	var out []float64
 
 	//  %B = (Close - Lower Band) / (Upper Band - Lower Band)
 	for i := range close {
-		if len(bbUpper) <= i || len(bbLower) <= i {
-			out = append(out, 0)
+		if len(bbUpper) <= i || len(bbLower) <= i || i < BbandsPercentLookback() {
+			out = append(out, math.NaN())
 			continue
 		}
 		if bbUpper[i] == bbLower[i] {
@@ -21,3 +23,142 @@ func BbandsPercent(close []float64) []float64 {
 	}
 	return out
 }
+
+// BbandsPercentMA is like BbandsPercent with the middle band a 20 bar
+// average of type t. MASMA is the TA-Lib BBANDS of BbandsPercent; for other
+// types the bands are still two population standard deviations of the
+// window from the middle band, as in TA-Lib.
+func BbandsPercentMA(close []float64, t MAType) []float64 {
+	if t == MASMA {
+		return BbandsPercent(close)
+	}
+	out := make([]float64, len(close))
+	s := NewBbandsPercentMA(t)
+	for i, c := range close {
+		out[i], _ = s.Update(c)
+	}
+	return out
+}
+
+// BbandsPercentChecked is like BbandsPercent but reports insufficient data
+// as an error.
+func BbandsPercentChecked(close []float64) ([]float64, error) {
+	if err := checkData("BbandsPercent", len(close), BbandsPercentLookback()); err != nil {
+		return nil, err
+	}
+	return BbandsPercent(close), nil
+}
+
+// BbandsPercentMAChecked is like BbandsPercentMA but reports invalid input
+// as an error.
+func BbandsPercentMAChecked(close []float64, t MAType) ([]float64, error) {
+	if err := firstError(
+		checkMA("BbandsPercent", "period", 20, t),
+		checkData("BbandsPercent", len(close), BbandsPercentMALookback(t)),
+	); err != nil {
+		return nil, err
+	}
+	return BbandsPercentMA(close, t), nil
+}
+
+// BbandsPercentBars calculates BbandsPercent over the closes of b.
+func BbandsPercentBars(b *Bars) ([]float64, error) {
+	return BbandsPercentChecked(b.close)
+}
+
+// BbandsPercentMABars calculates BbandsPercentMA over the closes of b.
+func BbandsPercentMABars(b *Bars, t MAType) ([]float64, error) {
+	return BbandsPercentMAChecked(b.close, t)
+}
+
+// BbandsPercentLookback returns the number of leading warm-up values of
+// BbandsPercent.
+func BbandsPercentLookback() int {
+	return 19
+}
+
+// BbandsPercentMALookback returns the number of leading warm-up values of
+// BbandsPercentMA.
+func BbandsPercentMALookback(t MAType) int {
+	return max(BbandsPercentLookback(), MALookback(20, t))
+}
+
+// BbandsPercentStream is the streaming counterpart of BbandsPercent.
+type BbandsPercentStream struct {
+	bbands *talibBBands
+	// ma and variance are set instead of bbands for other types than MASMA.
+	ma       *MAStream
+	variance *rollingVar
+}
+
+// NewBbandsPercent returns a streaming Bollinger %B over 20 bars and two
+// standard deviations.
+func NewBbandsPercent() *BbandsPercentStream {
+	return &BbandsPercentStream{bbands: newTalibBBands(20, 2.0, 2.0)}
+}
+
+// NewBbandsPercentMA returns a streaming Bollinger %B with the middle band
+// an average of type t.
+func NewBbandsPercentMA(t MAType) *BbandsPercentStream {
+	if t == MASMA {
+		return NewBbandsPercent()
+	}
+	return &BbandsPercentStream{ma: NewMA(t, 20), variance: newRollingVar(20)}
+}
+
+// Update adds a close and returns the current %B, or NaN during warm-up.
+func (s *BbandsPercentStream) Update(close float64) (float64, bool) {
+	var bbUpper, bbLower float64
+	var ready bool
+	if s.ma != nil {
+		bbUpper, bbLower, ready = s.updateMA(close)
+	} else {
+		bbUpper, _, bbLower, ready = s.bbands.update(close)
+	}
+	if !ready {
+		return math.NaN(), false
+	}
+	if bbUpper == bbLower {
+		return 0, ready
+	}
+	return (close - bbLower) / (bbUpper - bbLower) * 100, ready
+}
+
+// updateMA returns the bands around the average, with the population
+// standard deviation of the window.
+func (s *BbandsPercentStream) updateMA(close float64) (upper, lower float64, ready bool) {
+	middle, maReady := s.ma.Update(close)
+	s.variance.push(close)
+	if !maReady || !s.variance.full() {
+		return 0, 0, false
+	}
+	n := float64(s.variance.window.count)
+	stdDev := math.Sqrt(s.variance.variance() * (n - 1) / n)
+	return middle + 2*stdDev, middle - 2*stdDev, true
+}
+
+// Ready reports whether the bands have left their warm-up period.
+func (s *BbandsPercentStream) Ready() bool {
+	if s.ma != nil {
+		return s.ma.Ready() && s.variance.full()
+	}
+	return s.bbands.sma.window.full()
+}
+
+// Reset clears the bands.
+func (s *BbandsPercentStream) Reset() {
+	if s.ma != nil {
+		s.ma.Reset()
+		s.variance.reset()
+		return
+	}
+	s.bbands.reset()
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *BbandsPercentStream) Lookback() int {
+	if s.ma != nil {
+		return max(BbandsPercentLookback(), s.ma.Lookback())
+	}
+	return BbandsPercentLookback()
+}
@@ -0,0 +1,385 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+)
+
+// CandlePattern selects a candlestick pattern of CandlestickPattern.
+type CandlePattern int
+
+const (
+	CandleDoji               CandlePattern = iota // 1 on a doji
+	CandleDragonflyDoji                           // 1 on a doji with a long lower shadow
+	CandleGravestoneDoji                          // -1 on a doji with a long upper shadow
+	CandleSpinningTop                             // 1 or -1 by color on a small body with longer shadows
+	CandleMarubozu                                // 1 or -1 by color on a long body without shadows
+	CandleHammer                                  // 1 on a long lower shadow after a decline
+	CandleHangingMan                              // -1 on a long lower shadow after a rise
+	CandleInvertedHammer                          // 1 on a long upper shadow after a decline
+	CandleShootingStar                            // -1 on a long upper shadow after a rise
+	CandleEngulfing                               // 1 or -1 when a body engulfs the previous opposite one
+	CandleHarami                                  // 1 or -1 when a small body lies within the previous long one
+	CandlePiercing                                // 1 when a white candle closes above the middle of a black one
+	CandleDarkCloudCover                          // -1 when a black candle closes below the middle of a white one
+	CandleMorningStar                             // 1 on a long black, a small star below it and a white close into it
+	CandleEveningStar                             // -1 on a long white, a small star above it and a black close into it
+	CandleThreeWhiteSoldiers                      // 1 on three rising white candles
+	CandleThreeBlackCrows                         // -1 on three falling black candles
+)
+
+// String returns the pattern name, e.g. "engulfing".
+func (p CandlePattern) String() string {
+	switch p {
+	case CandleDoji:
+		return "doji"
+	case CandleDragonflyDoji:
+		return "dragonfly_doji"
+	case CandleGravestoneDoji:
+		return "gravestone_doji"
+	case CandleSpinningTop:
+		return "spinning_top"
+	case CandleMarubozu:
+		return "marubozu"
+	case CandleHammer:
+		return "hammer"
+	case CandleHangingMan:
+		return "hanging_man"
+	case CandleInvertedHammer:
+		return "inverted_hammer"
+	case CandleShootingStar:
+		return "shooting_star"
+	case CandleEngulfing:
+		return "engulfing"
+	case CandleHarami:
+		return "harami"
+	case CandlePiercing:
+		return "piercing"
+	case CandleDarkCloudCover:
+		return "dark_cloud_cover"
+	case CandleMorningStar:
+		return "morning_star"
+	case CandleEveningStar:
+		return "evening_star"
+	case CandleThreeWhiteSoldiers:
+		return "three_white_soldiers"
+	case CandleThreeBlackCrows:
+		return "three_black_crows"
+	}
+	return "unknown"
+}
+
+// ParseCandlePattern returns the CandlePattern named name, as returned by
+// String.
+func ParseCandlePattern(name string) (CandlePattern, bool) {
+	for p := CandleDoji; p <= CandleThreeBlackCrows; p++ {
+		if p.String() == name {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// CandleOptions sets the thresholds of the candlestick patterns. Body and
+// shadow sizes are measured in multiples of the Wilder ATR up to the previous
+// bar, so that they adapt to the volatility of the instrument. Zero fields
+// take the defaults.
+type CandleOptions struct {
+	ATRPeriod   int     // defaults to 14
+	DojiBody    float64 // largest body of a doji; defaults to 0.1
+	SmallBody   float64 // largest small body, and smallest long shadow; defaults to 0.3
+	LongBody    float64 // smallest long body; defaults to 0.7
+	ShortShadow float64 // largest short shadow; defaults to 0.1
+	// ShadowRatio is the smallest ratio of the long shadow to the body of
+	// a hammer or shooting star. Defaults to 2.
+	ShadowRatio float64
+	// Penetration is the smallest fraction of the first body that the third
+	// candle of a star closes into. Defaults to 0.3.
+	Penetration float64
+	// TrendBars is the number of bars over which the close must have fallen
+	// (or risen) before a hammer, hanging man, inverted hammer or shooting
+	// star. Defaults to 5.
+	TrendBars int
+}
+
+func (o CandleOptions) withDefaults() CandleOptions {
+	if o.ATRPeriod <= 0 {
+		o.ATRPeriod = 14
+	}
+	if o.DojiBody <= 0 {
+		o.DojiBody = 0.1
+	}
+	if o.SmallBody <= 0 {
+		o.SmallBody = 0.3
+	}
+	if o.LongBody <= 0 {
+		o.LongBody = 0.7
+	}
+	if o.ShortShadow <= 0 {
+		o.ShortShadow = 0.1
+	}
+	if o.ShadowRatio <= 0 {
+		o.ShadowRatio = 2
+	}
+	if o.Penetration <= 0 {
+		o.Penetration = 0.3
+	}
+	if o.TrendBars <= 0 {
+		o.TrendBars = 5
+	}
+	return o
+}
+
+// CandlestickPattern returns 1 at the bars completing a bullish occurrence of
+// pattern, -1 at those completing a bearish one and 0 elsewhere. Patterns
+// without a direction, such as a doji, are 1. Values during warm-up are NaN.
+func CandlestickPattern(open, high, low, close []float64, pattern CandlePattern, opt CandleOptions) []float64 {
+	n := len(close)
+	if len(open) != n || len(high) != n || len(low) != n {
+		return nil
+	}
+	out := make([]float64, n)
+	s := NewCandlestickPattern(pattern, opt)
+	for i := 0; i < n; i++ {
+		out[i], _ = s.Update(open[i], high[i], low[i], close[i])
+	}
+	return out
+}
+
+// CandlestickPatternChecked is like CandlestickPattern but reports invalid
+// input as an error.
+func CandlestickPatternChecked(open, high, low, close []float64, pattern CandlePattern, opt CandleOptions) ([]float64, error) {
+	if pattern < CandleDoji || pattern > CandleThreeBlackCrows {
+		return nil, &InputError{Func: "CandlestickPattern", Err: ErrInvalidParam, Detail: fmt.Sprintf("unknown pattern %d", int(pattern))}
+	}
+	if err := firstError(
+		checkLengths("CandlestickPattern", len(close), len(open), len(high), len(low)),
+		checkData("CandlestickPattern", len(close), CandlestickPatternLookback(opt)),
+	); err != nil {
+		return nil, err
+	}
+	return CandlestickPattern(open, high, low, close, pattern, opt), nil
+}
+
+// CandlestickPatternBars calculates CandlestickPattern over b, which must
+// have an Open column.
+func CandlestickPatternBars(b *Bars, pattern CandlePattern, opt CandleOptions) ([]float64, error) {
+	if err := b.requireOpen("CandlestickPattern"); err != nil {
+		return nil, err
+	}
+	return CandlestickPatternChecked(b.open, b.high, b.low, b.close, pattern, opt)
+}
+
+// CandlestickPatterns calculates every pattern over b as columns named
+// cdl_<pattern>, e.g. cdl_engulfing.
+func CandlestickPatterns(b *Bars, opt CandleOptions) ([]Column, error) {
+	var cols []Column
+	for p := CandleDoji; p <= CandleThreeBlackCrows; p++ {
+		values, err := CandlestickPatternBars(b, p, opt)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, Column{Name: "cdl_" + p.String(), Values: values})
+	}
+	return cols, nil
+}
+
+// CandlestickPatternLookback returns the number of leading warm-up values of
+// CandlestickPattern: the ATR up to the previous bar and the trend before
+// it.
+func CandlestickPatternLookback(opt CandleOptions) int {
+	opt = opt.withDefaults()
+	return max(ATRWilder.lookback(opt.ATRPeriod), opt.TrendBars) + 1
+}
+
+// candle is a single OHLC bar.
+type candle struct{ open, high, low, close float64 }
+
+func (c candle) body() float64   { return math.Abs(c.close - c.open) }
+func (c candle) top() float64    { return math.Max(c.open, c.close) }
+func (c candle) bottom() float64 { return math.Min(c.open, c.close) }
+func (c candle) upper() float64  { return c.high - c.top() }
+func (c candle) lower() float64  { return c.bottom() - c.low }
+func (c candle) white() bool     { return c.close > c.open }
+func (c candle) black() bool     { return c.close < c.open }
+func (c candle) mid() float64    { return (c.open + c.close) / 2 }
+
+// color returns 1 for a white candle and -1 otherwise.
+func (c candle) color() float64 {
+	if c.white() {
+		return 1
+	}
+	return -1
+}
+
+// CandlestickStream is the streaming counterpart of CandlestickPattern.
+type CandlestickStream struct {
+	pattern CandlePattern
+	opt     CandleOptions
+	atr     atrKernel
+	prevATR float64
+	candles [3]candle // the current candle last
+	closes  *ring     // closes of the bars before the current one
+	seen    int
+}
+
+// NewCandlestickPattern returns a streaming detector of pattern.
+func NewCandlestickPattern(pattern CandlePattern, opt CandleOptions) *CandlestickStream {
+	opt = opt.withDefaults()
+	return &CandlestickStream{
+		pattern: pattern,
+		opt:     opt,
+		atr:     newATRKernel(ATRWilder, opt.ATRPeriod),
+		closes:  newRing(opt.TrendBars + 1),
+	}
+}
+
+// Update adds a bar and returns the pattern signal completed at it, or NaN
+// during warm-up.
+func (s *CandlestickStream) Update(open, high, low, close float64) (float64, bool) {
+	if s.seen > 0 {
+		s.closes.push(s.candles[2].close)
+	}
+	s.candles[0], s.candles[1] = s.candles[1], s.candles[2]
+	s.candles[2] = candle{open, high, low, close}
+	atr := s.prevATR
+	s.prevATR, _ = s.atr.update(high, low, close)
+	s.seen++
+	if !s.Ready() {
+		return math.NaN(), false
+	}
+	return s.detect(atr), true
+}
+
+// detect evaluates the pattern at the current candle with sizes measured in
+// atr.
+func (s *CandlestickStream) detect(atr float64) float64 {
+	o := s.opt
+	pp, p, c := s.candles[0], s.candles[1], s.candles[2]
+	// The trend is that of the closes before the current candle.
+	trend := s.closes.at(s.closes.count-1) - s.closes.at(0)
+	doji := c.body() <= o.DojiBody*atr
+	hammer := c.upper() <= o.ShortShadow*atr && c.lower() >= o.SmallBody*atr && c.lower() >= o.ShadowRatio*c.body()
+	inverted := c.lower() <= o.ShortShadow*atr && c.upper() >= o.SmallBody*atr && c.upper() >= o.ShadowRatio*c.body()
+
+	switch s.pattern {
+	case CandleDoji:
+		if doji {
+			return 1
+		}
+	case CandleDragonflyDoji:
+		if doji && c.upper() <= o.ShortShadow*atr && c.lower() >= o.SmallBody*atr {
+			return 1
+		}
+	case CandleGravestoneDoji:
+		if doji && c.lower() <= o.ShortShadow*atr && c.upper() >= o.SmallBody*atr {
+			return -1
+		}
+	case CandleSpinningTop:
+		if !doji && c.body() <= o.SmallBody*atr && c.upper() > c.body() && c.lower() > c.body() {
+			return c.color()
+		}
+	case CandleMarubozu:
+		if c.body() >= o.LongBody*atr && c.upper() <= o.ShortShadow*atr && c.lower() <= o.ShortShadow*atr {
+			return c.color()
+		}
+	case CandleHammer:
+		if hammer && trend < 0 {
+			return 1
+		}
+	case CandleHangingMan:
+		if hammer && trend > 0 {
+			return -1
+		}
+	case CandleInvertedHammer:
+		if inverted && trend < 0 {
+			return 1
+		}
+	case CandleShootingStar:
+		if inverted && trend > 0 {
+			return -1
+		}
+	case CandleEngulfing:
+		if p.body() > o.DojiBody*atr && c.body() > p.body() && c.top() >= p.top() && c.bottom() <= p.bottom() {
+			switch {
+			case p.black() && c.white():
+				return 1
+			case p.white() && c.black():
+				return -1
+			}
+		}
+	case CandleHarami:
+		if p.body() >= o.LongBody*atr && c.body() <= o.SmallBody*atr && c.top() <= p.top() && c.bottom() >= p.bottom() {
+			return -p.color()
+		}
+	case CandlePiercing:
+		if p.black() && p.body() >= o.LongBody*atr && c.white() &&
+			c.open < p.low && c.close > p.mid() && c.close < p.open {
+			return 1
+		}
+	case CandleDarkCloudCover:
+		if p.white() && p.body() >= o.LongBody*atr && c.black() &&
+			c.open > p.high && c.close < p.mid() && c.close > p.open {
+			return -1
+		}
+	case CandleMorningStar:
+		if pp.black() && pp.body() >= o.LongBody*atr && p.body() <= o.SmallBody*atr && p.top() < pp.close &&
+			c.white() && c.close > pp.close+o.Penetration*pp.body() {
+			return 1
+		}
+	case CandleEveningStar:
+		if pp.white() && pp.body() >= o.LongBody*atr && p.body() <= o.SmallBody*atr && p.bottom() > pp.close &&
+			c.black() && c.close < pp.close-o.Penetration*pp.body() {
+			return -1
+		}
+	case CandleThreeWhiteSoldiers:
+		if s.soldiers(atr, 1) {
+			return 1
+		}
+	case CandleThreeBlackCrows:
+		if s.soldiers(atr, -1) {
+			return -1
+		}
+	}
+	return 0
+}
+
+// soldiers reports whether the last three candles are of color sign, not
+// small, each closing beyond the previous one from an open within its body,
+// with short shadows beyond the close.
+func (s *CandlestickStream) soldiers(atr, sign float64) bool {
+	o := s.opt
+	for k, c := range s.candles {
+		if c.color() != sign || c.close == c.open || c.body() <= o.SmallBody*atr {
+			return false
+		}
+		shadow := c.upper()
+		if sign < 0 {
+			shadow = c.lower()
+		}
+		if shadow > o.ShortShadow*atr {
+			return false
+		}
+		if k > 0 {
+			p := s.candles[k-1]
+			if sign*(c.close-p.close) <= 0 || c.open < p.bottom() || c.open > p.top() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Ready reports whether the ATR and trend are defined.
+func (s *CandlestickStream) Ready() bool { return s.seen > s.Lookback() }
+
+// Reset clears the candles and the ATR.
+func (s *CandlestickStream) Reset() {
+	s.atr.reset()
+	s.closes.reset()
+	s.prevATR = 0
+	s.seen = 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *CandlestickStream) Lookback() int { return CandlestickPatternLookback(s.opt) }
@@ -0,0 +1,139 @@
+package indicators
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// candleSeries returns 30 bars with a range of 2, so that the ATR is 2,
+// whose closes move by trend each bar, followed by the given candles as
+// open, high, low and close offsets from the last of those closes.
+func candleSeries(trend float64, candles ...[4]float64) (open, high, low, close []float64) {
+	c := 100.0
+	for i := 0; i < 30; i++ {
+		c += trend
+		open, high, low, close = append(open, c-trend), append(high, c+1), append(low, c-1), append(close, c)
+	}
+	for _, k := range candles {
+		open, high, low, close = append(open, c+k[0]), append(high, c+k[1]), append(low, c+k[2]), append(close, c+k[3])
+	}
+	return open, high, low, close
+}
+
+// mirrorCandles turns candles upside down, making bullish patterns bearish.
+func mirrorCandles(candles [][4]float64) [][4]float64 {
+	out := make([][4]float64, len(candles))
+	for i, k := range candles {
+		out[i] = [4]float64{-k[0], -k[2], -k[1], -k[3]}
+	}
+	return out
+}
+
+func TestCandlestickPatterns(t *testing.T) {
+	var (
+		hammer         = [4]float64{0, 0.4, -1.5, 0.3}
+		blackLong      = [4]float64{0, 0.1, -1.6, -1.5}
+		engulfing      = [][4]float64{{0, 0.6, -0.6, -0.5}, {-0.6, 0.8, -0.7, 0.7}}
+		piercing       = [][4]float64{blackLong, {-1.8, -0.1, -1.9, -0.5}}
+		morningStar    = [][4]float64{blackLong, {-2, -1.8, -2.3, -1.9}, {-1.8, -0.3, -1.9, -0.4}}
+		whiteSoldiers  = [][4]float64{{0, 1.05, -0.1, 1}, {0.5, 2.05, 0.4, 2}, {1.5, 3.05, 1.4, 3}}
+		harami         = [][4]float64{{0, 1.6, -0.1, 1.5}, {1, 1.2, 0.7, 0.8}}
+		fallingSoldier = [][4]float64{{0, 1.05, -0.1, 1}, {0.5, 2.05, 0.4, 2}, {1.5, 2.05, 0.9, 1}}
+	)
+	for _, c := range []struct {
+		name    string
+		pattern CandlePattern
+		trend   float64
+		candles [][4]float64
+		want    float64
+	}{
+		{"doji", CandleDoji, 0, [][4]float64{{0, 1, -1, 0.05}}, 1},
+		{"no doji", CandleDoji, 0, [][4]float64{{0, 1, -1, 0.5}}, 0},
+		{"dragonfly", CandleDragonflyDoji, 0, [][4]float64{{0, 0.1, -2, 0.05}}, 1},
+		{"gravestone", CandleGravestoneDoji, 0, [][4]float64{{0, 2, -0.1, 0.05}}, -1},
+		{"spinning top", CandleSpinningTop, 0, [][4]float64{{0, 1, -1, 0.4}}, 1},
+		{"black marubozu", CandleMarubozu, 0, [][4]float64{blackLong}, -1},
+		{"hammer", CandleHammer, -0.5, [][4]float64{hammer}, 1},
+		{"hammer after a rise", CandleHammer, 0.5, [][4]float64{hammer}, 0},
+		{"hanging man", CandleHangingMan, 0.5, [][4]float64{hammer}, -1},
+		{"inverted hammer", CandleInvertedHammer, -0.5, mirrorCandles([][4]float64{hammer}), 1},
+		{"shooting star", CandleShootingStar, 0.5, mirrorCandles([][4]float64{hammer}), -1},
+		{"bullish engulfing", CandleEngulfing, 0, engulfing, 1},
+		{"bearish engulfing", CandleEngulfing, 0, mirrorCandles(engulfing), -1},
+		{"bearish harami", CandleHarami, 0, harami, -1},
+		{"bullish harami", CandleHarami, 0, mirrorCandles(harami), 1},
+		{"piercing", CandlePiercing, 0, piercing, 1},
+		{"dark cloud cover", CandleDarkCloudCover, 0, mirrorCandles(piercing), -1},
+		{"morning star", CandleMorningStar, 0, morningStar, 1},
+		{"evening star", CandleEveningStar, 0, mirrorCandles(morningStar), -1},
+		{"three white soldiers", CandleThreeWhiteSoldiers, 0, whiteSoldiers, 1},
+		{"three black crows", CandleThreeBlackCrows, 0, mirrorCandles(whiteSoldiers), -1},
+		{"a black third soldier", CandleThreeWhiteSoldiers, 0, fallingSoldier, 0},
+	} {
+		open, high, low, close := candleSeries(c.trend, c.candles...)
+		got := CandlestickPattern(open, high, low, close, c.pattern, CandleOptions{})
+		if last := got[len(got)-1]; last != c.want {
+			t.Errorf("%s: %v, want %v", c.name, last, c.want)
+		}
+	}
+}
+
+func TestCandlestickPatternWarmup(t *testing.T) {
+	open, high, low, close, _ := testOHLCV(100)
+	opt := CandleOptions{ATRPeriod: 10, TrendBars: 3}
+	lookback := CandlestickPatternLookback(opt)
+	for p := CandleDoji; p <= CandleThreeBlackCrows; p++ {
+		got := CandlestickPattern(open, high, low, close, p, opt)
+		for i, v := range got {
+			if math.IsNaN(v) != (i < lookback) {
+				t.Fatalf("%v: [%d] = %v with lookback %d", p, i, v, lookback)
+			}
+		}
+		s := NewCandlestickPattern(p, opt)
+		for pass := 0; pass < 2; pass++ {
+			for i := range close {
+				if v, _ := s.Update(open[i], high[i], low[i], close[i]); v != got[i] && !math.IsNaN(got[i]) {
+					t.Fatalf("%v pass %d: stream [%d] = %v, batch %v", p, pass, i, v, got[i])
+				}
+			}
+			s.Reset()
+		}
+	}
+}
+
+func TestCandlestickPatternNames(t *testing.T) {
+	b := testBars(50)
+	cols, err := CandlestickPatterns(b, CandleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cols) != int(CandleThreeBlackCrows)+1 {
+		t.Fatalf("%d columns", len(cols))
+	}
+	for p := CandleDoji; p <= CandleThreeBlackCrows; p++ {
+		if got, ok := ParseCandlePattern(p.String()); !ok || got != p {
+			t.Errorf("ParseCandlePattern(%q) = %v, %v", p.String(), got, ok)
+		}
+		if cols[p].Name != "cdl_"+p.String() {
+			t.Errorf("column %d is %s", p, cols[p].Name)
+		}
+	}
+	if _, ok := ParseCandlePattern("unknown"); ok {
+		t.Error("ParseCandlePattern accepted unknown")
+	}
+}
+
+func TestCandlestickPatternErrors(t *testing.T) {
+	b := testBars(50)
+	if _, err := CandlestickPatternBars(b, CandleThreeBlackCrows+1, CandleOptions{}); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("unknown pattern: %v", err)
+	}
+	if _, err := CandlestickPatternBars(b, CandleDoji, CandleOptions{ATRPeriod: 60}); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("50 bars for a 60-bar ATR: %v", err)
+	}
+	b.open = nil
+	if _, err := CandlestickPatternBars(b, CandleDoji, CandleOptions{}); !errors.Is(err, ErrMissingColumn) {
+		t.Errorf("no Open: %v", err)
+	}
+}
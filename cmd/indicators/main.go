@@ -0,0 +1,293 @@
+// Command indicators computes indicators over OHLCV bars read from CSV and
+// writes the bars together with the indicator columns as CSV to stdout.
+//
+// Usage:
+//
+//	indicators [flags] [file.csv]
+//
+// The bars are read from file.csv, or from stdin if it is omitted or "-".
+// Indicators are given as name:param=value,... and may be repeated:
+//
+//	indicators --ind supertrend:length=10,multiplier=3 --ind cmf:period=20 bars.csv
+//
+// Expressions over the bars can be added as name=expression:
+//
+//	indicators --expr 'trend=ema(close,9) - sma(close,21)' bars.csv
+//
+// Run with --list to print the available indicators and their parameters.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/blazer-org/indicators"
+	"github.com/blazer-org/indicators/expr"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "indicators:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// multiFlag collects the values of a repeatable flag.
+type multiFlag []string
+
+func (f *multiFlag) String() string { return strings.Join(*f, " ") }
+
+func (f *multiFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("indicators", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		inds, exprs, cols multiFlag
+		list              = fs.Bool("list", false, "list the available indicators and exit")
+		delim             = fs.String("delim", ",", "input field delimiter")
+		outDelim          = fs.String("out-delim", "", "output field delimiter (default: the input delimiter)")
+		timeFormat        = fs.String("time-format", "", `input time layout, "unix" or "unixms" (default: detect)`)
+		outTimeFormat     = fs.String("out-time-format", "", "output time layout (default: RFC 3339)")
+		nan               = fs.String("nan", "", "output text for NaN values")
+		precision         = fs.Int("precision", -1, "digits after the decimal point, or -1 for the shortest exact value")
+		warmup            = fs.String("warmup", "nan", "warm-up values: nan or zero")
+	)
+	fs.Var(&inds, "ind", "indicator as name:param=value,... (repeatable)")
+	fs.Var(&exprs, "expr", "expression column as name=expression (repeatable)")
+	fs.Var(&cols, "col", "input column mapping as column=header, e.g. close=Adj Close (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *list {
+		return listIndicators(stdout)
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("at most one input file, got %d", fs.NArg())
+	}
+	var policy indicators.Warmup
+	switch *warmup {
+	case "nan":
+		policy = indicators.WarmupNaN
+	case "zero":
+		policy = indicators.WarmupZero
+	default:
+		return fmt.Errorf("invalid --warmup %q", *warmup)
+	}
+
+	specs := make([]spec, len(inds))
+	seen := map[string]string{}
+	for i, s := range inds {
+		sp, err := parseSpec(s)
+		if err != nil {
+			return err
+		}
+		// The same indicator with the same parameters would repeat its
+		// column names.
+		if prev, dup := seen[sp.key]; dup {
+			return fmt.Errorf("--ind %q repeats --ind %q", s, prev)
+		}
+		seen[sp.key] = s
+		specs[i] = sp
+	}
+	programs := make([]namedProgram, len(exprs))
+	for i, e := range exprs {
+		name, src, ok := strings.Cut(e, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("--expr %q: want name=expression", e)
+		}
+		p, err := expr.Compile(src)
+		if err != nil {
+			return fmt.Errorf("--expr %s: %w", name, err)
+		}
+		programs[i] = namedProgram{strings.TrimSpace(name), p}
+	}
+
+	in := stdin
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	opt := indicators.CSVOptions{TimeFormat: *timeFormat}
+	var err error
+	if opt.Comma, err = delimiter(*delim); err != nil {
+		return err
+	}
+	if len(cols) > 0 {
+		opt.Columns = map[string]string{}
+		for _, c := range cols {
+			col, header, ok := strings.Cut(c, "=")
+			if !ok {
+				return fmt.Errorf("--col %q: want column=header", c)
+			}
+			opt.Columns[strings.ToLower(strings.TrimSpace(col))] = strings.TrimSpace(header)
+		}
+	}
+	bars, err := indicators.ReadCSV(bufio.NewReader(in), opt)
+	if err != nil {
+		return err
+	}
+
+	out, err := compute(bars, specs, policy)
+	if err != nil {
+		return err
+	}
+	for _, p := range programs {
+		values, err := p.Eval(bars)
+		if err != nil {
+			return fmt.Errorf("--expr %s: %w", p.name, err)
+		}
+		if policy == indicators.WarmupZero {
+			indicators.ApplyWarmup(values, p.Lookback(), policy)
+		}
+		out = append(out, indicators.Column{Name: p.name, Values: values})
+	}
+
+	wopt := indicators.CSVWriteOptions{
+		Comma:      opt.Comma,
+		TimeFormat: *outTimeFormat,
+		NaN:        *nan,
+	}
+	if *precision >= 0 {
+		wopt.Precision = precision
+	}
+	if *outDelim != "" {
+		if wopt.Comma, err = delimiter(*outDelim); err != nil {
+			return err
+		}
+	}
+	w := bufio.NewWriter(stdout)
+	if err := indicators.WriteCSV(w, bars, out, wopt); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+type namedProgram struct {
+	name string
+	*expr.Program
+}
+
+// delimiter parses a delimiter flag, accepting `\t` for tab.
+func delimiter(s string) (rune, error) {
+	if s == `\t` || s == "tab" {
+		return '\t', nil
+	}
+	r := []rune(s)
+	if len(r) != 1 {
+		return 0, fmt.Errorf("delimiter %q must be a single character", s)
+	}
+	return r[0], nil
+}
+
+// spec is an indicator requested on the command line.
+type spec struct {
+	text   string
+	desc   *indicators.Descriptor
+	params indicators.Params
+	key    string // name and resolved parameters, the same for equal specs
+}
+
+// parseSpec parses name:param=value,...
+func parseSpec(s string) (spec, error) {
+	name, rest, _ := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+	d, ok := indicators.Lookup(name)
+	if !ok {
+		return spec{}, fmt.Errorf("--ind %q: unknown indicator %q (see --list)", s, name)
+	}
+	sp := spec{text: s, desc: d, params: indicators.Params{}}
+	if strings.TrimSpace(rest) != "" {
+		for _, kv := range strings.Split(rest, ",") {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return spec{}, fmt.Errorf("--ind %q: parameter %q: want param=value", s, kv)
+			}
+			k = strings.TrimSpace(k)
+			sp.params[k] = strings.TrimSpace(v)
+		}
+	}
+	// Resolve now so that bad parameters are reported before reading input.
+	resolved, err := d.Resolve(sp.params)
+	if err != nil {
+		return spec{}, fmt.Errorf("--ind %q: %w", s, err)
+	}
+	sp.key = d.Name
+	for _, p := range d.Params {
+		sp.key += fmt.Sprintf(",%s=%v", p.Name, resolved[p.Name])
+	}
+	return sp, nil
+}
+
+// compute invokes every spec over bars. When an indicator is requested more
+// than once, its columns are suffixed with its parameter values so that the
+// names stay unique, e.g. sma_10 and sma_30. Outputs that share a name across
+// indicators, such as vwap, are prefixed with the indicator name.
+func compute(bars *indicators.Bars, specs []spec, policy indicators.Warmup) ([]indicators.Column, error) {
+	count := map[string]int{}
+	outputs := map[string]map[string]bool{}
+	for _, sp := range specs {
+		count[sp.desc.Name]++
+		for _, o := range sp.desc.Outputs {
+			if outputs[o] == nil {
+				outputs[o] = map[string]bool{}
+			}
+			outputs[o][sp.desc.Name] = true
+		}
+	}
+	var out []indicators.Column
+	for _, sp := range specs {
+		cols, err := sp.desc.Invoke(bars, sp.params)
+		if err != nil {
+			return nil, fmt.Errorf("--ind %q: %w", sp.text, err)
+		}
+		resolved, _ := sp.desc.Resolve(sp.params)
+		lookback := sp.desc.Lookback(resolved)
+		for _, c := range cols {
+			if len(outputs[c.Name]) > 1 {
+				c.Name = sp.desc.Name + "_" + c.Name
+			}
+			if count[sp.desc.Name] > 1 {
+				for _, p := range sp.desc.Params {
+					c.Name += "_" + fmt.Sprint(resolved[p.Name])
+				}
+			}
+			indicators.ApplyWarmup(c.Values, lookback, policy)
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func listIndicators(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, name := range indicators.Names() {
+		d, _ := indicators.Lookup(name)
+		fmt.Fprintf(bw, "%s: %s\n", d.Name, d.Doc)
+		fmt.Fprintf(bw, "  inputs:  %s\n", strings.Join(d.Inputs, ", "))
+		fmt.Fprintf(bw, "  outputs: %s\n", strings.Join(d.Outputs, ", "))
+		for _, p := range d.Params {
+			fmt.Fprintf(bw, "  %s %s = %v: %s", p.Name, p.Kind, p.Default, p.Doc)
+			if len(p.Choices) > 0 {
+				fmt.Fprintf(bw, " (%s)", strings.Join(p.Choices, ", "))
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+	return bw.Flush()
+}
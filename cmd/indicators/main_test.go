@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCSV() string {
+	var b strings.Builder
+	b.WriteString("date,open,high,low,close,volume\n")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 40; i++ {
+		c := 100 + float64(i%10)
+		day := start.AddDate(0, 0, i).Format("2006-01-02")
+		fmt.Fprintf(&b, "%s,%v,%v,%v,%v,%d\n", day, c, c+1, c-1, c, 1000+i)
+	}
+	return b.String()
+}
+
+// runCSV runs the command over testCSV and parses its output.
+func runCSV(t *testing.T, args ...string) [][]string {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	if err := run(args, strings.NewReader(testCSV()), &out, &errOut); err != nil {
+		t.Fatalf("run %v: %v", args, err)
+	}
+	recs, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return recs
+}
+
+func TestRunColumns(t *testing.T) {
+	recs := runCSV(t, "-ind", "sma:period=3", "-ind", "sma:period=5", "-ind", "anchored_vwap", "-ind", "session_vwap", "-expr", "d=close-lag(close,1)")
+	header := strings.Join(recs[0], ",")
+	want := "time,open,high,low,close,volume,sma_3,sma_5,anchored_vwap_vwap"
+	if !strings.HasPrefix(header, want) || !strings.Contains(header, ",session_vwap_vwap,") || !strings.HasSuffix(header, ",d") {
+		t.Errorf("header %s", header)
+	}
+	if len(recs) != 41 {
+		t.Errorf("%d records, want 41", len(recs))
+	}
+	// sma_3 is NaN, written as an empty field, during its warm-up.
+	if recs[2][6] != "" || recs[3][6] != "101" {
+		t.Errorf("sma_3 = %q, %q; want empty, 101", recs[2][6], recs[3][6])
+	}
+}
+
+func TestRunWarmupAndPrecision(t *testing.T) {
+	recs := runCSV(t, "-warmup", "zero", "-precision", "0", "-ind", "sma:period=4")
+	if recs[1][6] != "0" || recs[4][6] != "102" {
+		t.Errorf("sma = %q, %q; want 0, 102", recs[1][6], recs[4][6])
+	}
+	if recs[1][4] != "100" {
+		t.Errorf("close = %q, want 100", recs[1][4])
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-ind", "nope"},
+		{"-ind", "sma:period=x"},
+		{"-ind", "sma:period"},
+		{"-expr", "noname"},
+		{"-expr", "x=sma(close)"},
+		{"-warmup", "trim"},
+		{"-delim", "ab"},
+		{"-ind", "sma:period=10", "-ind", "sma: period = 10"},
+		{"a.csv", "b.csv"},
+	} {
+		var out, errOut bytes.Buffer
+		if err := run(args, strings.NewReader(testCSV()), &out, &errOut); err == nil {
+			t.Errorf("run %v succeeded", args)
+		}
+	}
+}
+
+func TestRunColumnMapping(t *testing.T) {
+	recs := runCSV(t, "-col", "close= Close ", "-ind", "sma:period=3")
+	if recs[3][6] != "101" {
+		t.Errorf("sma = %q, want 101", recs[3][6])
+	}
+}
+
+func TestRunList(t *testing.T) {
+	var out, errOut bytes.Buffer
+	if err := run([]string{"-list"}, strings.NewReader(""), &out, &errOut); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "supertrend: ") || !strings.Contains(out.String(), "  source string = hlc3") {
+		t.Errorf("list output:\n%s", out.String())
+	}
+}
@@ -1,14 +1,14 @@
 package indicators
 
-// CMF calculates the Chaikin Money Flow indicator
+import "math"
+
+// CMF calculates the Chaikin Money Flow indicator. Values before the first
+// full window are NaN.
 func CMF(highs, lows, closes, volumes []float64, period int) []float64 {
 	n := len(highs)
 	if n != len(lows) || n != len(closes) || n != len(volumes) {
 		return []float64{}
 	}
-	if n < period {
-		return []float64{}
-	}
 
 	mfm := make([]float64, n) // Money Flow Multiplier
 	mfv := make([]float64, n) // Money Flow Volume
@@ -24,18 +24,90 @@ func CMF(highs, lows, closes, volumes []float64, period int) []float64 {
 	}
 
 	cmf := make([]float64, n)
-	for i := period - 1; i < n; i++ {
-		var sumMFV, sumVolume float64
-		for j := i - period + 1; j <= i; j++ {
-			sumMFV += mfv[j]
-			sumVolume += volumes[j]
+	sumMFV, sumVolume := newRollingSum(period), newRollingSum(period)
+	for i := 0; i < n; i++ {
+		sumMFV.push(mfv[i])
+		sumVolume.push(volumes[i])
+		if i < period-1 {
+			cmf[i] = math.NaN()
+			continue
 		}
-		if sumVolume == 0 {
+		if sumVolume.value() == 0 {
 			cmf[i] = 0
 		} else {
-			cmf[i] = sumMFV / sumVolume
+			cmf[i] = sumMFV.value() / sumVolume.value()
 		}
 	}
 
 	return cmf
 }
+
+// CMFChecked is like CMF but reports invalid input as an error.
+func CMFChecked(highs, lows, closes, volumes []float64, period int) ([]float64, error) {
+	if err := firstError(
+		checkLengths("CMF", len(highs), len(lows), len(closes), len(volumes)),
+		checkPeriod("CMF", "period", period, 1),
+		checkData("CMF", len(highs), CMFLookback(period)),
+	); err != nil {
+		return nil, err
+	}
+	return CMF(highs, lows, closes, volumes, period), nil
+}
+
+// CMFBars calculates CMF over b.
+func CMFBars(b *Bars, period int) ([]float64, error) {
+	return CMFChecked(b.high, b.low, b.close, b.volume, period)
+}
+
+// CMFLookback returns the number of leading warm-up values of CMF.
+func CMFLookback(period int) int {
+	return period - 1
+}
+
+// CMFStream is the streaming counterpart of CMF.
+type CMFStream struct {
+	period int
+	mfv    *rollingSum
+	vol    *rollingSum
+}
+
+// NewCMF returns a streaming Chaikin Money Flow over period bars.
+func NewCMF(period int) *CMFStream {
+	if period <= 0 {
+		panic("period must be greater than 0")
+	}
+	return &CMFStream{period: period, mfv: newRollingSum(period), vol: newRollingSum(period)}
+}
+
+// Update adds a bar and returns the current CMF, or NaN during warm-up.
+func (s *CMFStream) Update(high, low, close, volume float64) (float64, bool) {
+	var mfm float64 // Money Flow Multiplier
+	highLowRange := high - low
+	if highLowRange == 0 {
+		mfm = 0 // Avoid division by zero
+	} else {
+		mfm = ((2*close - high - low) / highLowRange)
+	}
+	s.mfv.push(mfm * volume)
+	s.vol.push(volume)
+
+	if !s.mfv.full() {
+		return math.NaN(), false
+	}
+	if s.vol.value() == 0 {
+		return 0, true
+	}
+	return s.mfv.value() / s.vol.value(), true
+}
+
+// Ready reports whether a full window has been seen.
+func (s *CMFStream) Ready() bool { return s.mfv.full() }
+
+// Reset clears the window.
+func (s *CMFStream) Reset() {
+	s.mfv.reset()
+	s.vol.reset()
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *CMFStream) Lookback() int { return s.period - 1 }
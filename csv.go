@@ -0,0 +1,288 @@
+package indicators
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVOptions configures ReadCSV.
+type CSVOptions struct {
+	// Comma is the field delimiter. Defaults to ','.
+	Comma rune
+	// Columns maps the Bars columns "time", "open", "high", "low", "close"
+	// and "volume" to CSV header names, matched case-insensitively. Unmapped
+	// columns are looked up under their usual names, e.g. "date" or
+	// "timestamp" for time and "o" or "Open" for open.
+	Columns map[string]string
+	// Fields names the CSV columns for files without a header row. If set,
+	// the first row is read as data.
+	Fields []string
+	// TimeFormat is the time.Parse layout of the time column, or "unix" or
+	// "unixms" for epoch seconds or milliseconds. If empty, RFC 3339, ISO
+	// dates with or without a time of day and epoch values are accepted.
+	TimeFormat string
+	// Location is the time zone of times without an offset. Defaults to UTC.
+	Location *time.Location
+}
+
+// defaultCSVNames lists the header names tried for each Bars column.
+var defaultCSVNames = map[string][]string{
+	"time":   {"time", "date", "datetime", "timestamp", "t"},
+	"open":   {"open", "o"},
+	"high":   {"high", "h"},
+	"low":    {"low", "l"},
+	"close":  {"close", "c", "adj close", "adj_close"},
+	"volume": {"volume", "v", "vol"},
+}
+
+// autoTimeLayouts are the layouts tried when CSVOptions.TimeFormat is empty.
+var autoTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+// ReadCSV reads OHLCV bars from r. High, low, close and volume columns are
+// required; time and open are loaded if present. Empty fields and "NaN" are
+// read as NaN.
+func ReadCSV(r io.Reader, opt CSVOptions) (*Bars, error) {
+	cr := csv.NewReader(r)
+	if opt.Comma != 0 {
+		cr.Comma = opt.Comma
+	}
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+
+	header := opt.Fields
+	if header == nil {
+		rec, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("indicators: ReadCSV: header: %w", err)
+		}
+		header = append([]string(nil), rec...)
+	}
+	idx, err := mapCSVColumns(header, opt.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every record must reach the last mapped column. Without a header
+	// row, csv.Reader only checks that records match the first one.
+	fields := 0
+	for _, i := range idx {
+		fields = max(fields, i+1)
+	}
+
+	loc := opt.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	var b BarColumns
+	cols := map[string]*[]float64{
+		"open": &b.Open, "high": &b.High, "low": &b.Low, "close": &b.Close, "volume": &b.Volume,
+	}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("indicators: ReadCSV: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		if len(rec) < fields {
+			return nil, &InputError{Func: "ReadCSV", Err: ErrMissingColumn, Detail: fmt.Sprintf("line %d has %d fields, want %d", line, len(rec), fields)}
+		}
+		for name, dst := range cols {
+			i, ok := idx[name]
+			if !ok {
+				continue
+			}
+			v, err := parseCSVFloat(rec[i])
+			if err != nil {
+				return nil, fmt.Errorf("indicators: ReadCSV: line %d: %s: %w", line, name, err)
+			}
+			*dst = append(*dst, v)
+		}
+		if i, ok := idx["time"]; ok {
+			t, err := parseCSVTime(rec[i], opt.TimeFormat, loc)
+			if err != nil {
+				return nil, fmt.Errorf("indicators: ReadCSV: line %d: time: %w", line, err)
+			}
+			b.Time = append(b.Time, t)
+		}
+	}
+
+	// Keep optional columns non-nil when present, even for an empty file.
+	if _, ok := idx["open"]; ok && b.Open == nil {
+		b.Open = []float64{}
+	}
+	if _, ok := idx["time"]; ok && b.Time == nil {
+		b.Time = []time.Time{}
+	}
+	return NewBars(b)
+}
+
+// mapCSVColumns returns the index of each Bars column found in header.
+func mapCSVColumns(header []string, names map[string]string) (map[string]int, error) {
+	pos := make(map[string]int, len(header))
+	for i, h := range header {
+		pos[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	idx := make(map[string]int, 6)
+	for col, candidates := range defaultCSVNames {
+		if name, ok := names[col]; ok {
+			i, found := pos[strings.ToLower(name)]
+			if !found {
+				return nil, &InputError{Func: "ReadCSV", Err: ErrMissingColumn, Detail: fmt.Sprintf("no header %q for %s", name, col)}
+			}
+			idx[col] = i
+			continue
+		}
+		for _, name := range candidates {
+			if i, found := pos[name]; found {
+				idx[col] = i
+				break
+			}
+		}
+	}
+	for col := range names {
+		if _, ok := defaultCSVNames[col]; !ok {
+			return nil, fmt.Errorf("indicators: ReadCSV: unknown column %q in mapping", col)
+		}
+	}
+	for _, col := range []string{"high", "low", "close", "volume"} {
+		if _, ok := idx[col]; !ok {
+			return nil, &InputError{Func: "ReadCSV", Err: ErrMissingColumn, Detail: col}
+		}
+	}
+	return idx, nil
+}
+
+func parseCSVFloat(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func parseCSVTime(s, layout string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	switch layout {
+	case "unix", "unixms":
+		return parseEpoch(s, layout == "unixms")
+	case "":
+	default:
+		return time.ParseInLocation(layout, s, loc)
+	}
+	for _, l := range autoTimeLayouts {
+		if t, err := time.ParseInLocation(l, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	// Epoch values of 12 or more digits are taken as milliseconds.
+	if t, err := parseEpoch(s, len(strings.TrimLeft(s, "-")) >= 12); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
+
+func parseEpoch(s string, millis bool) (time.Time, error) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if millis {
+		return time.UnixMilli(v).UTC(), nil
+	}
+	return time.Unix(v, 0).UTC(), nil
+}
+
+// CSVWriteOptions configures WriteCSV.
+type CSVWriteOptions struct {
+	// Comma is the field delimiter. Defaults to ','.
+	Comma rune
+	// TimeFormat is the time.Format layout of the time column. Defaults to
+	// RFC 3339.
+	TimeFormat string
+	// NaN is written for NaN values. Defaults to an empty field.
+	NaN string
+	// Precision is the number of digits after the decimal point. If nil,
+	// values are written with the fewest digits that read back exactly.
+	Precision *int
+}
+
+// WriteCSV writes the bars followed by cols to w, with a header row. The
+// time and open columns are written if present. Use the Columns methods of
+// the multi-output results, e.g. IchimokuResult.Columns, or Invoke to build
+// cols.
+func WriteCSV(w io.Writer, b *Bars, cols []Column, opt CSVWriteOptions) error {
+	n := b.Len()
+	for _, c := range cols {
+		if len(c.Values) != n {
+			return &InputError{Func: "WriteCSV", Err: ErrLengthMismatch, Detail: fmt.Sprintf("%s has %d values, bars have %d", c.Name, len(c.Values), n)}
+		}
+	}
+	layout := opt.TimeFormat
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	prec := -1
+	if opt.Precision != nil {
+		prec = *opt.Precision
+	}
+
+	cw := csv.NewWriter(w)
+	if opt.Comma != 0 {
+		cw.Comma = opt.Comma
+	}
+
+	series := make([][]float64, 0, 5+len(cols))
+	header := make([]string, 0, 6+len(cols))
+	if b.HasTime() {
+		header = append(header, "time")
+	}
+	if b.HasOpen() {
+		header = append(header, "open")
+		series = append(series, b.open)
+	}
+	header = append(header, "high", "low", "close", "volume")
+	series = append(series, b.high, b.low, b.close, b.volume)
+	for _, c := range cols {
+		header = append(header, c.Name)
+		series = append(series, c.Values)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	rec := make([]string, len(header))
+	for i := 0; i < n; i++ {
+		rec = rec[:0]
+		if b.HasTime() {
+			rec = append(rec, b.time[i].Format(layout))
+		}
+		for _, s := range series {
+			if math.IsNaN(s[i]) {
+				rec = append(rec, opt.NaN)
+			} else {
+				rec = append(rec, strconv.FormatFloat(s[i], 'f', prec, 64))
+			}
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,97 @@
+package indicators
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	b := testBars(30)
+	b.close[3] = math.NaN()
+	sma := SMA(b.close, 5)
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, b, []Column{{Name: "sma", Values: sma}}, CSVWriteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadCSV(&buf, CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Len() != b.Len() || !got.HasTime() || !got.HasOpen() {
+		t.Fatalf("read %d bars, time %v, open %v", got.Len(), got.HasTime(), got.HasOpen())
+	}
+	for i := range b.time {
+		if !got.time[i].Equal(b.time[i]) {
+			t.Fatalf("time[%d] = %v, want %v", i, got.time[i], b.time[i])
+		}
+	}
+	// The shortest representation reads back exactly.
+	assertClose(t, "open", got.open, b.open, 0)
+	assertClose(t, "high", got.high, b.high, 0)
+	assertClose(t, "low", got.low, b.low, 0)
+	assertClose(t, "close", got.close, b.close, 0)
+	assertClose(t, "volume", got.volume, b.volume, 0)
+}
+
+func TestWriteCSVPrecision(t *testing.T) {
+	b, err := NewBars(BarColumns{High: []float64{1.25}, Low: []float64{0.5}, Close: []float64{1}, Volume: []float64{100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		precision *int
+		want      string
+	}{
+		{nil, "1.25,0.5,1,100"},
+		{new(int), "1,0,1,100"},
+	} {
+		var buf bytes.Buffer
+		if err := WriteCSV(&buf, b, nil, CSVWriteOptions{Precision: c.precision}); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if lines[1] != c.want {
+			t.Errorf("precision %v: %q, want %q", c.precision, lines[1], c.want)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	src := "Date;Open;High;Low;Adj Close;Vol\n" +
+		"2024-01-02;1;2;0.5;1.5;100\n" +
+		"2024-01-03;1.5;2.5;1;;200\n"
+	b, err := ReadCSV(strings.NewReader(src), CSVOptions{Comma: ';', Columns: map[string]string{"close": "Adj Close"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() != 2 || b.time[1].Day() != 3 || b.high[1] != 2.5 || !math.IsNaN(b.close[1]) || b.volume[0] != 100 {
+		t.Errorf("unexpected bars %+v", b)
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		src  string
+		opt  CSVOptions
+		want error
+	}{
+		{"missing column", "high,low,close\n1,1,1\n", CSVOptions{}, ErrMissingColumn},
+		{"unknown header", "high,low,close,volume\n", CSVOptions{Columns: map[string]string{"close": "last"}}, ErrMissingColumn},
+		{"short record", "1,1\n", CSVOptions{Fields: []string{"high", "low", "close", "volume"}}, ErrMissingColumn},
+	} {
+		_, err := ReadCSV(strings.NewReader(c.src), c.opt)
+		if !errors.Is(err, c.want) {
+			t.Errorf("%s: %v, want %v", c.name, err, c.want)
+		}
+	}
+
+	_, err := ReadCSV(strings.NewReader("high,low,close,volume\n1,1,1,1\n1,x,1,1\n"), CSVOptions{})
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("bad number: %v, want an error on line 3", err)
+	}
+}
@@ -1,18 +1,134 @@
 package indicators
 
-import cgotalib "github.com/blazer-org/cgo-talib"
+import "math"
 
+// Disp14 calculates the displacement of the close from its 14-bar SMA, in
+// percent. Values during warm-up are NaN.
 func Disp14(close []float64) []float64 {
 	disp14 := make([]float64, len(close))
-	sma14 := cgotalib.Sma(close, int32(14))
+	sma14 := taSma(close, int32(14))
 
 	for i := 0; i < len(close); i++ {
-		if i < len(sma14) {
+		if i < len(sma14) && i >= Disp14Lookback() {
 			disp14[i] = (close[i] - sma14[i]) / sma14[i] * 100
 		} else {
-			disp14[i] = 0
+			disp14[i] = math.NaN()
 		}
 	}
 
 	return disp14
 }
+
+// Disp14Checked is like Disp14 but reports insufficient data as an error.
+func Disp14Checked(close []float64) ([]float64, error) {
+	if err := checkData("Disp14", len(close), Disp14Lookback()); err != nil {
+		return nil, err
+	}
+	return Disp14(close), nil
+}
+
+// Disp14MA is like Disp14 with the 14-bar average of type t. MASMA is the
+// TA-Lib SMA of Disp14. As in Disp14, values during warm-up are NaN.
+func Disp14MA(close []float64, t MAType) []float64 {
+	if t == MASMA {
+		return Disp14(close)
+	}
+	disp14 := make([]float64, len(close))
+	s := NewDisp14MA(t)
+	for i, c := range close {
+		disp14[i], _ = s.Update(c)
+	}
+	return disp14
+}
+
+// Disp14MAChecked is like Disp14MA but reports invalid input as an error.
+func Disp14MAChecked(close []float64, t MAType) ([]float64, error) {
+	if err := firstError(
+		checkMA("Disp14", "period", 14, t),
+		checkData("Disp14", len(close), Disp14MALookback(t)),
+	); err != nil {
+		return nil, err
+	}
+	return Disp14MA(close, t), nil
+}
+
+// Disp14Bars calculates Disp14 over the closes of b.
+func Disp14Bars(b *Bars) ([]float64, error) {
+	return Disp14Checked(b.close)
+}
+
+// Disp14MABars calculates Disp14MA over the closes of b.
+func Disp14MABars(b *Bars, t MAType) ([]float64, error) {
+	return Disp14MAChecked(b.close, t)
+}
+
+// Disp14Lookback returns the number of leading warm-up values of Disp14.
+func Disp14Lookback() int {
+	return 13
+}
+
+// Disp14MALookback returns the number of leading warm-up values of Disp14MA.
+func Disp14MALookback(t MAType) int {
+	return MALookback(14, t)
+}
+
+// Disp14Stream is the streaming counterpart of Disp14.
+type Disp14Stream struct {
+	sma14 *talibSMA
+	ma    *MAStream // set instead of sma14 for other types than MASMA
+}
+
+// NewDisp14 returns a streaming 14-bar disparity index.
+func NewDisp14() *Disp14Stream {
+	return &Disp14Stream{sma14: newTalibSMA(14)}
+}
+
+// NewDisp14MA returns a streaming 14-bar disparity index from an average of
+// type t.
+func NewDisp14MA(t MAType) *Disp14Stream {
+	if t == MASMA {
+		return NewDisp14()
+	}
+	return &Disp14Stream{ma: NewMA(t, 14)}
+}
+
+// Update adds a close and returns the current disparity, or NaN during
+// warm-up.
+func (s *Disp14Stream) Update(close float64) (float64, bool) {
+	var ma float64
+	var ready bool
+	if s.ma != nil {
+		ma, ready = s.ma.Update(close)
+	} else {
+		ma, ready = s.sma14.update(close)
+	}
+	if !ready {
+		return math.NaN(), false
+	}
+	return (close - ma) / ma * 100, true
+}
+
+// Ready reports whether the average has left its warm-up period.
+func (s *Disp14Stream) Ready() bool {
+	if s.ma != nil {
+		return s.ma.Ready()
+	}
+	return s.sma14.window.full()
+}
+
+// Reset clears the average.
+func (s *Disp14Stream) Reset() {
+	if s.ma != nil {
+		s.ma.Reset()
+		return
+	}
+	s.sma14.reset()
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *Disp14Stream) Lookback() int {
+	if s.ma != nil {
+		return s.ma.Lookback()
+	}
+	return Disp14Lookback()
+}
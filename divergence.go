@@ -0,0 +1,350 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// DivergenceKind classifies a divergence between price and an oscillator.
+type DivergenceKind int
+
+const (
+	RegularBullish DivergenceKind = iota // lower price low, higher oscillator low
+	RegularBearish                       // higher price high, lower oscillator high
+	HiddenBullish                        // higher price low, lower oscillator low
+	HiddenBearish                        // lower price high, higher oscillator high
+)
+
+// String returns the kind name, e.g. "regular_bullish".
+func (k DivergenceKind) String() string {
+	switch k {
+	case RegularBullish:
+		return "regular_bullish"
+	case RegularBearish:
+		return "regular_bearish"
+	case HiddenBullish:
+		return "hidden_bullish"
+	case HiddenBearish:
+		return "hidden_bearish"
+	}
+	return "unknown"
+}
+
+// Bullish reports whether the divergence is formed by swing lows.
+func (k DivergenceKind) Bullish() bool { return k == RegularBullish || k == HiddenBullish }
+
+// DivergenceOptions configures DetectDivergences. Zero fields take the
+// defaults.
+type DivergenceOptions struct {
+	// Left and Right are the strength of the swing points of price and of
+	// the oscillator, as in SwingPivots. Both default to 5.
+	Left, Right int
+	// MinBars and MaxBars bound the distance between the two price pivots.
+	// They default to 5 and 60.
+	MinBars, MaxBars int
+	// Tolerance is the largest distance in bars between a price pivot and
+	// the oscillator pivot paired with it. Defaults to 3.
+	Tolerance int
+}
+
+func (o DivergenceOptions) withDefaults() DivergenceOptions {
+	if o.Left <= 0 {
+		o.Left = 5
+	}
+	if o.Right <= 0 {
+		o.Right = 5
+	}
+	if o.MinBars <= 0 {
+		o.MinBars = 5
+	}
+	if o.MaxBars <= 0 {
+		o.MaxBars = 60
+	}
+	if o.Tolerance <= 0 {
+		o.Tolerance = 3
+	}
+	return o
+}
+
+// Divergence is a divergence between two consecutive price pivots and the
+// oscillator pivots paired with them.
+type Divergence struct {
+	Kind DivergenceKind
+
+	// Bar indices of the price pivots and the oscillator pivots.
+	PriceFirst, PriceSecond           int
+	OscillatorFirst, OscillatorSecond int
+
+	// Confirmed is the bar at which the second pivots of both series were
+	// known and the divergence was reported.
+	Confirmed int
+	// Strength is the move of the oscillator between its pivots as a
+	// fraction of its range over them, in (0, 1].
+	Strength float64
+}
+
+// DivergenceResult holds the divergences found by DetectDivergences.
+type DivergenceResult struct {
+	// Signal is 1 at the confirmation of a bullish divergence, -1 at that
+	// of a bearish one and 0 elsewhere; a bullish and a bearish divergence
+	// confirmed at the same bar cancel out. It is NaN during warm-up, which
+	// lasts until the lookback has passed since the first bar at which both
+	// price and the oscillator are defined.
+	Signal []float64
+	// Divergences are in order of confirmation.
+	Divergences []Divergence
+}
+
+// DetectDivergences finds regular and hidden divergences between price and
+// oscillator. Consecutive swing lows (or highs) of price at least MinBars
+// and at most MaxBars apart are each paired with the nearest oscillator
+// swing low (or high) within Tolerance bars known at the time, and the pair
+// diverges if the two series move in opposite directions between them. A
+// divergence is reported once the second pivots of both series are
+// confirmed, so the signal does not look ahead. Undefined (NaN) oscillator
+// values are never pivots.
+func DetectDivergences(price, oscillator []float64, opt DivergenceOptions) DivergenceResult {
+	n := len(price)
+	if len(oscillator) != n {
+		return DivergenceResult{}
+	}
+	r := DivergenceResult{Signal: make([]float64, n)}
+	s := NewDivergences(opt)
+	for i := 0; i < n; i++ {
+		found, ready := s.Update(price[i], oscillator[i])
+		if !ready {
+			r.Signal[i] = math.NaN()
+		}
+		for _, d := range found {
+			if d.Kind.Bullish() {
+				r.Signal[i]++
+			} else {
+				r.Signal[i]--
+			}
+			r.Divergences = append(r.Divergences, d)
+		}
+	}
+	return r
+}
+
+// DetectDivergencesChecked is like DetectDivergences but reports invalid
+// input as an error instead of returning an empty result.
+func DetectDivergencesChecked(price, oscillator []float64, opt DivergenceOptions) (DivergenceResult, error) {
+	if opt.Left < 0 || opt.Right < 0 || opt.MinBars < 0 || opt.MaxBars < 0 || opt.Tolerance < 0 {
+		return DivergenceResult{}, &InputError{Func: "DetectDivergences", Err: ErrInvalidPeriod, Detail: fmt.Sprintf("%+v", opt)}
+	}
+	if d := opt.withDefaults(); d.MinBars > d.MaxBars {
+		return DivergenceResult{}, &InputError{Func: "DetectDivergences", Err: ErrInvalidPeriod, Detail: fmt.Sprintf("MinBars=%d > MaxBars=%d", d.MinBars, d.MaxBars)}
+	}
+	if err := firstError(
+		checkLengths("DetectDivergences", len(price), len(oscillator)),
+		checkData("DetectDivergences", len(price), DetectDivergencesLookback(opt)),
+	); err != nil {
+		return DivergenceResult{}, err
+	}
+	return DetectDivergences(price, oscillator, opt), nil
+}
+
+// DetectDivergencesBars calculates DetectDivergences between the source
+// price of b and oscillator, which must have one value per bar.
+func DetectDivergencesBars(b *Bars, source PriceSource, oscillator []float64, opt DivergenceOptions) (DivergenceResult, error) {
+	price, err := source.Series(b)
+	if err != nil {
+		return DivergenceResult{}, err
+	}
+	return DetectDivergencesChecked(price, oscillator, opt)
+}
+
+// Columns returns the signal together with the strength of each divergence
+// at its confirmation, which is NaN elsewhere.
+func (r DivergenceResult) Columns() []Column {
+	strength := make([]float64, len(r.Signal))
+	for i := range strength {
+		strength[i] = math.NaN()
+	}
+	for _, d := range r.Divergences {
+		strength[d.Confirmed] = d.Strength
+	}
+	return []Column{
+		{Name: "divergence", Values: r.Signal},
+		{Name: "divergence_strength", Values: strength},
+	}
+}
+
+// DetectDivergencesLookback returns the number of bars before the first bar
+// at which a divergence can be confirmed: two price pivots MinBars apart and
+// the confirmation of the second.
+func DetectDivergencesLookback(opt DivergenceOptions) int {
+	opt = opt.withDefaults()
+	return opt.Left + opt.MinBars + opt.Right
+}
+
+// divergenceSide tracks the swing lows (or highs) of DivergenceStream.
+type divergenceSide struct {
+	high       bool
+	last       Swing // last price pivot
+	hasLast    bool
+	pending    [][2]Swing // price pivot pairs awaiting oscillator pivots
+	oscillator []Swing    // recent oscillator pivots
+}
+
+func (d *divergenceSide) reset() {
+	d.hasLast = false
+	d.pending = d.pending[:0]
+	d.oscillator = d.oscillator[:0]
+}
+
+// DivergenceStream is the streaming counterpart of DetectDivergences.
+type DivergenceStream struct {
+	opt               DivergenceOptions
+	price, oscillator *SwingPivotStream
+	values            *ring // recent oscillator values
+	lows, highs       divergenceSide
+	found             []Divergence
+	seen              int
+	defined           int // bars since both values were first defined
+}
+
+// NewDivergences returns a streaming divergence detector.
+func NewDivergences(opt DivergenceOptions) *DivergenceStream {
+	opt = opt.withDefaults()
+	return &DivergenceStream{
+		opt:        opt,
+		price:      NewSwingPivots(opt.Left, opt.Right),
+		oscillator: NewSwingPivots(opt.Left, opt.Right),
+		values:     newRing(opt.MaxBars + 2*opt.Tolerance + opt.Right + 1),
+		highs:      divergenceSide{high: true},
+	}
+}
+
+// Update adds a bar and returns the divergences confirmed at it. The
+// returned slice is reused by the next call.
+func (s *DivergenceStream) Update(price, oscillator float64) ([]Divergence, bool) {
+	s.values.push(oscillator)
+	s.seen++
+	if s.defined > 0 || !(math.IsNaN(price) || math.IsNaN(oscillator)) {
+		s.defined++
+	}
+	s.found = s.found[:0]
+
+	// Oscillator pivots first, so that a price pivot confirmed at the same
+	// bar can be paired with them.
+	swings, _ := s.oscillator.Update(oscillator, oscillator)
+	for _, sw := range swings {
+		side := s.side(sw.High)
+		side.oscillator = append(side.oscillator, sw)
+	}
+	swings, _ = s.price.Update(price, price)
+	for _, sw := range swings {
+		side := s.side(sw.High)
+		if side.hasLast {
+			if gap := sw.Index - side.last.Index; gap >= s.opt.MinBars && gap <= s.opt.MaxBars {
+				side.pending = append(side.pending, [2]Swing{side.last, sw})
+			}
+		}
+		side.last, side.hasLast = sw, true
+	}
+	s.resolve(&s.lows)
+	s.resolve(&s.highs)
+	return s.found, s.Ready()
+}
+
+func (s *DivergenceStream) side(high bool) *divergenceSide {
+	if high {
+		return &s.highs
+	}
+	return &s.lows
+}
+
+// resolve decides the pending pairs of side whose oscillator pivots are
+// known, and drops those that can no longer be paired.
+func (s *DivergenceStream) resolve(side *divergenceSide) {
+	now := s.seen - 1
+	// Oscillator pivots too old to pair with any pending or future pivot.
+	oldest := now - s.opt.MaxBars - 2*s.opt.Tolerance - s.opt.Right
+	side.oscillator = slices.DeleteFunc(side.oscillator, func(sw Swing) bool { return sw.Index < oldest })
+
+	side.pending = slices.DeleteFunc(side.pending, func(pair [2]Swing) bool {
+		first, second := s.nearest(side, pair[0].Index), s.nearest(side, pair[1].Index)
+		if first < 0 || second < 0 || side.oscillator[first].Index >= side.oscillator[second].Index {
+			// A later oscillator pivot could still pair with the second
+			// price pivot until it can no longer be confirmed.
+			return now >= pair[1].Index+s.opt.Tolerance+s.opt.Right
+		}
+		if d, ok := s.diverge(side.high, pair, side.oscillator[first], side.oscillator[second]); ok {
+			s.found = append(s.found, d)
+		}
+		return true
+	})
+}
+
+// nearest returns the position in side.oscillator of the pivot nearest to
+// bar index within the tolerance, or -1.
+func (s *DivergenceStream) nearest(side *divergenceSide, index int) int {
+	best, bestDist := -1, s.opt.Tolerance+1
+	for k, sw := range side.oscillator {
+		dist := sw.Index - index
+		if dist < 0 {
+			dist = -dist
+		}
+		if dist < bestDist {
+			best, bestDist = k, dist
+		}
+	}
+	return best
+}
+
+// diverge classifies the price pivots pair against the oscillator pivots o1
+// and o2.
+func (s *DivergenceStream) diverge(high bool, pair [2]Swing, o1, o2 Swing) (Divergence, bool) {
+	dp, do := pair[1].Price-pair[0].Price, o2.Price-o1.Price
+	if dp == 0 || do == 0 || (dp > 0) == (do > 0) {
+		return Divergence{}, false
+	}
+	d := Divergence{
+		PriceFirst:       pair[0].Index,
+		PriceSecond:      pair[1].Index,
+		OscillatorFirst:  o1.Index,
+		OscillatorSecond: o2.Index,
+		Confirmed:        s.seen - 1,
+	}
+	switch {
+	case !high && dp < 0:
+		d.Kind = RegularBullish
+	case !high:
+		d.Kind = HiddenBullish
+	case dp > 0:
+		d.Kind = RegularBearish
+	default:
+		d.Kind = HiddenBearish
+	}
+
+	// The range of the oscillator over its pivots, from the values kept in
+	// the ring; its oldest value is that of bar seen-count.
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := o1.Index; i <= o2.Index; i++ {
+		if v := s.values.at(i - (s.seen - s.values.count)); !math.IsNaN(v) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	d.Strength = math.Abs(do) / (hi - lo)
+	return d, true
+}
+
+// Ready reports whether a divergence can have been confirmed since both
+// values were first defined.
+func (s *DivergenceStream) Ready() bool { return s.defined > s.Lookback() }
+
+// Reset clears the pivots and pending pairs.
+func (s *DivergenceStream) Reset() {
+	s.price.Reset()
+	s.oscillator.Reset()
+	s.values.reset()
+	s.lows.reset()
+	s.highs.reset()
+	s.seen, s.defined = 0, 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *DivergenceStream) Lookback() int { return DetectDivergencesLookback(s.opt) }
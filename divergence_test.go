@@ -0,0 +1,147 @@
+package indicators
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+var testDivergenceOptions = DivergenceOptions{Left: 2, Right: 2, MinBars: 5, MaxBars: 30, Tolerance: 2}
+
+// divergenceSeries returns price with lows p1 at bar 10 and p2 at bar 25,
+// and an oscillator with lows o1 at bar 11 and o2 at bar osc2.
+func divergenceSeries(p1, p2, o1, o2 float64, osc2 int) (price, oscillator []float64) {
+	price = waypoints([2]float64{0, 100}, [2]float64{10, p1}, [2]float64{17, 102}, [2]float64{25, p2}, [2]float64{35, 100})
+	oscillator = waypoints([2]float64{0, 50}, [2]float64{11, o1}, [2]float64{18, 55}, [2]float64{float64(osc2), o2}, [2]float64{35, 60})
+	return price, oscillator
+}
+
+func negate(s []float64) []float64 {
+	out := make([]float64, len(s))
+	for i, v := range s {
+		out[i] = -v
+	}
+	return out
+}
+
+func TestDetectDivergencesKinds(t *testing.T) {
+	for _, c := range []struct {
+		p1, p2, o1, o2 float64
+		kind, mirrored DivergenceKind
+	}{
+		{95, 90, 30, 35, RegularBullish, RegularBearish},
+		{90, 95, 35, 30, HiddenBullish, HiddenBearish},
+	} {
+		price, osc := divergenceSeries(c.p1, c.p2, c.o1, c.o2, 24)
+		for _, mirror := range []bool{false, true} {
+			want := Divergence{Kind: c.kind, PriceFirst: 10, PriceSecond: 25, OscillatorFirst: 11, OscillatorSecond: 24, Confirmed: 27, Strength: 5.0 / 25}
+			signal := 1.0
+			if mirror {
+				price, osc = negate(price), negate(osc)
+				want.Kind, signal = c.mirrored, -1
+			}
+			r := DetectDivergences(price, osc, testDivergenceOptions)
+			if !slices.Equal(r.Divergences, []Divergence{want}) {
+				t.Errorf("%v: %+v, want %+v", want.Kind, r.Divergences, want)
+				continue
+			}
+			for i, v := range r.Signal {
+				switch {
+				case i < DetectDivergencesLookback(testDivergenceOptions):
+					if !math.IsNaN(v) {
+						t.Errorf("%v: signal[%d] = %v during warm-up", want.Kind, i, v)
+					}
+				case i == 27 && v != signal, i != 27 && v != 0:
+					t.Errorf("%v: signal[%d] = %v", want.Kind, i, v)
+				}
+			}
+		}
+	}
+}
+
+func TestDetectDivergencesPairing(t *testing.T) {
+	// Both series make lower lows: no divergence.
+	price, osc := divergenceSeries(95, 90, 30, 25, 24)
+	if r := DetectDivergences(price, osc, testDivergenceOptions); len(r.Divergences) != 0 {
+		t.Errorf("no divergence: %+v", r.Divergences)
+	}
+
+	// The second oscillator low is four bars from the price low.
+	price, osc = divergenceSeries(95, 90, 30, 35, 21)
+	if r := DetectDivergences(price, osc, testDivergenceOptions); len(r.Divergences) != 0 {
+		t.Errorf("oscillator low beyond the tolerance: %+v", r.Divergences)
+	}
+	opt := testDivergenceOptions
+	opt.Tolerance = 4
+	r := DetectDivergences(price, osc, opt)
+	if len(r.Divergences) != 1 || r.Divergences[0].OscillatorSecond != 21 || r.Divergences[0].Confirmed != 27 {
+		t.Errorf("oscillator low within the tolerance: %+v", r.Divergences)
+	}
+
+	// The price lows are 15 bars apart.
+	price, osc = divergenceSeries(95, 90, 30, 35, 24)
+	opt = testDivergenceOptions
+	opt.MaxBars = 10
+	if r := DetectDivergences(price, osc, opt); len(r.Divergences) != 0 {
+		t.Errorf("price lows beyond MaxBars: %+v", r.Divergences)
+	}
+	opt = testDivergenceOptions
+	opt.MinBars = 20
+	if r := DetectDivergences(price, osc, opt); len(r.Divergences) != 0 {
+		t.Errorf("price lows within MinBars: %+v", r.Divergences)
+	}
+}
+
+func TestDetectDivergencesWarmup(t *testing.T) {
+	price, osc := divergenceSeries(95, 90, 30, 35, 24)
+	for i := 0; i < 5; i++ {
+		osc[i] = math.NaN()
+	}
+	r := DetectDivergences(price, osc, testDivergenceOptions)
+	lookback := 5 + DetectDivergencesLookback(testDivergenceOptions)
+	for i, v := range r.Signal {
+		if math.IsNaN(v) != (i < lookback) {
+			t.Errorf("signal[%d] = %v with %d warm-up bars", i, v, lookback)
+		}
+	}
+	cols := r.Columns()
+	if cols[1].Values[27] != 0.2 || !math.IsNaN(cols[1].Values[26]) {
+		t.Errorf("strength %v", cols[1].Values)
+	}
+}
+
+func TestDivergenceStreamReset(t *testing.T) {
+	_, _, _, close, _ := testOHLCV(400)
+	osc := ZScore(close, 20)
+	opt := DivergenceOptions{Left: 3, Right: 3}
+	want := DetectDivergences(close, osc, opt).Divergences
+	if len(want) == 0 {
+		t.Fatal("no divergences in the test data")
+	}
+	s := NewDivergences(opt)
+	for pass := 0; pass < 2; pass++ {
+		var got []Divergence
+		for i := range close {
+			found, _ := s.Update(close[i], osc[i])
+			got = append(got, found...)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("pass %d: stream differs from batch", pass)
+		}
+		s.Reset()
+	}
+}
+
+func TestDetectDivergencesChecked(t *testing.T) {
+	price, osc := divergenceSeries(95, 90, 30, 35, 24)
+	if _, err := DetectDivergencesChecked(price, osc, DivergenceOptions{MinBars: 70}); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("MinBars above MaxBars: %v", err)
+	}
+	if _, err := DetectDivergencesChecked(price, osc[1:], testDivergenceOptions); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("short oscillator: %v", err)
+	}
+	if _, err := DetectDivergencesChecked(price[:8], osc[:8], testDivergenceOptions); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("8 bars: %v", err)
+	}
+}
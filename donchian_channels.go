@@ -2,29 +2,8 @@ package indicators
 
 import "math"
 
-// minInSlice returns the minimum value in a slice
-func minInSlice(data []float64) float64 {
-	min := math.Inf(1)
-	for _, v := range data {
-		if !math.IsNaN(v) && v < min {
-			min = v
-		}
-	}
-	return min
-}
-
-// maxInSlice returns the maximum value in a slice
-func maxInSlice(data []float64) float64 {
-	max := math.Inf(-1)
-	for _, v := range data {
-		if !math.IsNaN(v) && v > max {
-			max = v
-		}
-	}
-	return max
-}
-
-// Donchian calculates the Donchian Channels
+// Donchian calculates the Donchian Channels. NaN values are ignored when
+// taking the window extremes.
 func Donchian(high, low []float64, lowerLen, upperLen int) ([]float64, []float64, []float64) {
 	n := len(high)
 	if n == 0 || len(low) != n {
@@ -42,25 +21,101 @@ func Donchian(high, low []float64, lowerLen, upperLen int) ([]float64, []float64
 	upper := make([]float64, n)
 	mid := make([]float64, n)
 
+	s := NewDonchian(lowerLen, upperLen)
 	for i := 0; i < n; i++ {
-		if i >= lowerLen-1 {
-			window := low[i-lowerLen+1 : i+1]
-			lower[i] = minInSlice(window)
-		} else {
-			lower[i] = math.NaN()
-		}
-		if i >= upperLen-1 {
-			window := high[i-upperLen+1 : i+1]
-			upper[i] = maxInSlice(window)
-		} else {
-			upper[i] = math.NaN()
-		}
-		if !math.IsNaN(lower[i]) && !math.IsNaN(upper[i]) {
-			mid[i] = 0.5 * (lower[i] + upper[i])
-		} else {
-			mid[i] = math.NaN()
-		}
+		lower[i], upper[i], mid[i], _ = s.Update(high[i], low[i])
 	}
 
 	return lower, upper, mid
 }
+
+// DonchianChecked is like Donchian but reports invalid input as an error.
+// Non-positive lengths still select the default of 20.
+func DonchianChecked(high, low []float64, lowerLen, upperLen int) (lower, upper, mid []float64, err error) {
+	if err := firstError(
+		checkLengths("Donchian", len(high), len(low)),
+		checkData("Donchian", len(high), DonchianLookback(lowerLen, upperLen)),
+	); err != nil {
+		return nil, nil, nil, err
+	}
+	lower, upper, mid = Donchian(high, low, lowerLen, upperLen)
+	return lower, upper, mid, nil
+}
+
+// DonchianBars calculates Donchian over b.
+func DonchianBars(b *Bars, lowerLen, upperLen int) (lower, upper, mid []float64, err error) {
+	return DonchianChecked(b.high, b.low, lowerLen, upperLen)
+}
+
+// DonchianColumns returns the results of Donchian as named columns.
+func DonchianColumns(lower, upper, mid []float64) []Column {
+	return []Column{
+		{Name: "donchian_lower", Values: lower},
+		{Name: "donchian_upper", Values: upper},
+		{Name: "donchian_mid", Values: mid},
+	}
+}
+
+// DonchianLookback returns the number of leading warm-up values of the
+// Donchian mid channel.
+func DonchianLookback(lowerLen, upperLen int) int {
+	if lowerLen <= 0 {
+		lowerLen = 20
+	}
+	if upperLen <= 0 {
+		upperLen = 20
+	}
+	if lowerLen > upperLen {
+		return lowerLen - 1
+	}
+	return upperLen - 1
+}
+
+// DonchianStream is the streaming counterpart of Donchian.
+type DonchianStream struct {
+	lows  *rollingExtreme
+	highs *rollingExtreme
+}
+
+// NewDonchian returns streaming Donchian Channels. Non-positive lengths
+// default to 20 as in Donchian.
+func NewDonchian(lowerLen, upperLen int) *DonchianStream {
+	if lowerLen <= 0 {
+		lowerLen = 20
+	}
+	if upperLen <= 0 {
+		upperLen = 20
+	}
+	return &DonchianStream{lows: newRollingMin(lowerLen), highs: newRollingMax(upperLen)}
+}
+
+// Update adds a bar and returns the lower, upper and mid channel values.
+// Channels whose window is not yet full are NaN.
+func (s *DonchianStream) Update(high, low float64) (lower, upper, mid float64, ready bool) {
+	s.lows.push(low)
+	s.highs.push(high)
+
+	lower, upper, mid = math.NaN(), math.NaN(), math.NaN()
+	if s.lows.full() {
+		lower = s.lows.value()
+	}
+	if s.highs.full() {
+		upper = s.highs.value()
+	}
+	if !math.IsNaN(lower) && !math.IsNaN(upper) {
+		mid = 0.5 * (lower + upper)
+	}
+	return lower, upper, mid, s.Ready()
+}
+
+// Ready reports whether both channel windows are full.
+func (s *DonchianStream) Ready() bool { return s.lows.full() && s.highs.full() }
+
+// Reset clears both windows.
+func (s *DonchianStream) Reset() {
+	s.lows.reset()
+	s.highs.reset()
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *DonchianStream) Lookback() int { return DonchianLookback(s.lows.period, s.highs.period) }
@@ -1,23 +1,22 @@
 package indicators
 
-import (
-	cgotalib "github.com/blazer-org/cgo-talib"
-)
+import "math"
 
 // ElderBull calculates the Elder Bull indicator.
-// It takes a slice of closing prices and returns a slice of Elder Bull values.
+// It takes a slice of closing prices and returns a slice of Elder Bull values,
+// NaN during warm-up.
 //
 //	Elder Bull formula:
 //	  Elder Bull = EMA(Close, 13) - EMA(Close, 26)
 func ElderBull(close []float64) []float64 {
-	ema13 := cgotalib.Ema(close, int32(13))
-	ema26 := cgotalib.Ema(close, int32(26))
+	ema13 := taEma(close, int32(13))
+	ema26 := taEma(close, int32(26))
 
 	elderBull := make([]float64, len(close))
 
 	for i := range close {
-		if i < 12 {
-			elderBull[i] = 0
+		if i < ElderBullLookback() {
+			elderBull[i] = math.NaN()
 		} else {
 			elderBull[i] = ema13[i] - ema26[i]
 		}
@@ -27,17 +26,18 @@ func ElderBull(close []float64) []float64 {
 }
 
 // ElderBear calculates the Elder Bear indicator.
-// It takes a slice of closing prices and returns a slice of Elder Bear values.
+// It takes a slice of closing prices and returns a slice of Elder Bear values,
+// NaN during warm-up.
 //
 //	Elder Bear formula:
 //	  Elder Bear = Close - EMA(Close, 13)
 func ElderBear(close []float64) []float64 {
-	ema := cgotalib.Ema(close, int32(13))
+	ema := taEma(close, int32(13))
 	elderBear := make([]float64, len(close))
 
 	for i, v := range close {
-		if i < 12 {
-			elderBear[i] = 0
+		if i < ElderBearLookback() {
+			elderBear[i] = math.NaN()
 		} else {
 			elderBear[i] = v - ema[i]
 		}
@@ -45,3 +45,256 @@ func ElderBear(close []float64) []float64 {
 
 	return elderBear
 }
+
+// ElderBullMA is like ElderBull with the 13 and 26 bar averages of type t.
+// MAEMA is the TA-Lib EMA of ElderBull, seeded with the SMA of the first
+// values.
+func ElderBullMA(close []float64, t MAType) []float64 {
+	if t == MAEMA {
+		return ElderBull(close)
+	}
+	out := make([]float64, len(close))
+	s := NewElderBullMA(t)
+	for i, c := range close {
+		out[i], _ = s.Update(c)
+	}
+	return out
+}
+
+// ElderBearMA is like ElderBear with the 13 bar average of type t. MAEMA is
+// the TA-Lib EMA of ElderBear.
+func ElderBearMA(close []float64, t MAType) []float64 {
+	if t == MAEMA {
+		return ElderBear(close)
+	}
+	out := make([]float64, len(close))
+	s := NewElderBearMA(t)
+	for i, c := range close {
+		out[i], _ = s.Update(c)
+	}
+	return out
+}
+
+// ElderBullChecked is like ElderBull but reports insufficient data as an
+// error.
+func ElderBullChecked(close []float64) ([]float64, error) {
+	if err := checkData("ElderBull", len(close), ElderBullLookback()); err != nil {
+		return nil, err
+	}
+	return ElderBull(close), nil
+}
+
+// ElderBearChecked is like ElderBear but reports insufficient data as an
+// error.
+func ElderBearChecked(close []float64) ([]float64, error) {
+	if err := checkData("ElderBear", len(close), ElderBearLookback()); err != nil {
+		return nil, err
+	}
+	return ElderBear(close), nil
+}
+
+// ElderBullMAChecked is like ElderBullMA but reports invalid input as an
+// error.
+func ElderBullMAChecked(close []float64, t MAType) ([]float64, error) {
+	if err := firstError(
+		checkMA("ElderBull", "period", 13, t),
+		checkData("ElderBull", len(close), ElderBullMALookback(t)),
+	); err != nil {
+		return nil, err
+	}
+	return ElderBullMA(close, t), nil
+}
+
+// ElderBearMAChecked is like ElderBearMA but reports invalid input as an
+// error.
+func ElderBearMAChecked(close []float64, t MAType) ([]float64, error) {
+	if err := firstError(
+		checkMA("ElderBear", "period", 13, t),
+		checkData("ElderBear", len(close), ElderBearMALookback(t)),
+	); err != nil {
+		return nil, err
+	}
+	return ElderBearMA(close, t), nil
+}
+
+// ElderBullBars calculates ElderBull over the closes of b.
+func ElderBullBars(b *Bars) ([]float64, error) {
+	return ElderBullChecked(b.close)
+}
+
+// ElderBearBars calculates ElderBear over the closes of b.
+func ElderBearBars(b *Bars) ([]float64, error) {
+	return ElderBearChecked(b.close)
+}
+
+// ElderBullMABars calculates ElderBullMA over the closes of b.
+func ElderBullMABars(b *Bars, t MAType) ([]float64, error) {
+	return ElderBullMAChecked(b.close, t)
+}
+
+// ElderBearMABars calculates ElderBearMA over the closes of b.
+func ElderBearMABars(b *Bars, t MAType) ([]float64, error) {
+	return ElderBearMAChecked(b.close, t)
+}
+
+// ElderBullLookback returns the number of leading warm-up values of
+// ElderBull.
+func ElderBullLookback() int {
+	return 25
+}
+
+// ElderBearLookback returns the number of leading warm-up values of
+// ElderBear.
+func ElderBearLookback() int {
+	return 12
+}
+
+// ElderBullMALookback returns the number of leading warm-up values of
+// ElderBullMA.
+func ElderBullMALookback(t MAType) int {
+	if t == MAEMA {
+		return ElderBullLookback()
+	}
+	return max(MALookback(13, t), MALookback(26, t))
+}
+
+// ElderBearMALookback returns the number of leading warm-up values of
+// ElderBearMA.
+func ElderBearMALookback(t MAType) int {
+	if t == MAEMA {
+		return ElderBearLookback()
+	}
+	return MALookback(13, t)
+}
+
+// ElderBullStream is the streaming counterpart of ElderBull.
+type ElderBullStream struct {
+	ema13, ema26 *talibEMA
+	ma13, ma26   *MAStream // set instead of ema13 and ema26 for other types than MAEMA
+	seen         int
+}
+
+// NewElderBull returns a streaming Elder Bull indicator.
+func NewElderBull() *ElderBullStream {
+	return &ElderBullStream{ema13: newTalibEMA(13), ema26: newTalibEMA(26)}
+}
+
+// NewElderBullMA returns a streaming Elder Bull indicator with averages of
+// type t.
+func NewElderBullMA(t MAType) *ElderBullStream {
+	if t == MAEMA {
+		return NewElderBull()
+	}
+	return &ElderBullStream{ma13: NewMA(t, 13), ma26: NewMA(t, 26)}
+}
+
+// Update adds a close and returns the current Elder Bull value.
+func (s *ElderBullStream) Update(close float64) (float64, bool) {
+	if s.ma13 != nil {
+		ma13, ready13 := s.ma13.Update(close)
+		ma26, ready26 := s.ma26.Update(close)
+		if !ready13 || !ready26 {
+			return math.NaN(), false
+		}
+		return ma13 - ma26, true
+	}
+	ema13, _ := s.ema13.update(close)
+	ema26, ready := s.ema26.update(close)
+	s.seen++
+	if !ready {
+		return math.NaN(), false
+	}
+	return ema13 - ema26, true
+}
+
+// Ready reports whether both averages have left their warm-up period.
+func (s *ElderBullStream) Ready() bool {
+	if s.ma13 != nil {
+		return s.ma13.Ready() && s.ma26.Ready()
+	}
+	return s.seen >= 26
+}
+
+// Reset clears both averages.
+func (s *ElderBullStream) Reset() {
+	if s.ma13 != nil {
+		s.ma13.Reset()
+		s.ma26.Reset()
+		return
+	}
+	s.ema13.reset()
+	s.ema26.reset()
+	s.seen = 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *ElderBullStream) Lookback() int {
+	if s.ma13 != nil {
+		return max(s.ma13.Lookback(), s.ma26.Lookback())
+	}
+	return ElderBullLookback()
+}
+
+// ElderBearStream is the streaming counterpart of ElderBear.
+type ElderBearStream struct {
+	ema  *talibEMA
+	ma   *MAStream // set instead of ema for other types than MAEMA
+	seen int
+}
+
+// NewElderBear returns a streaming Elder Bear indicator.
+func NewElderBear() *ElderBearStream {
+	return &ElderBearStream{ema: newTalibEMA(13)}
+}
+
+// NewElderBearMA returns a streaming Elder Bear indicator with an average of
+// type t.
+func NewElderBearMA(t MAType) *ElderBearStream {
+	if t == MAEMA {
+		return NewElderBear()
+	}
+	return &ElderBearStream{ma: NewMA(t, 13)}
+}
+
+// Update adds a close and returns the current Elder Bear value.
+func (s *ElderBearStream) Update(close float64) (float64, bool) {
+	if s.ma != nil {
+		ma, ready := s.ma.Update(close)
+		if !ready {
+			return math.NaN(), false
+		}
+		return close - ma, true
+	}
+	ema, ready := s.ema.update(close)
+	s.seen++
+	if !ready {
+		return math.NaN(), false
+	}
+	return close - ema, true
+}
+
+// Ready reports whether the average has left its warm-up period.
+func (s *ElderBearStream) Ready() bool {
+	if s.ma != nil {
+		return s.ma.Ready()
+	}
+	return s.seen >= 13
+}
+
+// Reset clears the average.
+func (s *ElderBearStream) Reset() {
+	if s.ma != nil {
+		s.ma.Reset()
+		return
+	}
+	s.ema.reset()
+	s.seen = 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *ElderBearStream) Lookback() int {
+	if s.ma != nil {
+		return s.ma.Lookback()
+	}
+	return ElderBearLookback()
+}
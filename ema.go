@@ -1,13 +1,17 @@
 package indicators
 
+// EMA calculates the Exponential Moving Average of prices with smoothing
+// factor 2/(span+1). The average is seeded with the first price, as pandas
+// does with adjust=False, so every value is defined. It panics if span is
+// not positive.
 func EMA(prices []float64, span int32) []float64 {
-	n := len(prices)
-	if n == 0 {
-		return nil
-	}
 	if span <= 0 {
 		panic("span must be greater than 0")
 	}
+	n := len(prices)
+	if n == 0 {
+		return []float64{}
+	}
 
 	alpha := 2.0 / float64(span+1) // 0.142857143
 	ema := make([]float64, n)
@@ -22,3 +26,63 @@ func EMA(prices []float64, span int32) []float64 {
 
 	return ema
 }
+
+// EMAChecked is like EMA but reports invalid input as an error instead of
+// panicking.
+func EMAChecked(prices []float64, span int32) ([]float64, error) {
+	if err := firstError(
+		checkPeriod("EMA", "span", int(span), 1),
+		checkData("EMA", len(prices), EMALookback()),
+	); err != nil {
+		return nil, err
+	}
+	return EMA(prices, span), nil
+}
+
+// EMABars calculates EMA over the closes of b.
+func EMABars(b *Bars, span int32) ([]float64, error) {
+	return EMAChecked(b.close, span)
+}
+
+// EMALookback returns the number of leading warm-up values of EMA. The
+// average is seeded with the first price, so there are none whatever the
+// span.
+func EMALookback() int {
+	return 0
+}
+
+// EMAStream is the streaming counterpart of EMA.
+type EMAStream struct {
+	alpha  float64
+	value  float64
+	seeded bool
+}
+
+// NewEMA returns a streaming Exponential Moving Average with the given span.
+func NewEMA(span int32) *EMAStream {
+	if span <= 0 {
+		panic("span must be greater than 0")
+	}
+	return &EMAStream{alpha: 2.0 / float64(span+1)}
+}
+
+// Update adds price and returns the current EMA. The first price seeds the
+// average, so the EMA is ready immediately.
+func (e *EMAStream) Update(price float64) (float64, bool) {
+	if !e.seeded {
+		e.value = price
+		e.seeded = true
+		return e.value, true
+	}
+	e.value = e.alpha*price + (1.0-e.alpha)*e.value
+	return e.value, true
+}
+
+// Ready reports whether at least one price has been seen.
+func (e *EMAStream) Ready() bool { return e.seeded }
+
+// Reset clears the average.
+func (e *EMAStream) Reset() { e.value, e.seeded = 0, false }
+
+// Lookback returns the number of warm-up bars.
+func (e *EMAStream) Lookback() int { return 0 }
@@ -0,0 +1,211 @@
+// Package engine computes a set of registered indicators over many symbols
+// concurrently.
+//
+// A Spec names the indicators to compute, as registered with package
+// indicators, and Run evaluates it over the bars of every symbol with a
+// bounded pool of workers:
+//
+//	spec := engine.Spec{Indicators: []engine.Indicator{
+//		{Name: "kvo"},
+//		{Name: "zscore", Params: indicators.Params{"window": 50}},
+//	}}
+//	results, err := engine.Run(ctx, bars, spec, engine.Options{Workers: 8})
+//
+// Results are sorted by symbol, so the output does not depend on the
+// scheduling of the workers. An error computing one symbol is recorded in
+// its result and does not stop the others.
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/blazer-org/indicators"
+)
+
+// Indicator is a registered indicator to compute, with its parameters.
+type Indicator struct {
+	Name   string
+	Params indicators.Params
+	// Prefix, if not empty, is prepended with an underscore to the names of
+	// the output columns, to tell apart indicators with the same outputs.
+	Prefix string
+}
+
+// Spec is the set of indicators computed for every symbol.
+type Spec struct {
+	Indicators []Indicator
+	// Warmup is applied to the warm-up values of every output, as by
+	// indicators.ApplyWarmup.
+	Warmup indicators.Warmup
+}
+
+// compiled is an Indicator with its descriptor and resolved parameters.
+type compiled struct {
+	Indicator
+	desc     *indicators.Descriptor
+	params   indicators.Params
+	lookback int
+}
+
+// compile looks up and resolves the indicators of s, and checks that their
+// output columns have distinct names.
+func (s Spec) compile() ([]compiled, error) {
+	out := make([]compiled, 0, len(s.Indicators))
+	names := map[string]string{}
+	for _, ind := range s.Indicators {
+		d, ok := indicators.Lookup(ind.Name)
+		if !ok {
+			return nil, fmt.Errorf("engine: unknown indicator %q", ind.Name)
+		}
+		params, err := d.Resolve(ind.Params)
+		if err != nil {
+			return nil, fmt.Errorf("engine: %w", err)
+		}
+		for _, o := range d.Outputs {
+			name := ind.column(o)
+			if prev, dup := names[name]; dup {
+				return nil, fmt.Errorf("engine: column %q of %s is also an output of %s; set a Prefix", name, ind.Name, prev)
+			}
+			names[name] = ind.Name
+		}
+		out = append(out, compiled{Indicator: ind, desc: d, params: params, lookback: d.Lookback(params)})
+	}
+	return out, nil
+}
+
+// column returns the name of the output column output.
+func (ind Indicator) column(output string) string {
+	if ind.Prefix == "" {
+		return output
+	}
+	return ind.Prefix + "_" + output
+}
+
+// Options configures Run.
+type Options struct {
+	// Workers is the number of symbols computed at once. Defaults to
+	// runtime.GOMAXPROCS(0).
+	Workers int
+}
+
+// Result holds the columns computed for a symbol, or the error that stopped
+// them.
+type Result struct {
+	Symbol  string
+	Columns []indicators.Column
+	Err     error
+}
+
+// Run computes spec over the bars of every symbol. It returns one Result per
+// symbol, sorted by symbol, together with ctx.Err() if ctx was cancelled
+// before every symbol was computed; symbols left over then carry that error.
+// A cancellation after the last symbol was computed is not reported.
+// It reports an invalid spec as an error before computing anything.
+func Run(ctx context.Context, bars map[string]*indicators.Bars, spec Spec, opt Options) ([]Result, error) {
+	inds, err := spec.compile()
+	if err != nil {
+		return nil, err
+	}
+	workers := opt.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	results := make([]Result, 0, len(bars))
+	for sym := range bars {
+		results = append(results, Result{Symbol: sym})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Symbol < results[j].Symbol })
+
+	// Each worker writes only the results at the indices it receives.
+	jobs := make(chan int)
+	var skipped atomic.Bool
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(results)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r := &results[i]
+				if err := ctx.Err(); err != nil {
+					r.Err = err
+					skipped.Store(true)
+					continue
+				}
+				r.Columns, r.Err = compute(bars[r.Symbol], inds, spec.Warmup)
+			}
+		}()
+	}
+	sent := 0
+feed:
+	for ; sent < len(results); sent++ {
+		select {
+		case jobs <- sent:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if sent == len(results) && !skipped.Load() {
+		return results, nil
+	}
+	for i := sent; i < len(results); i++ {
+		results[i].Err = ctx.Err()
+	}
+	return results, ctx.Err()
+}
+
+// compute calculates the indicators over b. A panicking indicator is
+// reported as an error rather than taking down the other symbols.
+func compute(b *indicators.Bars, inds []compiled, warmup indicators.Warmup) (cols []indicators.Column, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			cols, err = nil, fmt.Errorf("engine: panic: %v", p)
+		}
+	}()
+	if b == nil {
+		return nil, errors.New("engine: nil bars")
+	}
+	for _, ind := range inds {
+		out, err := ind.desc.Compute(b, ind.params)
+		if err != nil {
+			return nil, fmt.Errorf("engine: %s: %w", ind.Name, err)
+		}
+		for _, c := range out {
+			cols = append(cols, indicators.Column{
+				Name:   ind.column(c.Name),
+				Values: indicators.ApplyWarmup(c.Values, ind.lookback, warmup),
+			})
+		}
+	}
+	return cols, nil
+}
+
+// SymbolError is the error of a symbol's Result.
+type SymbolError struct {
+	Symbol string
+	Err    error
+}
+
+func (e *SymbolError) Error() string { return e.Symbol + ": " + e.Err.Error() }
+
+func (e *SymbolError) Unwrap() error { return e.Err }
+
+// Errors joins the errors of results into one, each wrapped in a
+// *SymbolError, or returns nil if every symbol succeeded.
+func Errors(results []Result) error {
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, &SymbolError{Symbol: r.Symbol, Err: r.Err})
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blazer-org/indicators"
+)
+
+func testBars(n int, phase float64) *indicators.Bars {
+	var b indicators.BarColumns
+	start := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		c := 100 + 10*math.Sin(float64(i)/7+phase)
+		b.Time = append(b.Time, start.Add(time.Duration(i)*time.Hour))
+		b.Open = append(b.Open, c-0.5)
+		b.High = append(b.High, c+1)
+		b.Low = append(b.Low, c-1)
+		b.Close = append(b.Close, c)
+		b.Volume = append(b.Volume, 1000+float64(i%10))
+	}
+	out, err := indicators.NewBars(b)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+func TestRun(t *testing.T) {
+	bars := map[string]*indicators.Bars{"c": testBars(100, 2), "a": testBars(100, 0), "b": testBars(100, 1)}
+	spec := Spec{Indicators: []Indicator{
+		{Name: "sma", Params: indicators.Params{"period": 5}},
+		{Name: "sma", Params: indicators.Params{"period": 10}, Prefix: "slow"},
+	}, Warmup: indicators.WarmupZero}
+	results, err := Run(context.Background(), bars, spec, Options{Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var symbols []string
+	for _, r := range results {
+		symbols = append(symbols, r.Symbol)
+		if r.Err != nil {
+			t.Fatalf("%s: %v", r.Symbol, r.Err)
+		}
+		if len(r.Columns) != 2 || r.Columns[0].Name != "sma" || r.Columns[1].Name != "slow_sma" {
+			t.Fatalf("%s: columns %+v", r.Symbol, r.Columns)
+		}
+		want := indicators.ApplyWarmup(indicators.SMA(bars[r.Symbol].Close(), 10), 9, indicators.WarmupZero)
+		if !slices.Equal(r.Columns[1].Values, want) {
+			t.Errorf("%s: slow_sma differs from SMA", r.Symbol)
+		}
+	}
+	if !slices.Equal(symbols, []string{"a", "b", "c"}) {
+		t.Errorf("symbols %v", symbols)
+	}
+}
+
+func TestRunSymbolErrors(t *testing.T) {
+	bars := map[string]*indicators.Bars{"ok": testBars(100, 0), "short": testBars(3, 0), "nil": nil}
+	spec := Spec{Indicators: []Indicator{{Name: "sma", Params: indicators.Params{"period": 5}}}}
+	results, err := Run(context.Background(), bars, spec, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if (r.Err == nil) != (r.Symbol == "ok") {
+			t.Errorf("%s: error %v", r.Symbol, r.Err)
+		}
+	}
+	err = Errors(results)
+	var se *SymbolError
+	if !errors.As(err, &se) || !errors.Is(err, indicators.ErrInsufficientData) {
+		t.Errorf("Errors = %v", err)
+	}
+	if Errors(results[1:2]) != nil {
+		t.Errorf("Errors of a success = %v", Errors(results[1:2]))
+	}
+}
+
+func TestRunInvalidSpec(t *testing.T) {
+	bars := map[string]*indicators.Bars{"a": testBars(100, 0)}
+	for _, c := range []struct {
+		spec Spec
+		want string
+	}{
+		{Spec{Indicators: []Indicator{{Name: "nope"}}}, "unknown indicator"},
+		{Spec{Indicators: []Indicator{{Name: "sma", Params: indicators.Params{"period": "x"}}}}, "period"},
+		{Spec{Indicators: []Indicator{{Name: "sma"}, {Name: "sma", Params: indicators.Params{"period": 9}}}}, "set a Prefix"},
+	} {
+		if _, err := Run(context.Background(), bars, c.spec, Options{}); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%+v: %v, want %q", c.spec, err, c.want)
+		}
+	}
+}
+
+// blockStarted and blockRelease are set by TestRunCancelled.
+var blockStarted, blockRelease chan struct{}
+
+func init() {
+	// engine_test_block signals blockStarted and waits for blockRelease.
+	indicators.Register(indicators.Descriptor{
+		Name:     "engine_test_block",
+		Doc:      "blocks until released",
+		Inputs:   []string{"close"},
+		Outputs:  []string{"engine_test_block"},
+		Lookback: func(indicators.Params) int { return 0 },
+		Compute: func(b *indicators.Bars, _ indicators.Params) ([]indicators.Column, error) {
+			blockStarted <- struct{}{}
+			<-blockRelease
+			return []indicators.Column{{Name: "engine_test_block", Values: b.Close()}}, nil
+		},
+	})
+}
+
+func TestRunCancelled(t *testing.T) {
+	bars := map[string]*indicators.Bars{"a": testBars(10, 0), "b": testBars(10, 0), "c": testBars(10, 0)}
+	spec := Spec{Indicators: []Indicator{{Name: "engine_test_block"}}}
+	blockStarted, blockRelease = make(chan struct{}), make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var results []Result
+	var err error
+	go func() {
+		results, err = Run(ctx, bars, spec, Options{Workers: 1})
+		close(done)
+	}()
+	// The only worker is computing "a" when the context is cancelled, so
+	// "b" and "c" are never sent to it.
+	<-blockStarted
+	cancel()
+	close(blockRelease)
+	<-done
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+	if len(results) != 3 || results[0].Err != nil || len(results[0].Columns) != 1 {
+		t.Fatalf("results %+v", results)
+	}
+	for _, r := range results[1:] {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("%s: %v, want context.Canceled", r.Symbol, r.Err)
+		}
+	}
+
+	// Cancelling while the last symbol is computed is no error.
+	blockStarted, blockRelease = make(chan struct{}), make(chan struct{})
+	ctx, cancel = context.WithCancel(context.Background())
+	done = make(chan struct{})
+	go func() {
+		results, err = Run(ctx, map[string]*indicators.Bars{"a": bars["a"]}, spec, Options{Workers: 1})
+		close(done)
+	}()
+	<-blockStarted
+	cancel()
+	close(blockRelease)
+	<-done
+	if err != nil || len(results) != 1 || results[0].Err != nil {
+		t.Errorf("Run cancelled after the last symbol = %+v, %v", results, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	results, err = Run(ctx, bars, Spec{Indicators: []Indicator{{Name: "sma"}}}, Options{})
+	if !errors.Is(err, context.Canceled) || len(results) != 3 {
+		t.Fatalf("Run with a cancelled context = %d results, %v", len(results), err)
+	}
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) || r.Columns != nil {
+			t.Errorf("%s: %v", r.Symbol, r.Err)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package indicators
 
+import "math"
+
 // CalculateEOM calculates the Ease of Movement (EOM) indicator with SMA smoothing.
+// The first bar has no predecessor and is NaN.
 func EOM(highs, lows, volumes []float64, window int) []float64 {
 	if len(highs) != len(lows) || len(lows) != len(volumes) {
 		panic("Input slices must have the same length")
@@ -8,6 +11,9 @@ func EOM(highs, lows, volumes []float64, window int) []float64 {
 
 	n := len(highs)
 	eomRaw := make([]float64, n)
+	if n > 0 {
+		eomRaw[0] = math.NaN()
+	}
 
 	// Calculate raw EOM values
 	for i := 1; i < n; i++ {
@@ -18,3 +24,59 @@ func EOM(highs, lows, volumes []float64, window int) []float64 {
 
 	return eomRaw
 }
+
+// EOMChecked is like EOM but reports invalid input as an error instead of
+// panicking.
+func EOMChecked(highs, lows, volumes []float64, window int) ([]float64, error) {
+	if err := firstError(
+		checkLengths("EOM", len(highs), len(lows), len(volumes)),
+		checkData("EOM", len(highs), EOMLookback()),
+	); err != nil {
+		return nil, err
+	}
+	return EOM(highs, lows, volumes, window), nil
+}
+
+// EOMBars calculates EOM over b.
+func EOMBars(b *Bars, window int) ([]float64, error) {
+	return EOMChecked(b.high, b.low, b.volume, window)
+}
+
+// EOMLookback returns the number of leading warm-up values of EOM.
+func EOMLookback() int {
+	return 1
+}
+
+// EOMStream is the streaming counterpart of EOM.
+type EOMStream struct {
+	prevHigh, prevLow float64
+	seen              int
+}
+
+// NewEOM returns a streaming Ease of Movement.
+func NewEOM() *EOMStream {
+	return &EOMStream{}
+}
+
+// Update adds a bar and returns its raw EOM value. The first bar has no
+// predecessor and yields NaN.
+func (s *EOMStream) Update(high, low, volume float64) (float64, bool) {
+	eom := math.NaN()
+	if s.seen > 0 {
+		distanceMoved := (high+low)/2 - (s.prevHigh+s.prevLow)/2
+		boxRatio := volume / 100000000 / (high - low)
+		eom = distanceMoved / boxRatio
+	}
+	s.prevHigh, s.prevLow = high, low
+	s.seen++
+	return eom, s.Ready()
+}
+
+// Ready reports whether a previous bar was available for the last update.
+func (s *EOMStream) Ready() bool { return s.seen > 1 }
+
+// Reset clears the previous bar.
+func (s *EOMStream) Reset() { *s = EOMStream{} }
+
+// Lookback returns the number of warm-up bars.
+func (s *EOMStream) Lookback() int { return 1 }
@@ -0,0 +1,84 @@
+package indicators
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Sentinel errors reported by the Checked variants of the indicators. Use
+// errors.Is to test for them.
+var (
+	// ErrLengthMismatch reports input slices of different lengths.
+	ErrLengthMismatch = errors.New("input slices have different lengths")
+	// ErrInvalidPeriod reports a period, window or length out of range.
+	ErrInvalidPeriod = errors.New("invalid period")
+	// ErrInvalidParam reports any other parameter out of range, such as an
+	// unknown method or type.
+	ErrInvalidParam = errors.New("invalid parameter")
+	// ErrInsufficientData reports an input too short to produce any value
+	// past the warm-up period.
+	ErrInsufficientData = errors.New("insufficient data")
+	// ErrMissingColumn reports that an optional Bars column required by the
+	// indicator is absent.
+	ErrMissingColumn = errors.New("missing column")
+	// ErrUnsortedTime reports a Time column that is not in ascending order.
+	ErrUnsortedTime = errors.New("time column not in ascending order")
+)
+
+// InputError describes invalid input passed to an indicator. It wraps one of
+// the sentinel errors above.
+type InputError struct {
+	Func   string // indicator name, e.g. "CMF"
+	Err    error  // sentinel error
+	Detail string // specifics about the offending input
+}
+
+func (e *InputError) Error() string {
+	msg := "indicators: " + e.Func + ": " + e.Err.Error()
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	return msg
+}
+
+func (e *InputError) Unwrap() error { return e.Err }
+
+// checkLengths reports ErrLengthMismatch unless all lengths are equal.
+func checkLengths(fn string, lengths ...int) error {
+	for _, n := range lengths[1:] {
+		if n != lengths[0] {
+			// Formatting a copy keeps lengths from escaping, so that the
+			// Into variants do not allocate it.
+			return &InputError{Func: fn, Err: ErrLengthMismatch, Detail: fmt.Sprint("lengths ", slices.Clone(lengths))}
+		}
+	}
+	return nil
+}
+
+// checkPeriod reports ErrInvalidPeriod if value is below min.
+func checkPeriod(fn, name string, value, min int) error {
+	if value < min {
+		return &InputError{Func: fn, Err: ErrInvalidPeriod, Detail: fmt.Sprintf("%s=%d, must be at least %d", name, value, min)}
+	}
+	return nil
+}
+
+// checkData reports ErrInsufficientData unless n bars leave at least one
+// value after a warm-up of lookback bars.
+func checkData(fn string, n, lookback int) error {
+	if n <= lookback {
+		return &InputError{Func: fn, Err: ErrInsufficientData, Detail: fmt.Sprintf("got %d bars, need at least %d", n, lookback+1)}
+	}
+	return nil
+}
+
+// firstError returns the first non-nil error.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
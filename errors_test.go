@@ -0,0 +1,71 @@
+package indicators
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckedErrors(t *testing.T) {
+	open, high, low, close, volume := testOHLCV(100)
+	short := close[:99]
+	few := close[:5]
+	err := func(_ any, err error) error { return err }
+	for _, c := range []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"SMA period", err(SMAChecked(close, 0)), ErrInvalidPeriod},
+		{"SMA data", err(SMAChecked(few, 10)), ErrInsufficientData},
+		{"EMA span", err(EMAChecked(close, 0)), ErrInvalidPeriod},
+		{"RollingStd window", err(RollingStdChecked(close, 1)), ErrInvalidPeriod},
+		{"ZScore data", err(ZScoreChecked(few, 20)), ErrInsufficientData},
+		{"HMA length", err(HMAChecked(close, 3)), ErrInvalidPeriod},
+		{"CMF lengths", err(CMFChecked(high, low, short, volume, 20)), ErrLengthMismatch},
+		{"RollingVWAP lengths", err(RollingVWAPChecked(high, low, close, short, 20)), ErrLengthMismatch},
+		{"ATRSMA period", err(ATRSMAChecked(high, low, close, -1)), ErrInvalidPeriod},
+		{"EOM lengths", err(EOMChecked(high, short, volume, 14)), ErrLengthMismatch},
+		{"ForceIndex lengths", err(ForceIndexChecked(close, short, 13)), ErrLengthMismatch},
+		{"VWRSI period", err(VWRSIChecked(close, volume, 0)), ErrInvalidPeriod},
+		{"PVT lengths", err(PVTChecked(close, short)), ErrLengthMismatch},
+		{"KVO lengths", err(KVOChecked(high, low, close, short)), ErrLengthMismatch},
+		{"KVO data", err(KVOChecked(nil, nil, nil, nil)), ErrInsufficientData},
+		{"Stochastic window", err(StochasticOscillatorChecked(high, low, close, 0, 3, false)), ErrInvalidPeriod},
+		{"Vortex lengths", err(VortexChecked(high, short, close, 14)), ErrLengthMismatch},
+		{"Ichimoku data", err(IchimokuChecked(few, few, few)), ErrInsufficientData},
+		{"InstBlockTrade lengths", err(InstBlockTradeChecked(open, short, volume)), ErrLengthMismatch},
+		{"HeadShoulders lengths", err(HeadShouldersChecked(close, short)), ErrLengthMismatch},
+		{"BbandsPercent data", err(BbandsPercentChecked(few)), ErrInsufficientData},
+		{"ElderBull data", err(ElderBullChecked(few)), ErrInsufficientData},
+		{"Disp14 data", err(Disp14Checked(few)), ErrInsufficientData},
+		{"MA type", err(MAChecked(close, 10, MAType(-1))), ErrInvalidParam},
+		{"Supertrend length", err(SupertrendChecked(high, low, close, 0, 3)), ErrInvalidPeriod},
+		{"CandlestickPattern pattern", err(CandlestickPatternChecked(open, high, low, close, CandlePattern(-1), CandleOptions{})), ErrInvalidParam},
+		{"AnchoredVWAP anchors", err(AnchoredVWAPChecked(close, volume, 10, 5)), ErrInvalidPeriod},
+	} {
+		if !errors.Is(c.err, c.want) {
+			t.Errorf("%s: %v, want %v", c.name, c.err, c.want)
+			continue
+		}
+		var ie *InputError
+		if !errors.As(c.err, &ie) || ie.Func == "" || !strings.HasPrefix(c.err.Error(), "indicators: "+ie.Func+": ") {
+			t.Errorf("%s: %v is not a well-formed InputError", c.name, c.err)
+		}
+	}
+}
+
+func TestCheckedMatchesUnchecked(t *testing.T) {
+	_, high, low, close, volume := testOHLCV(100)
+	got, err := CMFChecked(high, low, close, volume, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "CMF", got, CMF(high, low, close, volume, 20), 0)
+
+	kvo, err := KVOChecked(high, low, close, volume)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "KVO", kvo.KVO, KVO(high, low, close, volume).KVO, 0)
+}
@@ -0,0 +1,357 @@
+// Package expr compiles indicator expressions such as
+//
+//	zscore(rolling_vwap(high, low, close, volume, 20), 50)
+//	ema(cmf(h, l, c, v, 21), 9) - sma(close, 14)
+//	sma(close, 10) crosses_above sma(close, 30) && volume > 1e6
+//
+// into a computation graph over the functions of package indicators and
+// evaluates them over *indicators.Bars.
+//
+// Operands are the columns open, high, low, close and volume (o, h, l, c and
+// v for short), numeric constants and function calls; see Functions. Calls
+// take their series arguments first, followed by integer constants. The
+// operators are, from lowest to highest precedence:
+//
+//	||
+//	&&
+//	< <= > >= == != crosses_above crosses_below crosses
+//	+ -
+//	* /
+//	- ! (unary)
+//
+// Comparisons, crossings and logical operators produce 1 or 0. Every node
+// yields NaN during its warm-up period, and a NaN operand makes the result
+// NaN, so the warm-up of an expression is the longest warm-up along any of
+// its paths.
+package expr
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/blazer-org/indicators"
+)
+
+type nodeKind int
+
+const (
+	kindConst nodeKind = iota
+	kindColumn
+	kindUnary
+	kindBinary
+	kindCall
+)
+
+// columns maps the column names accepted in expressions to the canonical
+// ones.
+var columns = map[string]string{
+	"open": "open", "o": "open",
+	"high": "high", "h": "high",
+	"low": "low", "l": "low",
+	"close": "close", "c": "close",
+	"volume": "volume", "v": "volume",
+}
+
+// node is a vertex of the computation graph. Its arguments are indices of
+// earlier nodes, so evaluating the nodes in order computes every argument
+// before its users.
+type node struct {
+	kind     nodeKind
+	name     string
+	value    float64
+	args     []int
+	params   []int
+	fn       function
+	key      string // canonical form, shared by identical subexpressions
+	lookback int
+}
+
+// Program is a compiled expression. It is safe for concurrent use.
+type Program struct {
+	src   string
+	nodes []*node
+	index map[string]int
+}
+
+// Compile parses src and builds its computation graph. Identical
+// subexpressions are computed once.
+func Compile(src string) (*Program, error) {
+	a, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &Program{src: src, index: map[string]int{}}
+	if _, err := p.add(a); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// MustCompile is like Compile but panics if src cannot be compiled.
+func MustCompile(src string) *Program {
+	p, err := Compile(src)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source of the expression.
+func (p *Program) String() string { return p.src }
+
+// Lookback returns the number of leading NaN values of the result.
+func (p *Program) Lookback() int { return p.root().lookback }
+
+func (p *Program) root() *node { return p.nodes[len(p.nodes)-1] }
+
+// add adds a and its arguments to the graph and returns the index of its
+// node.
+func (p *Program) add(a *ast) (int, error) {
+	n := &node{kind: a.kind, name: a.name, value: a.value}
+	switch a.kind {
+	case kindConst:
+		n.key = fmt.Sprint(a.value)
+	case kindColumn:
+		col, ok := columns[a.name]
+		if !ok {
+			return 0, &SyntaxError{Pos: a.pos, Msg: fmt.Sprintf("unknown column %q", a.name)}
+		}
+		n.name = col
+		n.key = col
+	case kindUnary, kindBinary:
+		keys := make([]string, len(a.args))
+		for i, arg := range a.args {
+			idx, err := p.add(arg)
+			if err != nil {
+				return 0, err
+			}
+			n.args = append(n.args, idx)
+			n.lookback = max(n.lookback, p.nodes[idx].lookback)
+			keys[i] = p.nodes[idx].key
+		}
+		if a.kind == kindUnary {
+			n.key = fmt.Sprintf("(%s%s)", a.name, keys[0])
+		} else {
+			n.key = fmt.Sprintf("(%s %s %s)", keys[0], a.name, keys[1])
+			if crossOps[a.name] {
+				n.lookback++
+			}
+		}
+	case kindCall:
+		fn, ok := functions[a.name]
+		if !ok {
+			return 0, &SyntaxError{Pos: a.pos, Msg: fmt.Sprintf("unknown function %q", a.name)}
+		}
+		if len(a.args) != fn.series+len(fn.params) {
+			return 0, &SyntaxError{Pos: a.pos, Msg: fmt.Sprintf("%s takes %d arguments, got %d", a.name, fn.series+len(fn.params), len(a.args))}
+		}
+		n.fn = fn
+		n.key = a.name + "("
+		for i, arg := range a.args {
+			if i > 0 {
+				n.key += ","
+			}
+			if i >= fn.series {
+				if arg.kind != kindConst || arg.value != math.Trunc(arg.value) {
+					return 0, &SyntaxError{Pos: arg.pos, Msg: fmt.Sprintf("%s: %s must be an integer constant", a.name, fn.params[i-fn.series])}
+				}
+				n.params = append(n.params, int(arg.value))
+				n.key += fmt.Sprint(int(arg.value))
+				continue
+			}
+			idx, err := p.add(arg)
+			if err != nil {
+				return 0, err
+			}
+			n.args = append(n.args, idx)
+			n.lookback = max(n.lookback, p.nodes[idx].lookback)
+			n.key += p.nodes[idx].key
+		}
+		n.key += ")"
+		n.lookback += fn.lookback(n.params)
+	}
+	if idx, ok := p.index[n.key]; ok {
+		return idx, nil
+	}
+	p.nodes = append(p.nodes, n)
+	p.index[n.key] = len(p.nodes) - 1
+	return len(p.nodes) - 1, nil
+}
+
+// value is the result of a node: a constant or one value per bar.
+type value struct {
+	series []float64
+	scalar float64
+}
+
+func (v value) at(i int) float64 {
+	if v.series == nil {
+		return v.scalar
+	}
+	return v.series[i]
+}
+
+// Eval evaluates the expression over b and returns one value per bar.
+func (p *Program) Eval(b *indicators.Bars) ([]float64, error) {
+	n := b.Len()
+	vals := make([]value, len(p.nodes))
+	for i, nd := range p.nodes {
+		v, err := nd.eval(b, vals, n)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+	return broadcast(vals[len(vals)-1], n), nil
+}
+
+func (nd *node) eval(b *indicators.Bars, vals []value, n int) (value, error) {
+	switch nd.kind {
+	case kindConst:
+		return value{scalar: nd.value}, nil
+	case kindColumn:
+		var col []float64
+		switch nd.name {
+		case "open":
+			if !b.HasOpen() {
+				return value{}, fmt.Errorf("expr: %w: open", indicators.ErrMissingColumn)
+			}
+			col = b.Open()
+		case "high":
+			col = b.High()
+		case "low":
+			col = b.Low()
+		case "close":
+			col = b.Close()
+		case "volume":
+			col = b.Volume()
+		}
+		return value{series: col}, nil
+	case kindUnary:
+		x := vals[nd.args[0]]
+		if nd.name == "-" {
+			return mapValue(x, n, func(v float64) float64 { return -v }), nil
+		}
+		return mapValue(x, n, func(v float64) float64 {
+			if math.IsNaN(v) {
+				return v
+			}
+			return truth(v == 0)
+		}), nil
+	case kindBinary:
+		x, y := vals[nd.args[0]], vals[nd.args[1]]
+		if crossOps[nd.name] {
+			return value{series: cross(nd.name, x, y, n)}, nil
+		}
+		return binary(nd.name, x, y, n), nil
+	}
+	return nd.call(vals, n)
+}
+
+// call evaluates a function node. The leading warm-up of the arguments is
+// cut off before the call, so that functions which do not skip NaN, such as
+// recursive averages, see only defined values. The result, NaN during the
+// function's own warm-up, is padded back to n values with NaN.
+func (nd *node) call(vals []value, n int) (value, error) {
+	start := nd.lookback - nd.fn.lookback(nd.params)
+	start = min(start, n)
+	args := make([][]float64, len(nd.args))
+	for i, idx := range nd.args {
+		args[i] = broadcast(vals[idx], n)[start:]
+	}
+	res, err := nd.fn.call(args, nd.params)
+	if err != nil {
+		return value{}, err
+	}
+	out := make([]float64, n)
+	for i := 0; i < start; i++ {
+		out[i] = math.NaN()
+	}
+	copy(out[start:], res)
+	return value{series: out}, nil
+}
+
+func mapValue(x value, n int, f func(float64) float64) value {
+	if x.series == nil {
+		return value{scalar: f(x.scalar)}
+	}
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = f(x.series[i])
+	}
+	return value{series: out}
+}
+
+// broadcast returns x as a series of n values.
+func broadcast(x value, n int) []float64 {
+	if x.series != nil {
+		return x.series
+	}
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = x.scalar
+	}
+	return out
+}
+
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func binary(op string, x, y value, n int) value {
+	f := binaryOps[op]
+	if x.series == nil && y.series == nil {
+		return value{scalar: f(x.scalar, y.scalar)}
+	}
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = f(x.at(i), y.at(i))
+	}
+	return value{series: out}
+}
+
+var binaryOps = map[string]func(a, b float64) float64{
+	"+":  func(a, b float64) float64 { return a + b },
+	"-":  func(a, b float64) float64 { return a - b },
+	"*":  func(a, b float64) float64 { return a * b },
+	"/":  func(a, b float64) float64 { return a / b },
+	"<":  compare(func(a, b float64) bool { return a < b }),
+	"<=": compare(func(a, b float64) bool { return a <= b }),
+	">":  compare(func(a, b float64) bool { return a > b }),
+	">=": compare(func(a, b float64) bool { return a >= b }),
+	"==": compare(func(a, b float64) bool { return a == b }),
+	"!=": compare(func(a, b float64) bool { return a != b }),
+	"&&": compare(func(a, b float64) bool { return a != 0 && b != 0 }),
+	"||": compare(func(a, b float64) bool { return a != 0 || b != 0 }),
+}
+
+// compare turns a predicate into an operator yielding 1 or 0, or NaN if an
+// operand is NaN.
+func compare(pred func(a, b float64) bool) func(a, b float64) float64 {
+	return func(a, b float64) float64 {
+		if math.IsNaN(a) || math.IsNaN(b) {
+			return math.NaN()
+		}
+		return truth(pred(a, b))
+	}
+}
+
+// cross evaluates a crossing operator with indicators.Cross: x crosses_above
+// y at bar i if x was at or below y at bar i-1 and is above it at bar i.
+func cross(op string, x, y value, n int) []float64 {
+	out := indicators.Cross(broadcast(x, n), broadcast(y, n))
+	for i, c := range out {
+		switch op {
+		case "crosses_above":
+			out[i] = math.Max(c, 0)
+		case "crosses_below":
+			out[i] = math.Max(-c, 0)
+		default:
+			out[i] = math.Abs(c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/blazer-org/indicators"
+)
+
+func testBars(t *testing.T) *indicators.Bars {
+	t.Helper()
+	n := 60
+	high, low, close, volume := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range close {
+		close[i] = 100 + 10*math.Sin(float64(i)/5)
+		high[i], low[i] = close[i]+1, close[i]-1
+		volume[i] = 1000 + float64(i%7)*100
+	}
+	b, err := indicators.NewBars(indicators.BarColumns{High: high, Low: low, Close: close, Volume: volume})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestEvalMatchesIndicators(t *testing.T) {
+	b := testBars(t)
+	p := MustCompile("sma(close, 10) - sma(c, 10) + ema(close, 5)")
+	got, err := p.Eval(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := indicators.EMA(b.Close(), 5)
+	if lb := max(indicators.SMALookback(10), indicators.EMALookback()); p.Lookback() != lb {
+		t.Errorf("Lookback = %d, want %d", p.Lookback(), lb)
+	}
+	for i := p.Lookback(); i < len(got); i++ {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	// sma(close, 10) is defined from bar 9, so the sum is NaN until then.
+	if !math.IsNaN(got[8]) || math.IsNaN(got[9]) {
+		t.Errorf("warm-up ends at %v, %v; want NaN then a value", got[8], got[9])
+	}
+}
+
+func TestLag(t *testing.T) {
+	b := testBars(t)
+	p := MustCompile("lag(close, 3)")
+	got, err := p.Eval(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Lookback() != 3 || !math.IsNaN(got[2]) || got[3] != b.Close()[0] {
+		t.Errorf("lag(close, 3): lookback %d, got[2] %v, got[3] %v", p.Lookback(), got[2], got[3])
+	}
+}
+
+func TestLagRejectsNegativeBars(t *testing.T) {
+	b := testBars(t)
+	p, err := Compile("lag(close, -1)")
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	_, err = p.Eval(b)
+	if !errors.Is(err, indicators.ErrInvalidPeriod) {
+		t.Errorf("Eval = %v, want ErrInvalidPeriod", err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, src := range []string{
+		"sma(close)",
+		"sma(close, 1.5)",
+		"foo(close, 3)",
+		"close +",
+		"closing",
+	} {
+		var se *SyntaxError
+		if _, err := Compile(src); !errors.As(err, &se) {
+			t.Errorf("Compile(%q) = %v, want a SyntaxError", src, err)
+		}
+	}
+}
+
+func TestCrosses(t *testing.T) {
+	b := testBars(t)
+	got, err := MustCompile("close crosses_above 100").Eval(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(got); i++ {
+		want := 0.0
+		if b.Close()[i-1] <= 100 && b.Close()[i] > 100 {
+			want = 1
+		}
+		if got[i] != want {
+			t.Fatalf("[%d] = %v, want %v", i, got[i], want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package expr
+
+import (
+	"math"
+	"sort"
+
+	"github.com/blazer-org/indicators"
+)
+
+// function describes a function callable from an expression. Calls take the
+// series arguments first, followed by integer constants such as periods.
+type function struct {
+	series   int
+	params   []string
+	lookback func(params []int) int
+	call     func(args [][]float64, params []int) ([]float64, error)
+}
+
+var functions = map[string]function{
+	"sma": {
+		series:   1,
+		params:   []string{"period"},
+		lookback: func(p []int) int { return indicators.SMALookback(p[0]) },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			return indicators.SMAChecked(a[0], p[0])
+		},
+	},
+	"ema": {
+		series:   1,
+		params:   []string{"span"},
+		lookback: func([]int) int { return indicators.EMALookback() },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			return indicators.EMAChecked(a[0], int32(p[0]))
+		},
+	},
+	"hma": {
+		series:   1,
+		params:   []string{"length"},
+		lookback: func(p []int) int { return indicators.HMALookback(p[0]) },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			return indicators.HMAChecked(a[0], p[0])
+		},
+	},
+	"stdev": {
+		series:   1,
+		params:   []string{"window"},
+		lookback: func(p []int) int { return indicators.RollingStdLookback(p[0]) },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			return indicators.RollingStdChecked(a[0], p[0])
+		},
+	},
+	"zscore": {
+		series:   1,
+		params:   []string{"window"},
+		lookback: func(p []int) int { return indicators.ZScoreLookback(p[0]) },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			return indicators.ZScoreChecked(a[0], p[0])
+		},
+	},
+	"rolling_vwap": {
+		series:   4,
+		params:   []string{"period"},
+		lookback: func(p []int) int { return indicators.RollingVWAPLookback(p[0]) },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			return indicators.RollingVWAPChecked(a[0], a[1], a[2], a[3], p[0])
+		},
+	},
+	"cmf": {
+		series:   4,
+		params:   []string{"period"},
+		lookback: func(p []int) int { return indicators.CMFLookback(p[0]) },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			return indicators.CMFChecked(a[0], a[1], a[2], a[3], p[0])
+		},
+	},
+	"atr_sma": {
+		series:   3,
+		params:   []string{"period"},
+		lookback: func(p []int) int { return indicators.ATRSMALookback(p[0]) },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			return indicators.ATRSMAChecked(a[0], a[1], a[2], p[0])
+		},
+	},
+	"eom": {
+		series:   3,
+		params:   []string{"window"},
+		lookback: func(p []int) int { return indicators.EOMLookback() },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			return indicators.EOMChecked(a[0], a[1], a[2], p[0])
+		},
+	},
+	"force_index": {
+		series:   2,
+		params:   []string{"length"},
+		lookback: func(p []int) int { return indicators.ForceIndexLookback(p[0]) },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			return indicators.ForceIndexChecked(a[0], a[1], p[0])
+		},
+	},
+	"vwrsi": {
+		series:   2,
+		params:   []string{"period"},
+		lookback: func(p []int) int { return indicators.VWRSILookback(p[0]) },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			return indicators.VWRSIChecked(a[0], a[1], p[0])
+		},
+	},
+	"lag": {
+		series:   1,
+		params:   []string{"bars"},
+		lookback: func(p []int) int { return max(p[0], 0) },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			if p[0] < 0 {
+				return nil, &indicators.InputError{Func: "lag", Err: indicators.ErrInvalidPeriod, Detail: "bars must not be negative"}
+			}
+			out := make([]float64, len(a[0]))
+			for i := range out {
+				if i < p[0] {
+					out[i] = math.NaN()
+				} else {
+					out[i] = a[0][i-p[0]]
+				}
+			}
+			return out, nil
+		},
+	},
+	"abs": {
+		series:   1,
+		lookback: func([]int) int { return 0 },
+		call: func(a [][]float64, _ []int) ([]float64, error) {
+			out := make([]float64, len(a[0]))
+			for i, v := range a[0] {
+				out[i] = math.Abs(v)
+			}
+			return out, nil
+		},
+	},
+	"max": {
+		series:   2,
+		lookback: func([]int) int { return 0 },
+		call: func(a [][]float64, _ []int) ([]float64, error) {
+			return zip(a[0], a[1], math.Max), nil
+		},
+	},
+	"min": {
+		series:   2,
+		lookback: func([]int) int { return 0 },
+		call: func(a [][]float64, _ []int) ([]float64, error) {
+			return zip(a[0], a[1], math.Min), nil
+		},
+	},
+	"bars_since": {
+		series:   1,
+		lookback: func([]int) int { return 0 },
+		call: func(a [][]float64, _ []int) ([]float64, error) {
+			return indicators.BarsSince(a[0]), nil
+		},
+	},
+	"value_when": {
+		series:   2,
+		params:   []string{"occurrence"},
+		lookback: func([]int) int { return 0 },
+		call: func(a [][]float64, p []int) ([]float64, error) {
+			if p[0] < 0 {
+				return nil, &indicators.InputError{Func: "ValueWhen", Err: indicators.ErrInvalidPeriod, Detail: "occurrence must not be negative"}
+			}
+			return indicators.ValueWhen(a[0], a[1], p[0]), nil
+		},
+	},
+}
+
+// Functions returns the sorted names of the functions available in
+// expressions.
+func Functions() []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func zip(a, b []float64, f func(x, y float64) float64) []float64 {
+	out := make([]float64, len(a))
+	for i := range out {
+		out[i] = f(a[i], b[i])
+	}
+	return out
+}
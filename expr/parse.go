@@ -0,0 +1,250 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError reports an expression that cannot be compiled.
+type SyntaxError struct {
+	Pos int // byte offset in the source
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("expr: offset %d: %s", e.Pos, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators lists the symbolic operators, longest first.
+var operators = []string{"<=", ">=", "==", "!=", "&&", "||", "+", "-", "*", "/", "<", ">", "!"}
+
+// crossOps are the crossing operators, written as infix keywords.
+var crossOps = map[string]bool{"crosses_above": true, "crosses_below": true, "crosses": true}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		r := rune(src[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case r == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case r == ',':
+			toks = append(toks, token{tokComma, ",", i})
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			j := i
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.') {
+				j++
+			}
+			if j < len(src) && (src[j] == 'e' || src[j] == 'E') {
+				j++
+				if j < len(src) && (src[j] == '+' || src[j] == '-') {
+					j++
+				}
+				for j < len(src) && unicode.IsDigit(rune(src[j])) {
+					j++
+				}
+			}
+			toks = append(toks, token{tokNumber, src[i:j], i})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || src[j] == '_') {
+				j++
+			}
+			word := src[i:j]
+			if crossOps[word] {
+				toks = append(toks, token{tokOp, word, i})
+			} else {
+				toks = append(toks, token{tokIdent, word, i})
+			}
+			i = j
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			toks = append(toks, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(toks, token{tokEOF, "", len(src)}), nil
+}
+
+// ast is a parsed expression before it is checked and added to the graph.
+type ast struct {
+	kind  nodeKind
+	name  string // operator, column or function name
+	value float64
+	args  []*ast
+	pos   int
+}
+
+// parser is a precedence-climbing parser. From lowest to highest
+// precedence the levels are ||, &&, comparisons and crossings, + -, * /
+// and the unary operators - and !.
+type parser struct {
+	toks []token
+	i    int
+}
+
+func parse(src string) (*ast, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	a, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+	}
+	return a, nil
+}
+
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"<":  3, "<=": 3, ">": 3, ">=": 3, "==": 3, "!=": 3,
+	"crosses_above": 3, "crosses_below": 3, "crosses": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5,
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) parseBinary(minPrec int) (*ast, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		prec, ok := precedence[t.text]
+		if t.kind != tokOp || !ok || prec <= minPrec {
+			return lhs, nil
+		}
+		p.next()
+		rhs, err := p.parseBinary(prec)
+		if err != nil {
+			return nil, err
+		}
+		lhs = &ast{kind: kindBinary, name: t.text, args: []*ast{lhs, rhs}, pos: t.pos}
+		// Comparisons do not chain: a < b < c is rejected.
+		if prec == 3 {
+			if n := p.peek(); n.kind == tokOp && precedence[n.text] == 3 {
+				return nil, &SyntaxError{Pos: n.pos, Msg: fmt.Sprintf("%q cannot follow a comparison", n.text)}
+			}
+		}
+	}
+}
+
+func (p *parser) parseUnary() (*ast, error) {
+	t := p.peek()
+	if t.kind == tokOp && (t.text == "-" || t.text == "!") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if t.text == "-" && x.kind == kindConst {
+			x.value = -x.value
+			x.pos = t.pos
+			return x, nil
+		}
+		return &ast{kind: kindUnary, name: t.text, args: []*ast{x}, pos: t.pos}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (*ast, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("invalid number %q", t.text)}
+		}
+		return &ast{kind: kindConst, value: v, pos: t.pos}, nil
+	case tokLParen:
+		x, err := p.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+		if r := p.next(); r.kind != tokRParen {
+			return nil, &SyntaxError{Pos: r.pos, Msg: "expected )"}
+		}
+		return x, nil
+	case tokIdent:
+		if p.peek().kind != tokLParen {
+			return &ast{kind: kindColumn, name: t.text, pos: t.pos}, nil
+		}
+		p.next()
+		call := &ast{kind: kindCall, name: t.text, pos: t.pos}
+		if p.peek().kind == tokRParen {
+			p.next()
+			return call, nil
+		}
+		for {
+			arg, err := p.parseBinary(0)
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			switch r := p.next(); r.kind {
+			case tokComma:
+				continue
+			case tokRParen:
+				return call, nil
+			default:
+				return nil, &SyntaxError{Pos: r.pos, Msg: "expected , or )"}
+			}
+		}
+	case tokEOF:
+		return nil, &SyntaxError{Pos: t.pos, Msg: "unexpected end of expression"}
+	}
+	return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+}
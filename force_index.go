@@ -1,7 +1,16 @@
 package indicators
 
-// ForceIndex calculates Force Index (FI)
+import "math"
+
+// ForceIndex calculates Force Index (FI). The first value has no price
+// change and is NaN.
 func ForceIndex(close, volume []float64, length int) []float64 {
+	return ForceIndexMA(close, volume, length, MAEMA)
+}
+
+// ForceIndexMA is like ForceIndex with the price change times volume
+// smoothed by an average of type t instead of the EMA.
+func ForceIndexMA(close, volume []float64, length int, t MAType) []float64 {
 	drift := 1
 	if len(close) != len(volume) {
 		panic("close and volume slices must have the same length")
@@ -20,5 +29,114 @@ func ForceIndex(close, volume []float64, length int) []float64 {
 		}
 	}
 
-	return EMA(pvDiff[drift:], int32(length))
+	fi := make([]float64, n)
+	for i := 0; i < drift && i < n; i++ {
+		fi[i] = math.NaN()
+	}
+	if n > drift {
+		copy(fi[drift:], MA(pvDiff[drift:], length, t))
+	}
+	return fi
 }
+
+// ForceIndexChecked is like ForceIndex but reports invalid input as an error
+// instead of panicking. A non-positive length still selects the default of
+// 13.
+func ForceIndexChecked(close, volume []float64, length int) ([]float64, error) {
+	if err := firstError(
+		checkLengths("ForceIndex", len(close), len(volume)),
+		checkData("ForceIndex", len(close), ForceIndexLookback(length)),
+	); err != nil {
+		return nil, err
+	}
+	return ForceIndex(close, volume, length), nil
+}
+
+// ForceIndexMAChecked is like ForceIndexMA but reports invalid input as an
+// error. A non-positive length still selects the default of 13.
+func ForceIndexMAChecked(close, volume []float64, length int, t MAType) ([]float64, error) {
+	if length <= 0 {
+		length = 13
+	}
+	if err := firstError(
+		checkLengths("ForceIndex", len(close), len(volume)),
+		checkMA("ForceIndex", "length", length, t),
+		checkData("ForceIndex", len(close), ForceIndexMALookback(length, t)),
+	); err != nil {
+		return nil, err
+	}
+	return ForceIndexMA(close, volume, length, t), nil
+}
+
+// ForceIndexBars calculates ForceIndex over b.
+func ForceIndexBars(b *Bars, length int) ([]float64, error) {
+	return ForceIndexChecked(b.close, b.volume, length)
+}
+
+// ForceIndexMABars calculates ForceIndexMA over b.
+func ForceIndexMABars(b *Bars, length int, t MAType) ([]float64, error) {
+	return ForceIndexMAChecked(b.close, b.volume, length, t)
+}
+
+// ForceIndexLookback returns the number of leading warm-up values of
+// ForceIndex.
+func ForceIndexLookback(length int) int {
+	return 1
+}
+
+// ForceIndexMALookback returns the number of leading warm-up values of
+// ForceIndexMA.
+func ForceIndexMALookback(length int, t MAType) int {
+	if length <= 0 {
+		length = 13
+	}
+	return 1 + MALookback(length, t)
+}
+
+// ForceIndexStream is the streaming counterpart of ForceIndex.
+type ForceIndexStream struct {
+	ma        *MAStream
+	prevClose float64
+	seen      int
+}
+
+// NewForceIndex returns a streaming Force Index. A non-positive length
+// defaults to 13 as in ForceIndex.
+func NewForceIndex(length int) *ForceIndexStream {
+	return NewForceIndexMA(length, MAEMA)
+}
+
+// NewForceIndexMA returns a streaming Force Index smoothed by an average of
+// type t. A non-positive length defaults to 13 as in ForceIndexMA.
+func NewForceIndexMA(length int, t MAType) *ForceIndexStream {
+	if length <= 0 {
+		length = 13
+	}
+	return &ForceIndexStream{ma: NewMA(t, length)}
+}
+
+// Update adds a bar and returns the current Force Index. The first bar has
+// no price change and yields NaN.
+func (s *ForceIndexStream) Update(close, volume float64) (float64, bool) {
+	prevClose := s.prevClose
+	s.prevClose = close
+	s.seen++
+	if s.seen == 1 {
+		return math.NaN(), false
+	}
+	return s.ma.Update((close - prevClose) * volume)
+}
+
+// Ready reports whether the average of the price changes has left its
+// warm-up period.
+func (s *ForceIndexStream) Ready() bool { return s.seen > 1 && s.ma.Ready() }
+
+// Reset clears the average and previous close.
+func (s *ForceIndexStream) Reset() {
+	s.ma.Reset()
+	s.prevClose = 0
+	s.seen = 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *ForceIndexStream) Lookback() int { return 1 + s.ma.Lookback() }
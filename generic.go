@@ -0,0 +1,215 @@
+package indicators
+
+import "math"
+
+// Float is the constraint of the generic indicators, such as SMAOf, which
+// accept float32 series to halve the memory of long histories. They
+// accumulate in float64 through the streaming indicators, so only the
+// inputs and outputs are rounded to T, and for float64 they return the same
+// values as their non-generic counterparts.
+type Float interface {
+	~float32 | ~float64
+}
+
+// streamOf runs update over data, converting each value to float64 and each
+// result back to T.
+func streamOf[T Float](data []T, update func(float64) (float64, bool)) []T {
+	out := make([]T, len(data))
+	for i, v := range data {
+		r, _ := update(float64(v))
+		out[i] = T(r)
+	}
+	return out
+}
+
+// nanOf returns n NaNs.
+func nanOf[T Float](n int) []T {
+	out := make([]T, n)
+	for i := range out {
+		out[i] = T(math.NaN())
+	}
+	return out
+}
+
+// SMAOf is SMA for any Float type.
+func SMAOf[T Float](data []T, period int) []T {
+	if period <= 0 {
+		return nanOf[T](len(data))
+	}
+	return streamOf(data, NewSMA(period).Update)
+}
+
+// SMAOfChecked is like SMAOf but reports invalid input as an error.
+func SMAOfChecked[T Float](data []T, period int) ([]T, error) {
+	if err := firstError(
+		checkPeriod("SMA", "period", period, 1),
+		checkData("SMA", len(data), SMALookback(period)),
+	); err != nil {
+		return nil, err
+	}
+	return SMAOf(data, period), nil
+}
+
+// EMAOf is EMA for any Float type.
+func EMAOf[T Float](prices []T, span int32) []T {
+	return streamOf(prices, NewEMA(span).Update)
+}
+
+// EMAOfChecked is like EMAOf but reports invalid input as an error instead
+// of panicking.
+func EMAOfChecked[T Float](prices []T, span int32) ([]T, error) {
+	if err := firstError(
+		checkPeriod("EMA", "span", int(span), 1),
+		checkData("EMA", len(prices), EMALookback()),
+	); err != nil {
+		return nil, err
+	}
+	return EMAOf(prices, span), nil
+}
+
+// MAOf is MA for any Float type.
+func MAOf[T Float](data []T, period int, t MAType) []T {
+	if period < t.minPeriod() {
+		return nanOf[T](len(data))
+	}
+	return streamOf(data, NewMA(t, period).Update)
+}
+
+// MAOfChecked is like MAOf but reports invalid input as an error.
+func MAOfChecked[T Float](data []T, period int, t MAType) ([]T, error) {
+	if err := firstError(
+		checkMA("MA", "period", period, t),
+		checkData("MA", len(data), MALookback(period, t)),
+	); err != nil {
+		return nil, err
+	}
+	return MAOf(data, period, t), nil
+}
+
+// RollingStdOf is RollingStd for any Float type.
+func RollingStdOf[T Float](data []T, window int) []T {
+	if window <= 0 {
+		return nanOf[T](len(data))
+	}
+	return streamOf(data, NewRollingStd(window).Update)
+}
+
+// RollingStdOfChecked is like RollingStdOf but reports invalid input as an
+// error.
+func RollingStdOfChecked[T Float](data []T, window int) ([]T, error) {
+	if err := firstError(
+		checkPeriod("RollingStd", "window", window, 2),
+		checkData("RollingStd", len(data), RollingStdLookback(window)),
+	); err != nil {
+		return nil, err
+	}
+	return RollingStdOf(data, window), nil
+}
+
+// ZScoreOf is ZScore for any Float type.
+func ZScoreOf[T Float](data []T, window int) []T {
+	if window <= 0 {
+		return nanOf[T](len(data))
+	}
+	return streamOf(data, NewZScore(window).Update)
+}
+
+// ZScoreOfChecked is like ZScoreOf but reports invalid input as an error.
+func ZScoreOfChecked[T Float](data []T, window int) ([]T, error) {
+	if err := firstError(
+		checkPeriod("ZScore", "window", window, 2),
+		checkData("ZScore", len(data), ZScoreLookback(window)),
+	); err != nil {
+		return nil, err
+	}
+	return ZScoreOf(data, window), nil
+}
+
+// DonchianOf is Donchian for any Float type.
+func DonchianOf[T Float](high, low []T, lowerLen, upperLen int) (lower, upper, mid []T) {
+	n := len(high)
+	if n == 0 || len(low) != n {
+		return nil, nil, nil
+	}
+	if lowerLen <= 0 {
+		lowerLen = 20
+	}
+	if upperLen <= 0 {
+		upperLen = 20
+	}
+	lower, upper, mid = make([]T, n), make([]T, n), make([]T, n)
+	s := NewDonchian(lowerLen, upperLen)
+	for i := 0; i < n; i++ {
+		l, u, m, _ := s.Update(float64(high[i]), float64(low[i]))
+		lower[i], upper[i], mid[i] = T(l), T(u), T(m)
+	}
+	return lower, upper, mid
+}
+
+// DonchianOfChecked is like DonchianOf but reports invalid input as an
+// error.
+func DonchianOfChecked[T Float](high, low []T, lowerLen, upperLen int) (lower, upper, mid []T, err error) {
+	if err := firstError(
+		checkLengths("Donchian", len(high), len(low)),
+		checkData("Donchian", len(high), DonchianLookback(lowerLen, upperLen)),
+	); err != nil {
+		return nil, nil, nil, err
+	}
+	lower, upper, mid = DonchianOf(high, low, lowerLen, upperLen)
+	return lower, upper, mid, nil
+}
+
+// CMFOf is CMF for any Float type.
+func CMFOf[T Float](highs, lows, closes, volumes []T, period int) []T {
+	n := len(highs)
+	if n != len(lows) || n != len(closes) || n != len(volumes) {
+		return []T{}
+	}
+	out := make([]T, n)
+	s := NewCMF(period)
+	for i := 0; i < n; i++ {
+		r, _ := s.Update(float64(highs[i]), float64(lows[i]), float64(closes[i]), float64(volumes[i]))
+		out[i] = T(r)
+	}
+	return out
+}
+
+// CMFOfChecked is like CMFOf but reports invalid input as an error.
+func CMFOfChecked[T Float](highs, lows, closes, volumes []T, period int) ([]T, error) {
+	if err := firstError(
+		checkLengths("CMF", len(highs), len(lows), len(closes), len(volumes)),
+		checkPeriod("CMF", "period", period, 1),
+		checkData("CMF", len(highs), CMFLookback(period)),
+	); err != nil {
+		return nil, err
+	}
+	return CMFOf(highs, lows, closes, volumes, period), nil
+}
+
+// RollingVWAPOf is RollingVWAP for any Float type.
+func RollingVWAPOf[T Float](highs, lows, closes, volumes []T, period int) []T {
+	n := len(highs)
+	if len(lows) != n || len(closes) != n || len(volumes) != n {
+		panic("all input slices must have the same length")
+	}
+	out := make([]T, n)
+	s := NewRollingVWAP(period)
+	for i := 0; i < n; i++ {
+		r, _ := s.Update(float64(highs[i]), float64(lows[i]), float64(closes[i]), float64(volumes[i]))
+		out[i] = T(r)
+	}
+	return out
+}
+
+// RollingVWAPOfChecked is like RollingVWAPOf but reports invalid input as an
+// error instead of panicking.
+func RollingVWAPOfChecked[T Float](highs, lows, closes, volumes []T, period int) ([]T, error) {
+	if err := firstError(
+		checkLengths("RollingVWAP", len(highs), len(lows), len(closes), len(volumes)),
+		checkPeriod("RollingVWAP", "period", period, 1),
+		checkData("RollingVWAP", len(highs), RollingVWAPLookback(period)),
+	); err != nil {
+		return nil, err
+	}
+	return RollingVWAPOf(highs, lows, closes, volumes, period), nil
+}
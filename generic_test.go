@@ -0,0 +1,107 @@
+package indicators
+
+import (
+	"errors"
+	"testing"
+)
+
+// price32 is a named float32 type, as accepted by the ~float32 constraint.
+type price32 float32
+
+func to32(s []float64) []float32 {
+	out := make([]float32, len(s))
+	for i, v := range s {
+		out[i] = float32(v)
+	}
+	return out
+}
+
+func to64[T Float](s []T) []float64 {
+	out := make([]float64, len(s))
+	for i, v := range s {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+func TestGenericFloat64MatchesBatch(t *testing.T) {
+	_, high, low, close, volume := testOHLCV(300)
+	assertClose(t, "SMAOf", SMAOf(close, 20), SMA(close, 20), 0)
+	assertClose(t, "EMAOf", EMAOf(close, 20), EMA(close, 20), 0)
+	assertClose(t, "RollingStdOf", RollingStdOf(close, 20), RollingStd(close, 20), 0)
+	assertClose(t, "ZScoreOf", ZScoreOf(close, 20), ZScore(close, 20), 0)
+	assertClose(t, "CMFOf", CMFOf(high, low, close, volume, 20), CMF(high, low, close, volume, 20), 0)
+	assertClose(t, "RollingVWAPOf", RollingVWAPOf(high, low, close, volume, 20), RollingVWAP(high, low, close, volume, 20), 0)
+	for _, ma := range []MAType{MASMA, MAEMA, MARMA, MAWMA, MAHMA, MADEMA, MATEMA, MAKAMA, MAALMA, MAT3, MAZLEMA} {
+		assertClose(t, "MAOf", MAOf(close, 14, ma), MA(close, 14, ma), 0)
+	}
+	lower, upper, mid := DonchianOf(high, low, 10, 20)
+	wl, wu, wm := Donchian(high, low, 10, 20)
+	assertClose(t, "DonchianOf lower", lower, wl, 0)
+	assertClose(t, "DonchianOf upper", upper, wu, 0)
+	assertClose(t, "DonchianOf mid", mid, wm, 0)
+}
+
+func TestGenericFloat32(t *testing.T) {
+	_, high, low, close, volume := testOHLCV(300)
+	h32, l32, c32, v32 := to32(high), to32(low), to32(close), to32(volume)
+	// Only the inputs and outputs are rounded: the float32 result is the
+	// rounded float64 result over the rounded inputs.
+	check := func(name string, got []float32, want []float64) {
+		t.Helper()
+		assertClose(t, name, to64(got), to64(to32(want)), 0)
+	}
+	check("SMAOf", SMAOf(c32, 20), SMA(to64(c32), 20))
+	check("EMAOf", EMAOf(c32, 20), EMA(to64(c32), 20))
+	check("RollingStdOf", RollingStdOf(c32, 20), RollingStd(to64(c32), 20))
+	check("ZScoreOf", ZScoreOf(c32, 20), ZScore(to64(c32), 20))
+	check("MAOf", MAOf(c32, 14, MAT3), MA(to64(c32), 14, MAT3))
+	check("CMFOf", CMFOf(h32, l32, c32, v32, 20), CMF(to64(h32), to64(l32), to64(c32), to64(v32), 20))
+	check("RollingVWAPOf", RollingVWAPOf(h32, l32, c32, v32, 20), RollingVWAP(to64(h32), to64(l32), to64(c32), to64(v32), 20))
+	_, upper, _ := DonchianOf(h32, l32, 10, 20)
+	_, want, _ := Donchian(to64(h32), to64(l32), 10, 20)
+	check("DonchianOf", upper, want)
+
+	// A float32 SMA stays within float32 precision of the float64 one.
+	assertClose(t, "SMAOf against float64", to64(SMAOf(c32, 20)), SMA(close, 20), 1e-4)
+
+	named := make([]price32, len(close))
+	for i, v := range close {
+		named[i] = price32(v)
+	}
+	assertClose(t, "SMAOf price32", to64(SMAOf(named, 20)), to64(SMAOf(c32, 20)), 0)
+}
+
+func TestGenericChecked(t *testing.T) {
+	_, high, low, close, volume := testOHLCV(10)
+	c32 := to32(close)
+	if _, err := SMAOfChecked(c32, 0); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("SMAOfChecked period 0: %v", err)
+	}
+	if _, err := EMAOfChecked(c32, 0); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("EMAOfChecked span 0: %v", err)
+	}
+	if _, err := MAOfChecked(c32, 20, MASMA); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("MAOfChecked 10 bars for 20: %v", err)
+	}
+	if _, err := RollingStdOfChecked(c32, 1); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("RollingStdOfChecked window 1: %v", err)
+	}
+	if _, err := ZScoreOfChecked(c32, 20); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("ZScoreOfChecked 10 bars for 20: %v", err)
+	}
+	if _, _, _, err := DonchianOfChecked(to32(high), to32(low[1:]), 3, 3); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("DonchianOfChecked short low: %v", err)
+	}
+	if _, err := CMFOfChecked(high, low, close, volume[1:], 3); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("CMFOfChecked short volume: %v", err)
+	}
+	if _, err := RollingVWAPOfChecked(high, low, close, volume, 0); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("RollingVWAPOfChecked period 0: %v", err)
+	}
+	got, err := SMAOfChecked(c32, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "SMAOfChecked", to64(got), to64(SMAOf(c32, 3)), 0)
+}
@@ -1,11 +1,19 @@
 package indicators
 
+import "math"
+
+// HeadShoulders flags bars where the last four highs alternate and the close
+// is below that of four bars earlier. See DetectHeadShoulders for a detector
+// of actual patterns. Values during warm-up are NaN.
 func HeadShoulders(close []float64, high []float64) []float64 {
 	if len(close) != len(high) {
 		panic("close and high slices must have the same length")
 	}
 
 	headShoulders := make([]float64, len(close))
+	for i := 0; i < 4 && i < len(close); i++ {
+		headShoulders[i] = math.NaN()
+	}
 	for i := 4; i < len(close); i++ {
 		if high[i-4] < high[i-3] && high[i-3] > high[i-2] && high[i-2] < high[i-1] && close[i] < close[i-4] {
 			headShoulders[i] = 1.0
@@ -16,3 +24,64 @@ func HeadShoulders(close []float64, high []float64) []float64 {
 
 	return headShoulders
 }
+
+// HeadShouldersChecked is like HeadShoulders but reports invalid input as an
+// error instead of panicking.
+func HeadShouldersChecked(close []float64, high []float64) ([]float64, error) {
+	if err := firstError(
+		checkLengths("HeadShoulders", len(close), len(high)),
+		checkData("HeadShoulders", len(close), HeadShouldersLookback()),
+	); err != nil {
+		return nil, err
+	}
+	return HeadShoulders(close, high), nil
+}
+
+// HeadShouldersBars calculates HeadShoulders over b.
+func HeadShouldersBars(b *Bars) ([]float64, error) {
+	return HeadShouldersChecked(b.close, b.high)
+}
+
+// HeadShouldersLookback returns the number of leading warm-up values of
+// HeadShoulders.
+func HeadShouldersLookback() int {
+	return 4
+}
+
+// HeadShouldersStream is the streaming counterpart of HeadShoulders.
+type HeadShouldersStream struct {
+	closes *ring
+	highs  *ring
+}
+
+// NewHeadShoulders returns a streaming head and shoulders signal.
+func NewHeadShoulders() *HeadShouldersStream {
+	return &HeadShouldersStream{closes: newRing(5), highs: newRing(5)}
+}
+
+// Update adds a bar and returns 1.0 when the pattern completes at it, or NaN
+// during warm-up.
+func (s *HeadShouldersStream) Update(close, high float64) (float64, bool) {
+	s.closes.push(close)
+	s.highs.push(high)
+	if !s.Ready() {
+		return math.NaN(), false
+	}
+	h := s.highs
+	if h.at(0) < h.at(1) && h.at(1) > h.at(2) && h.at(2) < h.at(3) && close < s.closes.at(0) {
+		return 1.0, true
+	}
+	return 0.0, true
+}
+
+// Ready reports whether five bars have been seen.
+func (s *HeadShouldersStream) Ready() bool { return s.highs.full() }
+
+// Reset clears the window.
+func (s *HeadShouldersStream) Reset() {
+	s.closes.reset()
+	s.highs.reset()
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *HeadShouldersStream) Lookback() int { return HeadShouldersLookback() }
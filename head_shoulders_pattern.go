@@ -0,0 +1,224 @@
+package indicators
+
+import (
+	"math"
+	"slices"
+)
+
+// HeadShouldersOptions configures DetectHeadShoulders. Zero fields take the
+// defaults.
+type HeadShouldersOptions struct {
+	// Pivot is the number of bars on each side of a swing high or low that
+	// must not exceed it. Defaults to 3.
+	Pivot int
+	// ShoulderTolerance is the largest difference between the two shoulders
+	// as a fraction of the height of the head above the neckline. Defaults
+	// to 0.3.
+	ShoulderTolerance float64
+	// MaxBreakoutBars is the number of bars after the right shoulder within
+	// which the close must break the neckline. Defaults to the distance
+	// between the shoulders.
+	MaxBreakoutBars int
+}
+
+func (o HeadShouldersOptions) withDefaults() HeadShouldersOptions {
+	if o.Pivot <= 0 {
+		o.Pivot = 3
+	}
+	if o.ShoulderTolerance <= 0 {
+		o.ShoulderTolerance = 0.3
+	}
+	return o
+}
+
+// HeadShouldersMatch is a confirmed head-and-shoulders pattern. For a top the
+// shoulders and head are swing highs and the neckline runs through the lowest
+// lows between them; for an inverse pattern the roles of highs and lows are
+// swapped.
+type HeadShouldersMatch struct {
+	Inverse bool
+
+	// Bar indices of the swing points.
+	LeftShoulder, Head, RightShoulder int
+	// Bar indices of the neckline points between the shoulders and the head.
+	LeftTrough, RightTrough int
+
+	// Breakout is the bar whose close broke the neckline.
+	Breakout int
+	// Neckline is the price of the neckline at Breakout, and NecklineSlope
+	// its change per bar.
+	Neckline      float64
+	NecklineSlope float64
+	// Target is the measured move: the neckline at Breakout moved by the
+	// height of the head above (or below) the neckline.
+	Target float64
+}
+
+// NecklineAt returns the price of the neckline at bar i.
+func (m HeadShouldersMatch) NecklineAt(i int) float64 {
+	return m.Neckline + m.NecklineSlope*float64(i-m.Breakout)
+}
+
+// HeadShouldersResult holds the patterns found by DetectHeadShoulders.
+type HeadShouldersResult struct {
+	// Signal is -1 at the breakout of a top, 1 at the breakout of an inverse
+	// pattern and 0 elsewhere, or NaN during warm-up.
+	Signal []float64
+	// Matches are the patterns in order of their breakout.
+	Matches []HeadShouldersMatch
+}
+
+// DetectHeadShoulders finds head-and-shoulders tops and inverse patterns
+// from swing highs and lows: a head beyond two shoulders of similar size,
+// all beyond a neckline through the extremes between them, confirmed by a
+// close through the neckline. A pattern is only reported once its right
+// shoulder is a confirmed swing point, so the signal does not look ahead.
+// Unlike HeadShoulders, it needs the high, low and close of every bar.
+func DetectHeadShoulders(high, low, close []float64, opt HeadShouldersOptions) HeadShouldersResult {
+	n := len(close)
+	if len(high) != n || len(low) != n {
+		return HeadShouldersResult{}
+	}
+	opt = opt.withDefaults()
+	r := HeadShouldersResult{Signal: make([]float64, n)}
+
+	tops := detectHeadShouldersTops(high, low, close, opt)
+
+	// An inverse pattern is a top of the mirrored prices.
+	neg := func(s []float64) []float64 {
+		out := make([]float64, len(s))
+		for i, v := range s {
+			out[i] = -v
+		}
+		return out
+	}
+	bottoms := detectHeadShouldersTops(neg(low), neg(high), neg(close), opt)
+	for i := range bottoms {
+		m := &bottoms[i]
+		m.Inverse = true
+		m.Neckline, m.NecklineSlope, m.Target = -m.Neckline, -m.NecklineSlope, -m.Target
+	}
+
+	// Merge both lists in order of breakout.
+	for len(tops) > 0 || len(bottoms) > 0 {
+		var m HeadShouldersMatch
+		if len(bottoms) == 0 || (len(tops) > 0 && tops[0].Breakout <= bottoms[0].Breakout) {
+			m, tops = tops[0], tops[1:]
+			r.Signal[m.Breakout] = -1
+		} else {
+			m, bottoms = bottoms[0], bottoms[1:]
+			r.Signal[m.Breakout] = 1
+		}
+		r.Matches = append(r.Matches, m)
+	}
+	for i := 0; i < n && i < DetectHeadShouldersLookback(opt); i++ {
+		r.Signal[i] = math.NaN()
+	}
+	return r
+}
+
+// detectHeadShouldersTops finds the head-and-shoulders tops of DetectHeadShoulders.
+func detectHeadShouldersTops(high, low, close []float64, opt HeadShouldersOptions) []HeadShouldersMatch {
+	n := len(close)
+	peaks := PivotHighs(high, opt.Pivot, opt.Pivot)
+	var matches []HeadShouldersMatch
+	for k := 2; k < len(peaks); k++ {
+		ls, head, rs := peaks[k-2].Index, peaks[k-1].Index, peaks[k].Index
+		if high[head] <= high[ls] || high[head] <= high[rs] {
+			continue
+		}
+		lt, rt := argExtreme(low, ls+1, head, false), argExtreme(low, head+1, rs, false)
+		if lt < 0 || rt < 0 {
+			continue
+		}
+		slope := (low[rt] - low[lt]) / float64(rt-lt)
+		neckAt := func(i int) float64 { return low[lt] + slope*float64(i-lt) }
+		height := high[head] - neckAt(head)
+		if high[ls] <= neckAt(ls) || high[rs] <= neckAt(rs) ||
+			math.Abs(high[ls]-high[rs]) > opt.ShoulderTolerance*height {
+			continue
+		}
+
+		maxBars := opt.MaxBreakoutBars
+		if maxBars <= 0 {
+			maxBars = rs - ls
+		}
+		for j := peaks[k].Confirmed; j < n && j <= rs+maxBars; j++ {
+			if high[j] > high[rs] {
+				break
+			}
+			if close[j] < neckAt(j) {
+				matches = append(matches, HeadShouldersMatch{
+					LeftShoulder:  ls,
+					Head:          head,
+					RightShoulder: rs,
+					LeftTrough:    lt,
+					RightTrough:   rt,
+					Breakout:      j,
+					Neckline:      neckAt(j),
+					NecklineSlope: slope,
+					Target:        neckAt(j) - height,
+				})
+				break
+			}
+		}
+	}
+	// Later patterns can break out first.
+	slices.SortStableFunc(matches, func(a, b HeadShouldersMatch) int { return a.Breakout - b.Breakout })
+	return matches
+}
+
+// argExtreme returns the index of the highest (or lowest) value in
+// values[from:to], or -1 if the range is empty.
+func argExtreme(values []float64, from, to int, high bool) int {
+	best := -1
+	for i := from; i < to; i++ {
+		if best < 0 || (high && values[i] > values[best]) || (!high && values[i] < values[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// DetectHeadShouldersChecked is like DetectHeadShoulders but reports invalid
+// input as an error instead of returning an empty result.
+func DetectHeadShouldersChecked(high, low, close []float64, opt HeadShouldersOptions) (HeadShouldersResult, error) {
+	if err := firstError(
+		checkLengths("DetectHeadShoulders", len(close), len(high), len(low)),
+		checkData("DetectHeadShoulders", len(close), DetectHeadShouldersLookback(opt)),
+	); err != nil {
+		return HeadShouldersResult{}, err
+	}
+	return DetectHeadShoulders(high, low, close, opt), nil
+}
+
+// DetectHeadShouldersBars calculates DetectHeadShoulders over b.
+func DetectHeadShouldersBars(b *Bars, opt HeadShouldersOptions) (HeadShouldersResult, error) {
+	return DetectHeadShouldersChecked(b.high, b.low, b.close, opt)
+}
+
+// Columns returns the signal together with the neckline and target at each
+// breakout, which are NaN elsewhere.
+func (r HeadShouldersResult) Columns() []Column {
+	neckline := make([]float64, len(r.Signal))
+	target := make([]float64, len(r.Signal))
+	for i := range neckline {
+		neckline[i], target[i] = math.NaN(), math.NaN()
+	}
+	for _, m := range r.Matches {
+		neckline[m.Breakout], target[m.Breakout] = m.Neckline, m.Target
+	}
+	return []Column{
+		{Name: "hs_signal", Values: r.Signal},
+		{Name: "hs_neckline", Values: neckline},
+		{Name: "hs_target", Values: target},
+	}
+}
+
+// DetectHeadShouldersLookback returns the number of bars before the first
+// bar at which a pattern can be confirmed: three swing points separated by
+// the lows between them, and the confirmation of the last.
+func DetectHeadShouldersLookback(opt HeadShouldersOptions) int {
+	opt = opt.withDefaults()
+	return 4*opt.Pivot + 2
+}
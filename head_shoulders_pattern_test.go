@@ -0,0 +1,108 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+// waypoints returns a series interpolated linearly between the given
+// (index, price) points.
+func waypoints(points ...[2]float64) []float64 {
+	last := points[len(points)-1]
+	out := make([]float64, int(last[0])+1)
+	for k := 1; k < len(points); k++ {
+		a, b := points[k-1], points[k]
+		for i := int(a[0]); i <= int(b[0]); i++ {
+			out[i] = a[1] + (b[1]-a[1])*(float64(i)-a[0])/(b[0]-a[0])
+		}
+	}
+	return out
+}
+
+// headShouldersTop is a top with shoulders at bars 5 and 25, the head at
+// bar 15 and a flat neckline at the lows of bars 10 and 20.
+func headShouldersTop() (high, low, close []float64) {
+	close = waypoints([2]float64{0, 100}, [2]float64{5, 110}, [2]float64{10, 104},
+		[2]float64{15, 120}, [2]float64{20, 104}, [2]float64{25, 110}, [2]float64{35, 90})
+	high, low = make([]float64, len(close)), make([]float64, len(close))
+	for i, c := range close {
+		high[i], low[i] = c+0.5, c-0.5
+	}
+	return high, low, close
+}
+
+func TestDetectHeadShouldersTop(t *testing.T) {
+	high, low, close := headShouldersTop()
+	r := DetectHeadShoulders(high, low, close, HeadShouldersOptions{})
+	if len(r.Matches) != 1 {
+		t.Fatalf("%d matches, want 1: %+v", len(r.Matches), r.Matches)
+	}
+	m := r.Matches[0]
+	if m.Inverse || m.LeftShoulder != 5 || m.Head != 15 || m.RightShoulder != 25 || m.LeftTrough != 10 || m.RightTrough != 20 {
+		t.Errorf("match %+v", m)
+	}
+	// The close first falls below the neckline at 103.5 on bar 29.
+	if m.Breakout != 29 || m.Neckline != 103.5 || m.NecklineSlope != 0 {
+		t.Errorf("breakout at %d through %v with slope %v", m.Breakout, m.Neckline, m.NecklineSlope)
+	}
+	if want := 103.5 - (120.5 - 103.5); m.Target != want {
+		t.Errorf("target %v, want %v", m.Target, want)
+	}
+	for i, s := range r.Signal {
+		switch {
+		case i < DetectHeadShouldersLookback(HeadShouldersOptions{}):
+			if !math.IsNaN(s) {
+				t.Errorf("signal[%d] = %v during warm-up", i, s)
+			}
+		case i == 29:
+			if s != -1 {
+				t.Errorf("signal at the breakout = %v, want -1", s)
+			}
+		case s != 0:
+			t.Errorf("signal[%d] = %v", i, s)
+		}
+	}
+}
+
+func TestDetectHeadShouldersInverse(t *testing.T) {
+	high, low, close := headShouldersTop()
+	mirror := func(s []float64) []float64 {
+		out := make([]float64, len(s))
+		for i, v := range s {
+			out[i] = 200 - v
+		}
+		return out
+	}
+	r := DetectHeadShoulders(mirror(low), mirror(high), mirror(close), HeadShouldersOptions{})
+	if len(r.Matches) != 1 {
+		t.Fatalf("%d matches, want 1", len(r.Matches))
+	}
+	m := r.Matches[0]
+	if !m.Inverse || m.Head != 15 || m.Breakout != 29 || m.Neckline != 96.5 || m.Target != 96.5+17 {
+		t.Errorf("match %+v", m)
+	}
+	if r.Signal[29] != 1 {
+		t.Errorf("signal at the breakout = %v, want 1", r.Signal[29])
+	}
+}
+
+func TestDetectHeadShouldersRejects(t *testing.T) {
+	high, low, close := headShouldersTop()
+	// A right shoulder far below the left one.
+	for i := 21; i <= 29; i++ {
+		d := 5 - math.Abs(float64(i-25))
+		close[i] -= d
+		high[i] -= d
+		low[i] -= d
+	}
+	r := DetectHeadShoulders(high, low, close, HeadShouldersOptions{ShoulderTolerance: 0.1})
+	if len(r.Matches) != 0 {
+		t.Errorf("uneven shoulders matched: %+v", r.Matches)
+	}
+
+	// No breakout within MaxBreakoutBars.
+	high, low, close = headShouldersTop()
+	if r := DetectHeadShoulders(high, low, close, HeadShouldersOptions{MaxBreakoutBars: 3}); len(r.Matches) != 0 {
+		t.Errorf("late breakout matched: %+v", r.Matches)
+	}
+}
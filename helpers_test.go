@@ -0,0 +1,66 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testOHLCV returns n deterministic bars that trend, reverse and oscillate,
+// so that every indicator leaves its warm-up period with varied values.
+func testOHLCV(n int) (open, high, low, close, volume []float64) {
+	open, high, low, close, volume = make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	prev := 100.0
+	for i := 0; i < n; i++ {
+		x := float64(i)
+		c := 100 + 10*math.Sin(x/15) + 3*math.Sin(x/3.7) + x/20
+		open[i] = prev
+		high[i] = math.Max(prev, c) + 0.5 + math.Abs(math.Sin(x/2.3))
+		low[i] = math.Min(prev, c) - 0.5 - math.Abs(math.Cos(x/1.9))
+		close[i] = c
+		volume[i] = 1000 + 400*math.Sin(x/5.1) + 10*x
+		prev = c
+	}
+	return open, high, low, close, volume
+}
+
+// assertClose fails unless got and want have the same length and agree
+// within tol, treating NaNs as equal.
+func assertClose(t *testing.T, name string, got, want []float64, tol float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: len = %d, want %d", name, len(got), len(want))
+	}
+	for i := range got {
+		if math.IsNaN(got[i]) && math.IsNaN(want[i]) {
+			continue
+		}
+		if math.IsNaN(got[i]) != math.IsNaN(want[i]) || math.Abs(got[i]-want[i]) > tol {
+			t.Fatalf("%s[%d] = %v, want %v", name, i, got[i], want[i])
+		}
+	}
+}
+
+// naiveSum returns the sum of w, added in order.
+func naiveSum(w []float64) float64 {
+	var s float64
+	for _, v := range w {
+		s += v
+	}
+	return s
+}
+
+// testBars returns testOHLCV as hourly Bars.
+func testBars(n int) *Bars {
+	open, high, low, close, volume := testOHLCV(n)
+	times := make([]time.Time, n)
+	start := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	for i := range times {
+		times[i] = start.Add(time.Duration(i) * time.Hour)
+	}
+	b, err := NewBars(BarColumns{Time: times, Open: open, High: high, Low: low, Close: close, Volume: volume})
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -2,11 +2,10 @@ package indicators
 
 import (
 	"math"
-
-	cgotalib "github.com/blazer-org/cgo-talib"
 )
 
 // HMA calculates the Hull Moving Average for the given data and period.
+// Values during warm-up are NaN.
 func HMA(data []float64, length int) []float64 {
 	if length < 1 {
 		length = 10
@@ -15,15 +14,95 @@ func HMA(data []float64, length int) []float64 {
 	half := length / 2
 	sqrtLength := int(math.Sqrt(float64(length)))
 
-	wmaFull := cgotalib.Wma(data, int32(length))
-	wmaHalf := cgotalib.Wma(data, int32(half))
+	wmaFull := taWma(data, int32(length))
+	wmaHalf := taWma(data, int32(half))
 
 	diff := Subtract(Multiply(wmaHalf, 2), wmaFull)
-	hma := cgotalib.Wma(diff, int32(sqrtLength))
+	hma := taWma(diff, int32(sqrtLength))
+	for i := 0; i < len(hma) && i < HMALookback(length); i++ {
+		hma[i] = math.NaN()
+	}
 
 	return hma
 }
 
+// HMAChecked is like HMA but reports invalid input as an error. Lengths
+// below 1 still select the default of 10; otherwise the length must be at
+// least 4 so that every intermediate WMA spans two or more values.
+func HMAChecked(data []float64, length int) ([]float64, error) {
+	if length >= 1 {
+		if err := checkPeriod("HMA", "length", length, 4); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkData("HMA", len(data), HMALookback(length)); err != nil {
+		return nil, err
+	}
+	return HMA(data, length), nil
+}
+
+// HMABars calculates HMA over the closes of b.
+func HMABars(b *Bars, length int) ([]float64, error) {
+	return HMAChecked(b.close, length)
+}
+
+// HMALookback returns the number of leading warm-up values of HMA.
+func HMALookback(length int) int {
+	if length < 1 {
+		length = 10
+	}
+	return length + int(math.Sqrt(float64(length))) - 2
+}
+
+// HMAStream is the streaming counterpart of HMA.
+type HMAStream struct {
+	wmaFull, wmaHalf, wmaSqrt *talibWMA
+	lookback                  int
+	seen                      int
+}
+
+// NewHMA returns a streaming Hull Moving Average. A length below 1 defaults
+// to 10 as in HMA.
+func NewHMA(length int) *HMAStream {
+	if length < 1 {
+		length = 10
+	}
+	sqrtLength := int(math.Sqrt(float64(length)))
+	return &HMAStream{
+		wmaFull:  newTalibWMA(length),
+		wmaHalf:  newTalibWMA(length / 2),
+		wmaSqrt:  newTalibWMA(sqrtLength),
+		lookback: length + sqrtLength - 2,
+	}
+}
+
+// Update adds v and returns the current HMA, or NaN during warm-up. As in
+// HMA, the intermediate averages are fed from the zero padding of the
+// full-length WMA before it is defined.
+func (s *HMAStream) Update(v float64) (float64, bool) {
+	wmaFull, _ := s.wmaFull.update(v)
+	wmaHalf, _ := s.wmaHalf.update(v)
+	hma, _ := s.wmaSqrt.update(wmaHalf*2 - wmaFull)
+	s.seen++
+	if !s.Ready() {
+		return math.NaN(), false
+	}
+	return hma, true
+}
+
+// Ready reports whether every WMA feeding the current value is defined.
+func (s *HMAStream) Ready() bool {
+	return s.wmaFull.valid && s.wmaHalf.valid && s.wmaSqrt.valid && s.seen > s.lookback
+}
+
+// Reset clears all averages.
+func (s *HMAStream) Reset() {
+	s.wmaFull.reset()
+	s.wmaHalf.reset()
+	s.wmaSqrt.reset()
+	s.seen = 0
+}
+
 // Subtract two series element-wise
 func Subtract(a, b []float64) []float64 {
 	length := len(a)
@@ -37,6 +116,15 @@ func Subtract(a, b []float64) []float64 {
 	return result
 }
 
+// SubtractChecked is like Subtract but reports series of different lengths
+// as an error instead of truncating to the shorter one.
+func SubtractChecked(a, b []float64) ([]float64, error) {
+	if err := checkLengths("Subtract", len(a), len(b)); err != nil {
+		return nil, err
+	}
+	return Subtract(a, b), nil
+}
+
 // Multiply a series by a scalar
 func Multiply(series []float64, scalar float64) []float64 {
 	result := make([]float64, len(series))
@@ -45,3 +133,6 @@ func Multiply(series []float64, scalar float64) []float64 {
 	}
 	return result
 }
+
+// Lookback returns the number of warm-up bars.
+func (s *HMAStream) Lookback() int { return s.lookback }
@@ -1,6 +1,7 @@
 package indicators
 
 import (
+	"fmt"
 	"math"
 )
 
@@ -10,99 +11,270 @@ type IchimokuResult struct {
 	KijunSen    []float64
 	SenkouSpanA []float64
 	SenkouSpanB []float64
-	ChikouSpan  []float64
+	ChikouSpan  []float64 // all NaN in causal mode
+
+	// FutureSpanA and FutureSpanB hold the cloud projected over the
+	// Displacement bars following the last input.
+	FutureSpanA []float64
+	FutureSpanB []float64
 }
 
-// highestHigh returns the highest value in a slice
-func highestHigh(data []float64) float64 {
-	high := data[0]
-	for _, v := range data {
-		if v > high {
-			high = v
-		}
-	}
-	return high
+// CalculateIchimoku computes Ichimoku Cloud lines from highs, lows, and closes.
+// NaN values are ignored when taking the window extremes.
+func Ichimoku(highs, lows, closes []float64) IchimokuResult {
+	return IchimokuWith(highs, lows, closes, DefaultIchimokuConfig())
 }
 
-// lowestLow returns the lowest value in a slice
-func lowestLow(data []float64) float64 {
-	low := data[0]
-	for _, v := range data {
-		if v < low {
-			low = v
-		}
+// IchimokuConfig holds the periods of Ichimoku. Zero periods take the
+// defaults of DefaultIchimokuConfig.
+type IchimokuConfig struct {
+	Tenkan       int // Tenkan-sen period
+	Kijun        int // Kijun-sen period
+	SenkouB      int // Senkou Span B period
+	Displacement int // bars the spans are plotted ahead and ChikouSpan behind
+	// Causal leaves ChikouSpan NaN, as it plots each close Displacement
+	// bars back and so uses future closes at every earlier bar.
+	Causal bool
+}
+
+// DefaultIchimokuConfig returns the traditional 9/26/52 periods with a
+// 26-bar displacement.
+func DefaultIchimokuConfig() IchimokuConfig {
+	return IchimokuConfig{Tenkan: 9, Kijun: 26, SenkouB: 52, Displacement: 26}
+}
+
+func (c IchimokuConfig) withDefaults() IchimokuConfig {
+	d := DefaultIchimokuConfig()
+	if c.Tenkan <= 0 {
+		c.Tenkan = d.Tenkan
+	}
+	if c.Kijun <= 0 {
+		c.Kijun = d.Kijun
+	}
+	if c.SenkouB <= 0 {
+		c.SenkouB = d.SenkouB
+	}
+	if c.Displacement <= 0 {
+		c.Displacement = d.Displacement
 	}
-	return low
+	return c
 }
 
-// CalculateIchimoku computes Ichimoku Cloud lines from highs, lows, and closes
-func Ichimoku(highs, lows, closes []float64) IchimokuResult {
+// IchimokuWith computes Ichimoku with the periods of cfg. The spans computed
+// over the last Displacement bars, which are plotted beyond the input, are
+// returned in FutureSpanA and FutureSpanB.
+func IchimokuWith(highs, lows, closes []float64, cfg IchimokuConfig) IchimokuResult {
 	n := len(highs)
-	if n != len(lows) || n != len(closes) || n < 52 {
+	if n != len(lows) || n != len(closes) {
 		return IchimokuResult{}
 	}
+	cfg = cfg.withDefaults()
+	disp := cfg.Displacement
 
-	tenkanSen := make([]float64, n)
-	kijunSen := make([]float64, n)
-	senkouSpanA := make([]float64, n)
-	senkouSpanB := make([]float64, n)
-	chikouSpan := make([]float64, n)
-
-	// Fill with NaN initially
-	for i := range tenkanSen {
-		tenkanSen[i] = math.NaN()
-		kijunSen[i] = math.NaN()
-		chikouSpan[i] = math.NaN()
+	r := IchimokuResult{
+		TenkanSen:   make([]float64, n),
+		KijunSen:    make([]float64, n),
+		SenkouSpanA: make([]float64, n),
+		SenkouSpanB: make([]float64, n),
+		ChikouSpan:  make([]float64, n),
+		FutureSpanA: make([]float64, disp),
+		FutureSpanB: make([]float64, disp),
+	}
+	for i := range r.ChikouSpan {
+		r.ChikouSpan[i] = math.NaN()
 	}
-	for i := range senkouSpanA {
-		senkouSpanA[i] = math.NaN()
-		senkouSpanB[i] = math.NaN()
+	for i := 0; i < disp; i++ {
+		r.FutureSpanA[i] = math.NaN()
+		r.FutureSpanB[i] = math.NaN()
 	}
 
+	s := NewIchimokuWith(cfg)
 	for i := 0; i < n; i++ {
-		// Tenkan-sen (9 periods)
-		if i >= 8 {
-			high := highestHigh(highs[i-8 : i+1])
-			low := lowestLow(lows[i-8 : i+1])
-			tenkanSen[i] = (high + low) / 2
+		p, _ := s.Update(highs[i], lows[i], closes[i])
+		r.TenkanSen[i] = p.TenkanSen
+		r.KijunSen[i] = p.KijunSen
+		r.SenkouSpanA[i] = p.SenkouSpanA
+		r.SenkouSpanB[i] = p.SenkouSpanB
+		if !cfg.Causal && i >= disp {
+			r.ChikouSpan[i-disp] = closes[i]
 		}
+	}
+	spanA, spanB := s.Projected()
+	copy(r.FutureSpanA[disp-len(spanA):], spanA)
+	copy(r.FutureSpanB[disp-len(spanB):], spanB)
+	return r
+}
 
-		// Kijun-sen (26 periods)
-		if i >= 25 {
-			high := highestHigh(highs[i-25 : i+1])
-			low := lowestLow(lows[i-25 : i+1])
-			kijunSen[i] = (high + low) / 2
-		}
+// IchimokuPoint holds the Ichimoku lines for a single bar.
+type IchimokuPoint struct {
+	TenkanSen   float64
+	KijunSen    float64
+	SenkouSpanA float64
+	SenkouSpanB float64
+	// ChikouSpan is the current close, which Ichimoku plots Displacement
+	// bars back.
+	ChikouSpan float64
+}
 
-		// Senkou Span A (shifted forward 26 periods)
-		if i >= 25 {
-			spanA := (tenkanSen[i] + kijunSen[i]) / 2
-			if i+26 < len(senkouSpanA) {
-				senkouSpanA[i+26] = spanA
-			}
-		}
+// IchimokuChecked is like Ichimoku but reports invalid input as an error
+// instead of returning an empty result.
+func IchimokuChecked(highs, lows, closes []float64) (IchimokuResult, error) {
+	return IchimokuWithChecked(highs, lows, closes, DefaultIchimokuConfig())
+}
 
-		// Senkou Span B (52 periods, shifted forward 26 periods)
-		if i >= 51 {
-			high := highestHigh(highs[i-51 : i+1])
-			low := lowestLow(lows[i-51 : i+1])
-			spanB := (high + low) / 2
-			if i+26 < len(senkouSpanB) {
-				senkouSpanB[i+26] = spanB
-			}
-		}
+// IchimokuWithChecked is like IchimokuWith but reports invalid input as an
+// error instead of returning an empty result. Negative periods are invalid.
+func IchimokuWithChecked(highs, lows, closes []float64, cfg IchimokuConfig) (IchimokuResult, error) {
+	if cfg.Tenkan < 0 || cfg.Kijun < 0 || cfg.SenkouB < 0 || cfg.Displacement < 0 {
+		return IchimokuResult{}, &InputError{Func: "Ichimoku", Err: ErrInvalidPeriod, Detail: fmt.Sprintf("%+v", cfg)}
+	}
+	if err := firstError(
+		checkLengths("Ichimoku", len(highs), len(lows), len(closes)),
+		checkData("Ichimoku", len(highs), IchimokuWithLookback(cfg)),
+	); err != nil {
+		return IchimokuResult{}, err
+	}
+	return IchimokuWith(highs, lows, closes, cfg), nil
+}
 
-		// Chikou Span (close shifted backward 26 periods)
-		if i >= 26 {
-			chikouSpan[i-26] = closes[i]
-		}
+// IchimokuBars calculates Ichimoku over b.
+func IchimokuBars(b *Bars) (IchimokuResult, error) {
+	return IchimokuChecked(b.high, b.low, b.close)
+}
+
+// IchimokuWithBars calculates IchimokuWith over b.
+func IchimokuWithBars(b *Bars, cfg IchimokuConfig) (IchimokuResult, error) {
+	return IchimokuWithChecked(b.high, b.low, b.close, cfg)
+}
+
+// Columns returns the Ichimoku lines as named columns.
+func (r IchimokuResult) Columns() []Column {
+	return []Column{
+		{Name: "tenkan_sen", Values: r.TenkanSen},
+		{Name: "kijun_sen", Values: r.KijunSen},
+		{Name: "senkou_span_a", Values: r.SenkouSpanA},
+		{Name: "senkou_span_b", Values: r.SenkouSpanB},
+		{Name: "chikou_span", Values: r.ChikouSpan},
 	}
+}
+
+// IchimokuLookback returns the number of leading warm-up values of the
+// Ichimoku lines, bounded by the displaced Senkou Span B. ChikouSpan is
+// instead undefined for the last 26 bars.
+func IchimokuLookback() int {
+	return IchimokuWithLookback(DefaultIchimokuConfig())
+}
+
+// IchimokuWithLookback returns the number of leading warm-up values of
+// IchimokuWith, bounded by the longest period plus the displacement.
+func IchimokuWithLookback(cfg IchimokuConfig) int {
+	cfg = cfg.withDefaults()
+	return max(cfg.Tenkan, cfg.Kijun, cfg.SenkouB) + cfg.Displacement - 1
+}
+
+// IchimokuStream is the streaming counterpart of Ichimoku.
+type IchimokuStream struct {
+	cfg                     IchimokuConfig
+	tenkanHighs, tenkanLows *rollingExtreme
+	kijunHighs, kijunLows   *rollingExtreme
+	spanBHighs, spanBLows   *rollingExtreme
+	// spanA and spanB hold the spans computed over the last Displacement
+	// bars, which are plotted Displacement bars ahead.
+	spanA, spanB *ring
+	seen         int
+}
 
-	return IchimokuResult{
-		TenkanSen:   tenkanSen,
-		KijunSen:    kijunSen,
-		SenkouSpanA: senkouSpanA,
-		SenkouSpanB: senkouSpanB,
-		ChikouSpan:  chikouSpan,
+// NewIchimoku returns a streaming Ichimoku Cloud with the default periods.
+func NewIchimoku() *IchimokuStream {
+	return NewIchimokuWith(DefaultIchimokuConfig())
+}
+
+// NewIchimokuWith returns a streaming Ichimoku Cloud with the periods of
+// cfg. Causal has no effect, since the stream never looks ahead.
+func NewIchimokuWith(cfg IchimokuConfig) *IchimokuStream {
+	cfg = cfg.withDefaults()
+	return &IchimokuStream{
+		cfg:         cfg,
+		tenkanHighs: newRollingMax(cfg.Tenkan),
+		tenkanLows:  newRollingMin(cfg.Tenkan),
+		kijunHighs:  newRollingMax(cfg.Kijun),
+		kijunLows:   newRollingMin(cfg.Kijun),
+		spanBHighs:  newRollingMax(cfg.SenkouB),
+		spanBLows:   newRollingMin(cfg.SenkouB),
+		spanA:       newRing(cfg.Displacement),
+		spanB:       newRing(cfg.Displacement),
 	}
 }
+
+// Update adds a bar and returns the Ichimoku lines plotted at it. Lines that
+// are still warming up are NaN.
+func (s *IchimokuStream) Update(high, low, close float64) (IchimokuPoint, bool) {
+	s.tenkanHighs.push(high)
+	s.kijunHighs.push(high)
+	s.spanBHighs.push(high)
+	s.tenkanLows.push(low)
+	s.kijunLows.push(low)
+	s.spanBLows.push(low)
+
+	p := IchimokuPoint{
+		TenkanSen:   math.NaN(),
+		KijunSen:    math.NaN(),
+		SenkouSpanA: math.NaN(),
+		SenkouSpanB: math.NaN(),
+		ChikouSpan:  close,
+	}
+
+	// Spans computed Displacement bars ago are plotted at this bar.
+	if s.spanA.full() {
+		p.SenkouSpanA = s.spanA.at(0)
+		p.SenkouSpanB = s.spanB.at(0)
+	}
+
+	if s.tenkanHighs.full() {
+		p.TenkanSen = (s.tenkanHighs.value() + s.tenkanLows.value()) / 2
+	}
+	if s.kijunHighs.full() {
+		p.KijunSen = (s.kijunHighs.value() + s.kijunLows.value()) / 2
+	}
+
+	spanB := math.NaN()
+	if s.spanBHighs.full() {
+		spanB = (s.spanBHighs.value() + s.spanBLows.value()) / 2
+	}
+	s.spanA.push((p.TenkanSen + p.KijunSen) / 2)
+	s.spanB.push(spanB)
+
+	s.seen++
+	return p, s.Ready()
+}
+
+// Projected returns the spans computed over the last Displacement bars, to
+// be plotted over the bars following the current one, oldest first.
+func (s *IchimokuStream) Projected() (spanA, spanB []float64) {
+	spanA = make([]float64, s.spanA.count)
+	spanB = make([]float64, s.spanB.count)
+	for i := range spanA {
+		spanA[i] = s.spanA.at(i)
+		spanB[i] = s.spanB.at(i)
+	}
+	return spanA, spanB
+}
+
+// Ready reports whether every line plotted at the current bar is available.
+func (s *IchimokuStream) Ready() bool { return s.seen > s.Lookback() }
+
+// Reset clears all windows.
+func (s *IchimokuStream) Reset() {
+	for _, r := range []*rollingExtreme{
+		s.tenkanHighs, s.tenkanLows, s.kijunHighs, s.kijunLows,
+		s.spanBHighs, s.spanBLows,
+	} {
+		r.reset()
+	}
+	s.spanA.reset()
+	s.spanB.reset()
+	s.seen = 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *IchimokuStream) Lookback() int { return IchimokuWithLookback(s.cfg) }
@@ -0,0 +1,61 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIchimokuCausalKeepsColumns(t *testing.T) {
+	b := testBars(200)
+	d, _ := Lookup("ichimoku")
+	for _, causal := range []bool{false, true} {
+		p, err := d.Resolve(Params{"causal": causal})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cols, err := d.Compute(b, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cols) != len(d.Outputs) {
+			t.Fatalf("causal=%v: %d columns, want %d", causal, len(cols), len(d.Outputs))
+		}
+		for i, c := range cols {
+			if c.Name != d.Outputs[i] {
+				t.Errorf("causal=%v: column %d is %s, want %s", causal, i, c.Name, d.Outputs[i])
+			}
+		}
+		chikou := cols[len(cols)-1].Values
+		if causal {
+			for i, v := range chikou {
+				if !math.IsNaN(v) {
+					t.Fatalf("causal chikou_span[%d] = %v, want NaN", i, v)
+				}
+			}
+		} else if chikou[0] != b.close[26] {
+			t.Errorf("chikou_span[0] = %v, want %v", chikou[0], b.close[26])
+		}
+	}
+}
+
+func TestIchimokuStreamMatchesBatch(t *testing.T) {
+	_, high, low, close, _ := testOHLCV(150)
+	r := Ichimoku(high, low, close)
+	s := NewIchimoku()
+	for i := range close {
+		p, _ := s.Update(high[i], low[i], close[i])
+		for _, c := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"tenkan_sen", p.TenkanSen, r.TenkanSen[i]},
+			{"kijun_sen", p.KijunSen, r.KijunSen[i]},
+			{"senkou_span_a", p.SenkouSpanA, r.SenkouSpanA[i]},
+			{"senkou_span_b", p.SenkouSpanB, r.SenkouSpanB[i]},
+		} {
+			if c.got != c.want && !(math.IsNaN(c.got) && math.IsNaN(c.want)) {
+				t.Fatalf("%s[%d]: stream %v, batch %v", c.name, i, c.got, c.want)
+			}
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package indicators
 
+import "math"
+
 // InstBlockTrade calculates the institutional block trade signal.
 // It first calculates the 50 SMA of the volume.
 // Then it checks if volume exceeds the 50 SMA times 5 and Close is less than Open.
 // If both conditions are met, it returns 1.0. Otherwise, it returns 0.0.
+// Values during warm-up are NaN.
 func InstBlockTrade(open []float64, close []float64, volume []float64) []float64 {
 	// Calculate the 50 SMA of the volume
 	volSMA := SMA(volume, 50)
@@ -14,7 +17,7 @@ func InstBlockTrade(open []float64, close []float64, volume []float64) []float64
 	// Loop through the data and calculate the signal
 	for i := 0; i < len(open); i++ {
 		if i < 50 {
-			result[i] = 0.0 // Not enough data for SMA
+			result[i] = math.NaN() // Not enough data for SMA
 			continue
 		}
 		if volume[i] > volSMA[i]*5 && close[i] < open[i] {
@@ -26,3 +29,67 @@ func InstBlockTrade(open []float64, close []float64, volume []float64) []float64
 
 	return result
 }
+
+// InstBlockTradeChecked is like InstBlockTrade but reports invalid input as
+// an error.
+func InstBlockTradeChecked(open []float64, close []float64, volume []float64) ([]float64, error) {
+	if err := firstError(
+		checkLengths("InstBlockTrade", len(open), len(close), len(volume)),
+		checkData("InstBlockTrade", len(open), InstBlockTradeLookback()),
+	); err != nil {
+		return nil, err
+	}
+	return InstBlockTrade(open, close, volume), nil
+}
+
+// InstBlockTradeBars calculates InstBlockTrade over b, which must have an
+// Open column.
+func InstBlockTradeBars(b *Bars) ([]float64, error) {
+	if err := b.requireOpen("InstBlockTrade"); err != nil {
+		return nil, err
+	}
+	return InstBlockTradeChecked(b.open, b.close, b.volume)
+}
+
+// InstBlockTradeLookback returns the number of leading warm-up values of
+// InstBlockTrade.
+func InstBlockTradeLookback() int {
+	return 50
+}
+
+// InstBlockTradeStream is the streaming counterpart of InstBlockTrade.
+type InstBlockTradeStream struct {
+	volSMA *SMAStream
+	seen   int
+}
+
+// NewInstBlockTrade returns a streaming institutional block trade signal.
+func NewInstBlockTrade() *InstBlockTradeStream {
+	return &InstBlockTradeStream{volSMA: NewSMA(50)}
+}
+
+// Update adds a bar and returns 1.0 when it is a block trade, 0.0 otherwise,
+// or NaN during warm-up.
+func (s *InstBlockTradeStream) Update(open, close, volume float64) (float64, bool) {
+	volSMA, _ := s.volSMA.Update(volume)
+	s.seen++
+	if s.seen <= 50 {
+		return math.NaN(), false // Not enough data for SMA
+	}
+	if volume > volSMA*5 && close < open {
+		return 1.0, true
+	}
+	return 0.0, true
+}
+
+// Ready reports whether the volume average is available.
+func (s *InstBlockTradeStream) Ready() bool { return s.seen > 50 }
+
+// Reset clears the volume average.
+func (s *InstBlockTradeStream) Reset() {
+	s.volSMA.Reset()
+	s.seen = 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *InstBlockTradeStream) Lookback() int { return InstBlockTradeLookback() }
@@ -0,0 +1,298 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+)
+
+// Workspace holds the streaming state reused by the Into variants, so that
+// recomputing an indicator over new data of the same shape allocates nothing
+// once dst has grown to the input length. Into variants exist for SMA, EMA,
+// MA and MAVolume, RollingStd, ZScore, CMF, RollingVWAP, KVO and KVOMA, and
+// Supertrend and SupertrendWith; the other indicators have none. It keeps one
+// stream per indicator and parameter set it has seen, so alternating periods
+// do not rebuild them, and it grows with every new parameter set; use a
+// fresh Workspace to release them. A Workspace is not safe for concurrent
+// use; give each goroutine its own. The zero value is ready to use, and a
+// nil *Workspace builds new streams on every call.
+type Workspace struct {
+	sma        cached[int, *SMAStream]
+	ema        cached[int32, *EMAStream]
+	ma         cached[maKey, *MAStream]
+	std        cached[int, *RollingStdStream]
+	zscore     cached[int, *ZScoreStream]
+	cmf        cached[int, *CMFStream]
+	vwap       cached[int, *RollingVWAPStream]
+	kvo        cached[MAType, *KVOStream]
+	supertrend cached[supertrendKey, *SupertrendStream]
+}
+
+// cached holds the streams built for each key, such as a period.
+type cached[K comparable, S interface{ Reset() }] struct {
+	streams map[K]S
+}
+
+// get returns the stream for key, reset, building it with build on the
+// first call with key.
+func (c *cached[K, S]) get(key K, build func() S) S {
+	if s, ok := c.streams[key]; ok {
+		s.Reset()
+		return s
+	}
+	if c.streams == nil {
+		c.streams = make(map[K]S)
+	}
+	s := build()
+	c.streams[key] = s
+	return s
+}
+
+// orNew returns ws, or a new Workspace if ws is nil.
+func (ws *Workspace) orNew() *Workspace {
+	if ws == nil {
+		return new(Workspace)
+	}
+	return ws
+}
+
+// resize returns dst with length n, reusing its array if it is large enough.
+func resize[T any](dst []T, n int) []T {
+	if cap(dst) < n {
+		return make([]T, n)
+	}
+	return dst[:n]
+}
+
+// SMAInto is like SMAChecked but writes into dst, grown as needed, and
+// returns it.
+func SMAInto(ws *Workspace, dst, data []float64, period int) ([]float64, error) {
+	if err := firstError(
+		checkPeriod("SMA", "period", period, 1),
+		checkData("SMA", len(data), SMALookback(period)),
+	); err != nil {
+		return dst, err
+	}
+	s := ws.orNew().sma.get(period, func() *SMAStream { return NewSMA(period) })
+	dst = resize(dst, len(data))
+	for i, v := range data {
+		dst[i], _ = s.Update(v)
+	}
+	return dst, nil
+}
+
+// EMAInto is like EMAChecked but writes into dst, grown as needed, and
+// returns it.
+func EMAInto(ws *Workspace, dst, prices []float64, span int32) ([]float64, error) {
+	if err := firstError(
+		checkPeriod("EMA", "span", int(span), 1),
+		checkData("EMA", len(prices), EMALookback()),
+	); err != nil {
+		return dst, err
+	}
+	s := ws.orNew().ema.get(span, func() *EMAStream { return NewEMA(span) })
+	dst = resize(dst, len(prices))
+	for i, v := range prices {
+		dst[i], _ = s.Update(v)
+	}
+	return dst, nil
+}
+
+// maKey holds the parameters of an MAStream.
+type maKey struct {
+	typ    MAType
+	period int
+}
+
+// MAInto is like MAChecked but writes into dst, grown as needed, and returns
+// it.
+func MAInto(ws *Workspace, dst, data []float64, period int, t MAType) ([]float64, error) {
+	return MAVolumeInto(ws, dst, data, nil, period, t)
+}
+
+// MAVolumeInto is like MAVolumeChecked but writes into dst, grown as needed,
+// and returns it.
+func MAVolumeInto(ws *Workspace, dst, data, volume []float64, period int, t MAType) ([]float64, error) {
+	if volume != nil {
+		if err := checkLengths("MA", len(data), len(volume)); err != nil {
+			return dst, err
+		}
+	}
+	if err := firstError(
+		checkMA("MA", "period", period, t),
+		checkData("MA", len(data), MALookback(period, t)),
+	); err != nil {
+		return dst, err
+	}
+	s := ws.orNew().ma.get(maKey{t, period}, func() *MAStream { return NewMA(t, period) })
+	dst = resize(dst, len(data))
+	for i, v := range data {
+		w := 1.0
+		if volume != nil {
+			w = volume[i]
+		}
+		dst[i], _ = s.UpdateVolume(v, w)
+	}
+	return dst, nil
+}
+
+// RollingStdInto is like RollingStdChecked but writes into dst, grown as
+// needed, and returns it.
+func RollingStdInto(ws *Workspace, dst, data []float64, window int) ([]float64, error) {
+	if err := firstError(
+		checkPeriod("RollingStd", "window", window, 2),
+		checkData("RollingStd", len(data), RollingStdLookback(window)),
+	); err != nil {
+		return dst, err
+	}
+	s := ws.orNew().std.get(window, func() *RollingStdStream { return NewRollingStd(window) })
+	dst = resize(dst, len(data))
+	for i, v := range data {
+		dst[i], _ = s.Update(v)
+	}
+	return dst, nil
+}
+
+// ZScoreInto is like ZScoreChecked but writes into dst, grown as needed, and
+// returns it.
+func ZScoreInto(ws *Workspace, dst, data []float64, window int) ([]float64, error) {
+	if err := firstError(
+		checkPeriod("ZScore", "window", window, 2),
+		checkData("ZScore", len(data), ZScoreLookback(window)),
+	); err != nil {
+		return dst, err
+	}
+	s := ws.orNew().zscore.get(window, func() *ZScoreStream { return NewZScore(window) })
+	dst = resize(dst, len(data))
+	for i, v := range data {
+		dst[i], _ = s.Update(v)
+	}
+	return dst, nil
+}
+
+// CMFInto is like CMFChecked but writes into dst, grown as needed, and
+// returns it.
+func CMFInto(ws *Workspace, dst, highs, lows, closes, volumes []float64, period int) ([]float64, error) {
+	if err := firstError(
+		checkLengths("CMF", len(highs), len(lows), len(closes), len(volumes)),
+		checkPeriod("CMF", "period", period, 1),
+		checkData("CMF", len(highs), CMFLookback(period)),
+	); err != nil {
+		return dst, err
+	}
+	s := ws.orNew().cmf.get(period, func() *CMFStream { return NewCMF(period) })
+	dst = resize(dst, len(highs))
+	for i := range dst {
+		dst[i], _ = s.Update(highs[i], lows[i], closes[i], volumes[i])
+	}
+	return dst, nil
+}
+
+// RollingVWAPInto is like RollingVWAPChecked but writes into dst, grown as
+// needed, and returns it.
+func RollingVWAPInto(ws *Workspace, dst, highs, lows, closes, volumes []float64, period int) ([]float64, error) {
+	if err := firstError(
+		checkLengths("RollingVWAP", len(highs), len(lows), len(closes), len(volumes)),
+		checkPeriod("RollingVWAP", "period", period, 1),
+		checkData("RollingVWAP", len(highs), RollingVWAPLookback(period)),
+	); err != nil {
+		return dst, err
+	}
+	s := ws.orNew().vwap.get(period, func() *RollingVWAPStream { return NewRollingVWAP(period) })
+	dst = resize(dst, len(highs))
+	for i := range dst {
+		dst[i], _ = s.Update(highs[i], lows[i], closes[i], volumes[i])
+	}
+	return dst, nil
+}
+
+// KVOInto is like KVOChecked but writes into the series of dst, grown as
+// needed.
+func KVOInto(ws *Workspace, dst *KVOResult, high, low, close, volume []float64) error {
+	return KVOMAInto(ws, dst, high, low, close, volume, MAEMA)
+}
+
+// KVOMAInto is like KVOMAChecked but writes into the series of dst, grown as
+// needed.
+func KVOMAInto(ws *Workspace, dst *KVOResult, high, low, close, volume []float64, t MAType) error {
+	if err := firstError(
+		checkLengths("KVO", len(close), len(high), len(low), len(volume)),
+		checkMA("KVO", "signal", 13, t),
+		checkData("KVO", len(close), KVOMALookback(t)),
+	); err != nil {
+		return err
+	}
+	s := ws.orNew().kvo.get(t, func() *KVOStream { return NewKVOMA(t) })
+	dst.KVO = resize(dst.KVO, len(close))
+	dst.KVOSignal = resize(dst.KVOSignal, len(close))
+	for i := range close {
+		dst.KVO[i], dst.KVOSignal[i], _ = s.Update(high[i], low[i], close[i], volume[i])
+	}
+	return nil
+}
+
+// supertrendKey holds the parameters of a SupertrendStream. The multiplier
+// is keyed by its bits, so that a NaN finds its stream again.
+type supertrendKey struct {
+	length     int
+	multiplier uint64
+	method     ATRMethod
+}
+
+// SupertrendInto is like SupertrendChecked but writes into the series of
+// dst, grown as needed.
+func SupertrendInto(ws *Workspace, dst *SupertrendResult, high, low, close []float64, length int, multiplier float64) error {
+	if err := firstError(
+		checkLengths("Supertrend", len(close), len(high), len(low)),
+		checkPeriod("Supertrend", "length", length, 1),
+		checkData("Supertrend", len(close), SupertrendLookback(length)),
+	); err != nil {
+		return err
+	}
+	supertrendInto(ws, dst, nil, high, low, close, length, multiplier, ATRWilder)
+	return nil
+}
+
+// SupertrendWithInto is like SupertrendWithChecked but writes into the
+// series of dst, grown as needed.
+func SupertrendWithInto(ws *Workspace, dst *SupertrendResult, source, high, low, close []float64, length int, multiplier float64, method ATRMethod) error {
+	if method < ATRWilder || method > ATRExponential {
+		return &InputError{Func: "Supertrend", Err: ErrInvalidParam, Detail: fmt.Sprintf("ATR method %v", method)}
+	}
+	if err := firstError(
+		checkLengths("Supertrend", len(close), len(high), len(low), len(source)),
+		checkPeriod("Supertrend", "length", length, 1),
+		checkData("Supertrend", len(close), SupertrendWithLookback(length, method)),
+	); err != nil {
+		return err
+	}
+	supertrendInto(ws, dst, source, high, low, close, length, multiplier, method)
+	return nil
+}
+
+// supertrendInto runs the Supertrend stream for the parameters over the bars,
+// with bands around source or, if it is nil, around hl2.
+func supertrendInto(ws *Workspace, dst *SupertrendResult, source, high, low, close []float64, length int, multiplier float64, method ATRMethod) {
+	key := supertrendKey{length, math.Float64bits(multiplier), method}
+	s := ws.orNew().supertrend.get(key, func() *SupertrendStream {
+		return NewSupertrendWith(SourceHL2, length, multiplier, method)
+	})
+	n := len(close)
+	dst.Trend = resize(dst.Trend, n)
+	dst.Direction = resize(dst.Direction, n)
+	dst.Long = resize(dst.Long, n)
+	dst.Short = resize(dst.Short, n)
+	dst.Distance = resize(dst.Distance, n)
+	dst.Flips = dst.Flips[:0]
+	for i := range close {
+		src := (high[i] + low[i]) / 2.0
+		if source != nil {
+			src = source[i]
+		}
+		p, _ := s.updateSource(src, high[i], low[i], close[i])
+		dst.Trend[i], dst.Direction[i] = p.Trend, p.Direction
+		dst.Long[i], dst.Short[i], dst.Distance[i] = p.Long, p.Short, p.Distance
+		if p.Flip {
+			dst.Flips = append(dst.Flips, i)
+		}
+	}
+}
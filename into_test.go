@@ -0,0 +1,194 @@
+package indicators
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIntoMatchesBatch(t *testing.T) {
+	_, high, low, close, volume := testOHLCV(300)
+	ws := new(Workspace)
+	var dst []float64
+	var err error
+
+	dst, err = SMAInto(ws, dst, close, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "sma", dst, SMA(close, 20), 1e-9)
+
+	dst, err = EMAInto(ws, dst, close, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "ema", dst, EMA(close, 12), 1e-9)
+
+	for _, typ := range []MAType{MAWMA, MAT3, MAVWMA} {
+		dst, err = MAVolumeInto(ws, dst, close, volume, 14, typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertClose(t, typ.String(), dst, MAVolume(close, volume, 14, typ), 0)
+	}
+	dst, err = MAInto(ws, dst, close, 14, MAVWMA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "vwma without volume", dst, MA(close, 14, MAVWMA), 0)
+
+	dst, err = RollingStdInto(ws, dst, close, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "std", dst, RollingStd(close, 20), 1e-9)
+
+	dst, err = ZScoreInto(ws, dst, close, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "zscore", dst, ZScore(close, 20), 1e-9)
+
+	dst, err = CMFInto(ws, dst, high, low, close, volume, 21)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "cmf", dst, CMF(high, low, close, volume, 21), 1e-9)
+
+	dst, err = RollingVWAPInto(ws, dst, high, low, close, volume, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "vwap", dst, RollingVWAP(high, low, close, volume, 20), 1e-9)
+
+	var kvo KVOResult
+	if err := KVOInto(ws, &kvo, high, low, close, volume); err != nil {
+		t.Fatal(err)
+	}
+	want := KVO(high, low, close, volume)
+	assertClose(t, "kvo", kvo.KVO, want.KVO, 1e-9)
+	assertClose(t, "kvo signal", kvo.KVOSignal, want.KVOSignal, 1e-9)
+
+	var st SupertrendResult
+	if err := SupertrendInto(ws, &st, high, low, close, 10, 3); err != nil {
+		t.Fatal(err)
+	}
+	wantST := Supertrend(high, low, close, 10, 3)
+	assertClose(t, "supertrend", st.Trend, wantST.Trend, 1e-9)
+	if len(st.Flips) != len(wantST.Flips) {
+		t.Errorf("flips %v, want %v", st.Flips, wantST.Flips)
+	}
+
+	if err := SupertrendWithInto(ws, &st, close, high, low, close, 10, 2, ATRExponential); err != nil {
+		t.Fatal(err)
+	}
+	wantST = SupertrendWith(close, high, low, close, 10, 2, ATRExponential)
+	assertClose(t, "supertrend with", st.Trend, wantST.Trend, 1e-9)
+	if len(st.Flips) != len(wantST.Flips) {
+		t.Errorf("flips %v, want %v", st.Flips, wantST.Flips)
+	}
+}
+
+func TestIntoErrors(t *testing.T) {
+	_, high, low, close, volume := testOHLCV(50)
+	if _, err := MAInto(nil, nil, close, 3, MAHMA); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("HMA of 3: %v", err)
+	}
+	if _, err := MAVolumeInto(nil, nil, close, volume[1:], 3, MAVWMA); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("short volume: %v", err)
+	}
+	if err := SupertrendWithInto(nil, new(SupertrendResult), close, high, low, close, 7, 3, ATRMethod(99)); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("unknown method: %v", err)
+	}
+}
+
+func TestIntoRecomputesFromScratch(t *testing.T) {
+	_, _, _, close, _ := testOHLCV(200)
+	ws := new(Workspace)
+	first, _ := SMAInto(ws, nil, close[100:], 10)
+	first = append([]float64(nil), first...)
+	SMAInto(ws, nil, close[:100], 10)
+	again, _ := SMAInto(ws, nil, close[100:], 10)
+	assertClose(t, "sma", again, first, 0)
+}
+
+func TestIntoNilWorkspace(t *testing.T) {
+	_, _, _, close, _ := testOHLCV(50)
+	got, err := SMAInto(nil, nil, close, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "sma", got, SMA(close, 5), 1e-9)
+}
+
+func TestIntoAllocs(t *testing.T) {
+	_, high, low, close, volume := testOHLCV(1000)
+	ws := new(Workspace)
+	dst := make([]float64, len(close))
+	var kvo KVOResult
+	var st SupertrendResult
+	run := func(period int) {
+		dst, _ = SMAInto(ws, dst, close, period)
+		dst, _ = EMAInto(ws, dst, close, int32(period))
+		dst, _ = MAVolumeInto(ws, dst, close, volume, period, MAT3)
+		dst, _ = RollingStdInto(ws, dst, close, period)
+		dst, _ = ZScoreInto(ws, dst, close, period)
+		dst, _ = CMFInto(ws, dst, high, low, close, volume, period)
+		dst, _ = RollingVWAPInto(ws, dst, high, low, close, volume, period)
+		KVOInto(ws, &kvo, high, low, close, volume)
+		SupertrendInto(ws, &st, high, low, close, period, 3)
+		SupertrendWithInto(ws, &st, close, high, low, close, period, 3, ATRSimple)
+	}
+	// Grow dst and the results, and build the streams of both periods.
+	run(10)
+	run(20)
+
+	if allocs := testing.AllocsPerRun(20, func() { run(10) }); allocs != 0 {
+		t.Errorf("%v allocations per run with the same parameters, want 0", allocs)
+	}
+	period := 10
+	alternate := func() {
+		run(period)
+		period = 30 - period
+	}
+	if allocs := testing.AllocsPerRun(20, alternate); allocs != 0 {
+		t.Errorf("%v allocations per run with alternating parameters, want 0", allocs)
+	}
+}
+
+func BenchmarkSMAInto(b *testing.B) {
+	_, _, _, close, _ := testOHLCV(10000)
+	ws := new(Workspace)
+	dst := make([]float64, len(close))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dst, _ = SMAInto(ws, dst, close, 50)
+	}
+}
+
+func BenchmarkSMAChecked(b *testing.B) {
+	_, _, _, close, _ := testOHLCV(10000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SMAChecked(close, 50)
+	}
+}
+
+func BenchmarkCMFInto(b *testing.B) {
+	_, high, low, close, volume := testOHLCV(10000)
+	ws := new(Workspace)
+	dst := make([]float64, len(close))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dst, _ = CMFInto(ws, dst, high, low, close, volume, 21)
+	}
+}
+
+func BenchmarkSupertrendInto(b *testing.B) {
+	_, high, low, close, _ := testOHLCV(10000)
+	ws := new(Workspace)
+	var dst SupertrendResult
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SupertrendInto(ws, &dst, high, low, close, 10, 3)
+	}
+}
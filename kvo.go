@@ -1,5 +1,7 @@
 package indicators
 
+import "math"
+
 // KVOResult holds the calculated KVO and KVO signal values.
 type KVOResult struct {
 	KVO       []float64
@@ -8,51 +10,145 @@ type KVOResult struct {
 
 // KVO calculates the Klinger Volume Oscillator (KVO) and its signal line.
 func KVO(high, low, close, volume []float64) KVOResult {
+	return KVOMA(high, low, close, volume, MAEMA)
+}
+
+// KVOMA is like KVO with the 34 and 55 bar averages of the volume trend and
+// the 13 bar signal line of type t. KVO is NaN until both averages have left
+// their warm-up, and the signal line until its own average has.
+func KVOMA(high, low, close, volume []float64, t MAType) KVOResult {
 	length := len(close)
 	if len(high) != length || len(low) != length || len(volume) != length {
 		panic("Input slices must have the same length")
 	}
 
-	hlc3 := make([]float64, length)
-	hlc3Diff := make([]float64, length)
-	xtrend := make([]float64, length)
 	kvo := make([]float64, length)
-
-	// Calculate HLC3 (High + Low + Close) / 3
+	kvoSignal := make([]float64, length)
+	s := NewKVOMA(t)
 	for i := 0; i < length; i++ {
-		hlc3[i] = (high[i] + low[i] + close[i]) / 3
+		kvo[i], kvoSignal[i], _ = s.Update(high[i], low[i], close[i], volume[i])
 	}
 
-	// Calculate HLC3 difference
-	for i := 1; i < length; i++ {
-		hlc3Diff[i] = hlc3[i] - hlc3[i-1]
+	return KVOResult{
+		KVO:       kvo,
+		KVOSignal: kvoSignal,
 	}
+}
 
-	// Calculate xtrend
-	for i := 0; i < length; i++ {
-		if hlc3Diff[i] > 0 {
-			xtrend[i] = volume[i] * 100
-		} else {
-			xtrend[i] = -volume[i] * 100
-		}
+// KVOChecked is like KVO but reports invalid input as an error instead of
+// panicking.
+func KVOChecked(high, low, close, volume []float64) (KVOResult, error) {
+	if err := firstError(
+		checkLengths("KVO", len(close), len(high), len(low), len(volume)),
+		checkData("KVO", len(close), KVOLookback()),
+	); err != nil {
+		return KVOResult{}, err
+	}
+	return KVO(high, low, close, volume), nil
+}
+
+// KVOMAChecked is like KVOMA but reports invalid input as an error instead
+// of panicking.
+func KVOMAChecked(high, low, close, volume []float64, t MAType) (KVOResult, error) {
+	if err := firstError(
+		checkLengths("KVO", len(close), len(high), len(low), len(volume)),
+		checkMA("KVO", "signal", 13, t),
+		checkData("KVO", len(close), KVOMALookback(t)),
+	); err != nil {
+		return KVOResult{}, err
 	}
+	return KVOMA(high, low, close, volume, t), nil
+}
 
-	// Calculate xfast (EMA of xtrend with fastSpan)
-	xfast := EMA(xtrend, 34)
+// KVOBars calculates KVO over b.
+func KVOBars(b *Bars) (KVOResult, error) {
+	return KVOChecked(b.high, b.low, b.close, b.volume)
+}
 
-	// Calculate xslow (EMA of xtrend with slowSpan)
-	xslow := EMA(xtrend, 55)
+// KVOMABars calculates KVOMA over b.
+func KVOMABars(b *Bars, t MAType) (KVOResult, error) {
+	return KVOMAChecked(b.high, b.low, b.close, b.volume, t)
+}
 
-	// Calculate KVO (xfast - xslow)
-	for i := 0; i < length; i++ {
-		kvo[i] = xfast[i] - xslow[i]
+// Columns returns KVO and its signal line as named columns.
+func (r KVOResult) Columns() []Column {
+	return []Column{
+		{Name: "kvo", Values: r.KVO},
+		{Name: "kvo_signal", Values: r.KVOSignal},
 	}
+}
 
-	// Calculate KVO signal line (EMA of KVO with signalSpan)
-	kvoSignal := EMA(kvo, 13)
+// KVOLookback returns the number of leading warm-up values of KVO.
+func KVOLookback() int {
+	return KVOMALookback(MAEMA)
+}
 
-	return KVOResult{
-		KVO:       kvo,
-		KVOSignal: kvoSignal,
+// KVOMALookback returns the number of leading warm-up values of the KVOMA
+// signal line.
+func KVOMALookback(t MAType) int {
+	return max(MALookback(34, t), MALookback(55, t)) + MALookback(13, t)
+}
+
+// KVOStream is the streaming counterpart of KVO.
+type KVOStream struct {
+	xfast    *MAStream
+	xslow    *MAStream
+	signal   *MAStream
+	prevHLC3 float64
+	seen     bool
+}
+
+// NewKVO returns a streaming Klinger Volume Oscillator.
+func NewKVO() *KVOStream {
+	return NewKVOMA(MAEMA)
+}
+
+// NewKVOMA returns a streaming Klinger Volume Oscillator with averages of
+// type t.
+func NewKVOMA(t MAType) *KVOStream {
+	return &KVOStream{xfast: NewMA(t, 34), xslow: NewMA(t, 55), signal: NewMA(t, 13)}
+}
+
+// Update adds a bar and returns the current KVO and signal line values.
+func (s *KVOStream) Update(high, low, close, volume float64) (kvo, kvoSignal float64, ready bool) {
+	hlc3 := (high + low + close) / 3
+
+	var hlc3Diff float64
+	if s.seen {
+		hlc3Diff = hlc3 - s.prevHLC3
 	}
+	s.prevHLC3 = hlc3
+	s.seen = true
+
+	xtrend := -volume * 100
+	if hlc3Diff > 0 {
+		xtrend = volume * 100
+	}
+
+	xfast, fastReady := s.xfast.Update(xtrend)
+	xslow, slowReady := s.xslow.Update(xtrend)
+	if !fastReady || !slowReady {
+		return math.NaN(), math.NaN(), false
+	}
+	kvo = xfast - xslow
+	kvoSignal, ready = s.signal.Update(kvo)
+	if !ready {
+		kvoSignal = math.NaN()
+	}
+	return kvo, kvoSignal, ready
 }
+
+// Ready reports whether the signal line has left its warm-up period.
+func (s *KVOStream) Ready() bool { return s.signal.Ready() }
+
+// Reset clears all averages.
+func (s *KVOStream) Reset() {
+	s.xfast.Reset()
+	s.xslow.Reset()
+	s.signal.Reset()
+	s.prevHLC3 = 0
+	s.seen = false
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *KVOStream) Lookback() int { return KVOMALookback(s.signal.Type()) }
@@ -0,0 +1,478 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+)
+
+// MAType selects a moving average of the MA family.
+type MAType int
+
+const (
+	MASMA   MAType = iota // simple, as SMA
+	MAEMA                 // exponential seeded with the first value, as EMA
+	MARMA                 // Wilder's smoothing, seeded with the SMA of the first period values
+	MAWMA                 // linearly weighted
+	MAHMA                 // Hull, as HMA
+	MADEMA                // double exponential, as TA-Lib's DEMA
+	MATEMA                // triple exponential, as TA-Lib's TEMA
+	MAKAMA                // Kaufman adaptive with the 2 and 30 bar bounds of TA-Lib's KAMA
+	MAALMA                // Arnaud Legoux with an offset of 0.85 and a sigma of 6
+	MAT3                  // Tillson T3 with a volume factor of 0.7, as TA-Lib's T3
+	MAVWMA                // volume weighted; equal weights without volume
+	MAZLEMA               // zero lag exponential
+)
+
+// String returns the type name, e.g. "sma".
+func (t MAType) String() string {
+	switch t {
+	case MASMA:
+		return "sma"
+	case MAEMA:
+		return "ema"
+	case MARMA:
+		return "rma"
+	case MAWMA:
+		return "wma"
+	case MAHMA:
+		return "hma"
+	case MADEMA:
+		return "dema"
+	case MATEMA:
+		return "tema"
+	case MAKAMA:
+		return "kama"
+	case MAALMA:
+		return "alma"
+	case MAT3:
+		return "t3"
+	case MAVWMA:
+		return "vwma"
+	case MAZLEMA:
+		return "zlema"
+	}
+	return "unknown"
+}
+
+// ParseMAType returns the MAType named name, as returned by String.
+func ParseMAType(name string) (MAType, bool) {
+	for t := MASMA; t <= MAZLEMA; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// minPeriod returns the smallest period the average is defined for.
+func (t MAType) minPeriod() int {
+	if t == MAHMA {
+		return 4
+	}
+	return 1
+}
+
+// checkMA reports ErrInvalidParam for unknown types and ErrInvalidPeriod
+// for periods the average is not defined for.
+func checkMA(fn, name string, period int, t MAType) error {
+	if t < MASMA || t > MAZLEMA {
+		return &InputError{Func: fn, Err: ErrInvalidParam, Detail: fmt.Sprintf("unknown MAType %d", int(t))}
+	}
+	return checkPeriod(fn, name, period, t.minPeriod())
+}
+
+// MA calculates the moving average of type t over data. Values during
+// warm-up are NaN, as for SMA, and so is every value for periods the average
+// is not defined for, such as HMA below 4. VWMA weighs the values equally;
+// use MAVolume to weigh them by volume.
+func MA(data []float64, period int, t MAType) []float64 {
+	return MAVolume(data, nil, period, t)
+}
+
+// MAVolume is like MA but weighs the values of VWMA by volume, which is
+// ignored by the other types. A nil volume weighs the values equally. It
+// returns nil if the lengths differ.
+func MAVolume(data, volume []float64, period int, t MAType) []float64 {
+	if volume != nil && len(volume) != len(data) {
+		return nil
+	}
+	result := make([]float64, len(data))
+	ma := NewMA(t, period)
+	for i, v := range data {
+		w := 1.0
+		if volume != nil {
+			w = volume[i]
+		}
+		result[i], _ = ma.UpdateVolume(v, w)
+	}
+	return result
+}
+
+// MAChecked is like MA but reports invalid input as an error.
+func MAChecked(data []float64, period int, t MAType) ([]float64, error) {
+	if err := firstError(
+		checkMA("MA", "period", period, t),
+		checkData("MA", len(data), MALookback(period, t)),
+	); err != nil {
+		return nil, err
+	}
+	return MA(data, period, t), nil
+}
+
+// MAVolumeChecked is like MAVolume but reports invalid input as an error.
+func MAVolumeChecked(data, volume []float64, period int, t MAType) ([]float64, error) {
+	if volume != nil {
+		if err := checkLengths("MA", len(data), len(volume)); err != nil {
+			return nil, err
+		}
+	}
+	if err := firstError(
+		checkMA("MA", "period", period, t),
+		checkData("MA", len(data), MALookback(period, t)),
+	); err != nil {
+		return nil, err
+	}
+	return MAVolume(data, volume, period, t), nil
+}
+
+// MABars calculates MAVolume of the source price of b, weighted by its
+// volume.
+func MABars(b *Bars, source PriceSource, period int, t MAType) ([]float64, error) {
+	price, err := source.Series(b)
+	if err != nil {
+		return nil, err
+	}
+	return MAVolumeChecked(price, b.volume, period, t)
+}
+
+// MALookback returns the number of leading warm-up values of MA.
+func MALookback(period int, t MAType) int {
+	switch t {
+	case MAEMA:
+		return 0
+	case MAHMA:
+		return HMALookback(period)
+	case MADEMA:
+		return 2 * (period - 1)
+	case MATEMA:
+		return 3 * (period - 1)
+	case MAKAMA:
+		return period
+	case MAT3:
+		return 6 * (period - 1)
+	case MAZLEMA:
+		return (period - 1) / 2
+	}
+	return period - 1
+}
+
+// MAStream is the streaming counterpart of MA.
+type MAStream struct {
+	typ      MAType
+	lookback int
+	kernel   maKernel
+	ready    bool
+}
+
+// NewMA returns a streaming moving average of type t over period values.
+// Unknown types fall back to MASMA. If the average is not defined for
+// period, such as HMA below 4, every value is NaN, as for MA.
+func NewMA(t MAType, period int) *MAStream {
+	if t < MASMA || t > MAZLEMA {
+		t = MASMA
+	}
+	if period < t.minPeriod() {
+		return &MAStream{typ: t, kernel: undefinedKernel{}}
+	}
+	return &MAStream{typ: t, lookback: MALookback(period, t), kernel: newMAKernel(t, period)}
+}
+
+// Update adds v and returns the current average, or NaN during warm-up.
+func (s *MAStream) Update(v float64) (float64, bool) {
+	return s.UpdateVolume(v, 1)
+}
+
+// UpdateVolume is like Update but weighs v by volume if the average is a
+// VWMA.
+func (s *MAStream) UpdateVolume(v, volume float64) (float64, bool) {
+	out, ok := s.kernel.update(v, volume)
+	s.ready = ok
+	if !ok {
+		return math.NaN(), false
+	}
+	return out, true
+}
+
+// Type returns the type of the average.
+func (s *MAStream) Type() MAType { return s.typ }
+
+// Ready reports whether the average has left its warm-up period.
+func (s *MAStream) Ready() bool { return s.ready }
+
+// Reset clears the average.
+func (s *MAStream) Reset() {
+	s.kernel.reset()
+	s.ready = false
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *MAStream) Lookback() int { return s.lookback }
+
+// maKernel is one average of the MA family. Values during warm-up are
+// undefined and reported as not ready.
+type maKernel interface {
+	update(v, volume float64) (float64, bool)
+	reset()
+}
+
+// undefinedKernel is an average for a period it is not defined for, which
+// never leaves its warm-up.
+type undefinedKernel struct{}
+
+func (undefinedKernel) update(float64, float64) (float64, bool) { return 0, false }
+func (undefinedKernel) reset()                                  {}
+
+func newMAKernel(t MAType, period int) maKernel {
+	switch t {
+	case MAEMA:
+		return emaKernel{NewEMA(int32(period))}
+	case MARMA:
+		return &rmaKernel{period: period}
+	case MAWMA:
+		if period == 1 {
+			return smaKernel{NewSMA(1)}
+		}
+		return wmaKernel{newTalibWMA(period)}
+	case MAHMA:
+		return hmaKernel{NewHMA(period)}
+	case MADEMA, MATEMA, MAT3:
+		return newEMACascade(t, period)
+	case MAKAMA:
+		return &kamaKernel{prices: newRing(period + 1), noise: newRollingSum(period)}
+	case MAALMA:
+		return newALMAKernel(period, 0.85, 6)
+	case MAVWMA:
+		return &vwmaKernel{pv: newRollingSum(period), volume: newRollingSum(period)}
+	case MAZLEMA:
+		lag := (period - 1) / 2
+		return &zlemaKernel{prices: newRing(lag + 1), ema: NewEMA(int32(period))}
+	}
+	return smaKernel{NewSMA(period)}
+}
+
+type smaKernel struct{ *SMAStream }
+
+func (k smaKernel) update(v, _ float64) (float64, bool) { return k.Update(v) }
+func (k smaKernel) reset()                              { k.Reset() }
+
+type emaKernel struct{ *EMAStream }
+
+func (k emaKernel) update(v, _ float64) (float64, bool) { return k.Update(v) }
+func (k emaKernel) reset()                              { k.Reset() }
+
+type wmaKernel struct{ *talibWMA }
+
+func (k wmaKernel) update(v, _ float64) (float64, bool) { return k.talibWMA.update(v) }
+func (k wmaKernel) reset()                              { k.talibWMA.reset() }
+
+type hmaKernel struct{ *HMAStream }
+
+func (k hmaKernel) update(v, _ float64) (float64, bool) { return k.Update(v) }
+func (k hmaKernel) reset()                              { k.Reset() }
+
+// rmaKernel is Wilder's smoothing with a factor of 1/period, seeded with the
+// SMA of the first period values.
+type rmaKernel struct {
+	period int
+	value  float64
+	seen   int
+}
+
+func (k *rmaKernel) update(v, _ float64) (float64, bool) {
+	k.seen++
+	switch {
+	case k.seen < k.period:
+		k.value += v
+		return 0, false
+	case k.seen == k.period:
+		k.value = (k.value + v) / float64(k.period)
+	default:
+		k.value += (v - k.value) / float64(k.period)
+	}
+	return k.value, true
+}
+
+func (k *rmaKernel) reset() { k.value, k.seen = 0, 0 }
+
+// emaCascade feeds each TA-Lib EMA stage with the output of the previous
+// one once it has left its warm-up, and combines the stages as DEMA, TEMA or
+// T3.
+type emaCascade struct {
+	typ    MAType
+	stages []*talibEMA
+	out    []float64
+}
+
+func newEMACascade(t MAType, period int) *emaCascade {
+	stages := 6
+	switch t {
+	case MADEMA:
+		stages = 2
+	case MATEMA:
+		stages = 3
+	}
+	c := &emaCascade{typ: t, stages: make([]*talibEMA, stages), out: make([]float64, stages)}
+	for i := range c.stages {
+		c.stages[i] = newTalibEMA(period)
+	}
+	return c
+}
+
+func (c *emaCascade) update(v, _ float64) (float64, bool) {
+	for i, e := range c.stages {
+		var ok bool
+		if v, ok = e.update(v); !ok {
+			return 0, false
+		}
+		c.out[i] = v
+	}
+	e := c.out
+	switch c.typ {
+	case MADEMA:
+		return 2*e[0] - e[1], true
+	case MATEMA:
+		return 3*e[0] - 3*e[1] + e[2], true
+	}
+	const a = 0.7
+	c1 := -a * a * a
+	c2 := 3*a*a + 3*a*a*a
+	c3 := -6*a*a - 3*a - 3*a*a*a
+	c4 := 1 + 3*a + a*a*a + 3*a*a
+	return c1*e[5] + c2*e[4] + c3*e[3] + c4*e[2], true
+}
+
+func (c *emaCascade) reset() {
+	for _, e := range c.stages {
+		e.reset()
+	}
+}
+
+// kamaKernel reproduces TA_KAMA: the smoothing constant moves between those
+// of a 2 and a 30 bar EMA with the efficiency ratio of the last period
+// changes, and the average is seeded with the previous value.
+type kamaKernel struct {
+	prices *ring // the last period+1 values
+	noise  *rollingSum
+	value  float64
+	seeded bool
+}
+
+func (k *kamaKernel) update(v, _ float64) (float64, bool) {
+	const (
+		slow = 2.0 / (30 + 1)
+		fast = 2.0 / (2 + 1)
+	)
+	var prev float64
+	if k.prices.count > 0 {
+		prev = k.prices.at(k.prices.count - 1)
+		k.noise.push(math.Abs(v - prev))
+	}
+	k.prices.push(v)
+	if !k.prices.full() {
+		return 0, false
+	}
+	if !k.seeded {
+		k.value, k.seeded = prev, true
+	}
+	change := math.Abs(v - k.prices.at(0))
+	er := 1.0
+	if noise := k.noise.value(); noise > change && noise != 0 {
+		er = change / noise
+	}
+	sc := er*(fast-slow) + slow
+	k.value += sc * sc * (v - k.value)
+	return k.value, true
+}
+
+func (k *kamaKernel) reset() {
+	k.prices.reset()
+	k.noise.reset()
+	k.value, k.seeded = 0, false
+}
+
+// almaKernel applies Gaussian weights centred at offset of the window,
+// with a width of period/sigma.
+type almaKernel struct {
+	window  *ring
+	weights []float64 // oldest first, normalised to sum to 1
+}
+
+func newALMAKernel(period int, offset, sigma float64) *almaKernel {
+	m := offset * float64(period-1)
+	s := float64(period) / sigma
+	weights := make([]float64, period)
+	var total float64
+	for i := range weights {
+		d := float64(i) - m
+		weights[i] = math.Exp(-d * d / (2 * s * s))
+		total += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+	return &almaKernel{window: newRing(period), weights: weights}
+}
+
+func (k *almaKernel) update(v, _ float64) (float64, bool) {
+	k.window.push(v)
+	if !k.window.full() {
+		return 0, false
+	}
+	var sum float64
+	for i, w := range k.weights {
+		sum += w * k.window.at(i)
+	}
+	return sum, true
+}
+
+func (k *almaKernel) reset() { k.window.reset() }
+
+// vwmaKernel divides the rolling sum of price times volume by the rolling
+// volume.
+type vwmaKernel struct {
+	pv, volume *rollingSum
+}
+
+func (k *vwmaKernel) update(v, volume float64) (float64, bool) {
+	k.pv.push(v * volume)
+	k.volume.push(volume)
+	if !k.volume.full() {
+		return 0, false
+	}
+	return k.pv.value() / k.volume.value(), true
+}
+
+func (k *vwmaKernel) reset() {
+	k.pv.reset()
+	k.volume.reset()
+}
+
+// zlemaKernel is the EMA of the value plus its change over (period-1)/2
+// bars, which offsets the lag of the EMA.
+type zlemaKernel struct {
+	prices *ring // the last lag+1 values
+	ema    *EMAStream
+}
+
+func (k *zlemaKernel) update(v, _ float64) (float64, bool) {
+	k.prices.push(v)
+	if !k.prices.full() {
+		return 0, false
+	}
+	return k.ema.Update(2*v - k.prices.at(0))
+}
+
+func (k *zlemaKernel) reset() {
+	k.prices.reset()
+	k.ema.Reset()
+}
@@ -0,0 +1,133 @@
+package indicators
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMAUndefinedPeriodIsNaN(t *testing.T) {
+	_, _, _, close, _ := testOHLCV(50)
+	for _, c := range []struct {
+		typ    MAType
+		period int
+	}{{MASMA, 0}, {MAEMA, -1}, {MAHMA, 3}, {MAHMA, 1}} {
+		for i, v := range MA(close, c.period, c.typ) {
+			if !math.IsNaN(v) {
+				t.Fatalf("MA(%v, %d)[%d] = %v, want NaN", c.typ, c.period, i, v)
+			}
+		}
+		s := NewMA(c.typ, c.period)
+		for _, v := range close {
+			if got, ready := s.Update(v); ready || !math.IsNaN(got) {
+				t.Fatalf("NewMA(%v, %d).Update = %v, %v; want NaN, false", c.typ, c.period, got, ready)
+			}
+		}
+		if _, err := MAChecked(close, c.period, c.typ); !errors.Is(err, ErrInvalidPeriod) {
+			t.Errorf("MAChecked(%v, %d): err = %v, want ErrInvalidPeriod", c.typ, c.period, err)
+		}
+	}
+	if _, err := MAChecked(close, 10, MAType(-1)); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("unknown type: err = %v, want ErrInvalidParam", err)
+	}
+}
+
+func TestMAVolumeChecked(t *testing.T) {
+	_, _, _, close, volume := testOHLCV(50)
+	if MAVolume(close, volume[1:], 10, MAVWMA) != nil {
+		t.Error("MAVolume with short volume is not nil")
+	}
+	if _, err := MAVolumeChecked(close, volume[1:], 10, MAVWMA); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("short volume: err = %v, want ErrLengthMismatch", err)
+	}
+	got, err := MAVolumeChecked(close, volume, 10, MAVWMA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 9; i < len(close); i++ {
+		var pv, v float64
+		for j := i - 9; j <= i; j++ {
+			pv += close[j] * volume[j]
+			v += volume[j]
+		}
+		if math.Abs(got[i]-pv/v) > 1e-9 {
+			t.Fatalf("VWMA[%d] = %v, want %v", i, got[i], pv/v)
+		}
+	}
+}
+
+func TestMAStreamMatchesBatch(t *testing.T) {
+	_, _, _, close, volume := testOHLCV(200)
+	for typ := MASMA; typ <= MAZLEMA; typ++ {
+		want := MAVolume(close, volume, 9, typ)
+		s := NewMA(typ, 9)
+		for i := range close {
+			got, ready := s.UpdateVolume(close[i], volume[i])
+			if ready != (i >= MALookback(9, typ)) {
+				t.Fatalf("%v: ready = %v at %d, lookback %d", typ, ready, i, MALookback(9, typ))
+			}
+			if ready && got != want[i] {
+				t.Fatalf("%v[%d]: stream %v, batch %v", typ, i, got, want[i])
+			}
+		}
+	}
+}
+
+func TestMAVariants(t *testing.T) {
+	_, _, _, close, volume := testOHLCV(200)
+
+	// The default types reproduce the original indicators.
+	assertClose(t, "ForceIndexMA", ForceIndexMA(close, volume, 13, MAEMA), ForceIndex(close, volume, 13), 0)
+	assertClose(t, "ElderBullMA", ElderBullMA(close, MAEMA), ElderBull(close), 0)
+	assertClose(t, "BbandsPercentMA", BbandsPercentMA(close, MASMA), BbandsPercent(close), 0)
+
+	sma13 := SMA(close, 13)
+	bear := ElderBearMA(close, MASMA)
+	for i := 12; i < len(close); i++ {
+		if math.Abs(bear[i]-(close[i]-sma13[i])) > 1e-9 {
+			t.Fatalf("ElderBearMA(sma)[%d] = %v, want %v", i, bear[i], close[i]-sma13[i])
+		}
+	}
+
+	// A WMA middle band with population deviations of the window.
+	pb := BbandsPercentMA(close, MAWMA)
+	wma := MA(close, 20, MAWMA)
+	for i := BbandsPercentMALookback(MAWMA); i < len(close); i++ {
+		var mean, m2 float64
+		for j := i - 19; j <= i; j++ {
+			mean += close[j] / 20
+		}
+		for j := i - 19; j <= i; j++ {
+			m2 += (close[j] - mean) * (close[j] - mean)
+		}
+		sd := math.Sqrt(m2 / 20)
+		want := (close[i] - (wma[i] - 2*sd)) / (4 * sd) * 100
+		if math.Abs(pb[i]-want) > 1e-6 {
+			t.Fatalf("BbandsPercentMA(wma)[%d] = %v, want %v", i, pb[i], want)
+		}
+	}
+
+	macd, signal, hist := VolumeWeightedMACDMA(close, volume, 12, 26, 9, MASMA)
+	vw := make([]float64, len(close))
+	for i := range vw {
+		vw[i] = close[i] * volume[i]
+	}
+	fast, slow := SMA(vw, 12), SMA(vw, 26)
+	lb := VolumeWeightedMACDMALookback(12, 26, 9, MASMA)
+	for i := lb; i < len(close); i++ {
+		var sig float64
+		for j := i - 8; j <= i; j++ {
+			sig += (fast[j] - slow[j]) / 9
+		}
+		if math.Abs(macd[i]-(fast[i]-slow[i])) > 1e-6 || math.Abs(signal[i]-sig) > 1e-6 || math.Abs(hist[i]-(macd[i]-signal[i])) > 1e-9 {
+			t.Fatalf("VolumeWeightedMACDMA(sma)[%d] = %v, %v, %v", i, macd[i], signal[i], hist[i])
+		}
+	}
+
+	if _, err := ForceIndexMAChecked(close, volume, 3, MAHMA); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("ForceIndexMAChecked(hma, 3): err = %v, want ErrInvalidPeriod", err)
+	}
+	if _, _, _, err := VolumeWeightedMACDMAChecked(close, volume[1:], 12, 26, 9, MAWMA); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("VolumeWeightedMACDMAChecked: err = %v, want ErrLengthMismatch", err)
+	}
+}
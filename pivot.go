@@ -17,3 +17,67 @@ func Pivot(high, low, close []float64) (pivot, s1, r1, s2, r2 []float64) {
 	}
 	return
 }
+
+// PivotChecked is like Pivot but reports invalid input as an error.
+func PivotChecked(high, low, close []float64) (pivot, s1, r1, s2, r2 []float64, err error) {
+	if err = firstError(
+		checkLengths("Pivot", len(high), len(low), len(close)),
+		checkData("Pivot", len(high), PivotLookback()),
+	); err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	pivot, s1, r1, s2, r2 = Pivot(high, low, close)
+	return pivot, s1, r1, s2, r2, nil
+}
+
+// PivotBars calculates Pivot over b.
+func PivotBars(b *Bars) (pivot, s1, r1, s2, r2 []float64, err error) {
+	return PivotChecked(b.high, b.low, b.close)
+}
+
+// PivotColumns returns the results of Pivot as named columns.
+func PivotColumns(pivot, s1, r1, s2, r2 []float64) []Column {
+	return []Column{
+		{Name: "pivot", Values: pivot},
+		{Name: "s1", Values: s1},
+		{Name: "r1", Values: r1},
+		{Name: "s2", Values: s2},
+		{Name: "r2", Values: r2},
+	}
+}
+
+// PivotLookback returns the number of leading warm-up values of Pivot.
+func PivotLookback() int {
+	return 0
+}
+
+// PivotStream is the streaming counterpart of Pivot. Pivot levels depend
+// only on the current bar, so the stream keeps no history.
+type PivotStream struct {
+	seen bool
+}
+
+// NewPivot returns a streaming pivot point calculator.
+func NewPivot() *PivotStream {
+	return &PivotStream{}
+}
+
+// Update returns the pivot levels for a bar.
+func (s *PivotStream) Update(high, low, close float64) (pivot, s1, r1, s2, r2 float64) {
+	s.seen = true
+	pivot = (high + low + close) / 3
+	s1 = 2*pivot - high
+	r1 = 2*pivot - low
+	s2 = pivot - (high - low)
+	r2 = pivot + (high - low)
+	return
+}
+
+// Ready reports whether a bar has been seen.
+func (s *PivotStream) Ready() bool { return s.seen }
+
+// Reset clears the stream.
+func (s *PivotStream) Reset() { s.seen = false }
+
+// Lookback returns the number of warm-up bars.
+func (s *PivotStream) Lookback() int { return 0 }
@@ -0,0 +1,267 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// PivotMethod selects the formulas of PivotPoints.
+type PivotMethod int
+
+const (
+	// PivotClassic is the floor trader pivot with three levels, as in Pivot.
+	PivotClassic PivotMethod = iota
+	// PivotFibonacci spaces three levels at 0.382, 0.618 and 1 times the
+	// range around the classic pivot.
+	PivotFibonacci
+	// PivotCamarilla places four levels around the close at 1.1/12, 1.1/6,
+	// 1.1/4 and 1.1/2 times the range.
+	PivotCamarilla
+	// PivotWoodie weights the close twice in the pivot and derives three
+	// levels as the classic method does.
+	PivotWoodie
+	// PivotDeMark weights the pivot by the direction of the period and
+	// defines a single support and resistance level.
+	PivotDeMark
+)
+
+// String returns the method name.
+func (m PivotMethod) String() string {
+	switch m {
+	case PivotClassic:
+		return "classic"
+	case PivotFibonacci:
+		return "fibonacci"
+	case PivotCamarilla:
+		return "camarilla"
+	case PivotWoodie:
+		return "woodie"
+	case PivotDeMark:
+		return "demark"
+	}
+	return "unknown"
+}
+
+// PivotPeriod selects the period whose aggregate the pivots are computed
+// from.
+type PivotPeriod int
+
+const (
+	PivotDaily PivotPeriod = iota
+	PivotWeekly
+	PivotMonthly
+)
+
+// String returns the period name.
+func (p PivotPeriod) String() string {
+	switch p {
+	case PivotDaily:
+		return "daily"
+	case PivotWeekly:
+		return "weekly"
+	case PivotMonthly:
+		return "monthly"
+	}
+	return "unknown"
+}
+
+// PivotPointsResult holds the pivot levels of every bar. Levels a method
+// does not define are NaN, as are all levels of the first period and of bars
+// outside the session.
+type PivotPointsResult struct {
+	Pivot          []float64
+	S1, S2, S3, S4 []float64
+	R1, R2, R3, R4 []float64
+}
+
+// PivotPoints computes pivot levels from the high, low, close (and, for
+// DeMark, open) of each day, week or month of b and applies them to every bar
+// of the following period. Days follow session; weeks start on Monday.
+// b must have a Time column in ascending order.
+func PivotPoints(b *Bars, method PivotMethod, period PivotPeriod, session Session) (PivotPointsResult, error) {
+	if !b.HasTime() {
+		return PivotPointsResult{}, &InputError{Func: "PivotPoints", Err: ErrMissingColumn, Detail: "Time"}
+	}
+	if method < PivotClassic || method > PivotDeMark {
+		return PivotPointsResult{}, &InputError{Func: "PivotPoints", Err: ErrInvalidParam, Detail: fmt.Sprintf("method %v", method)}
+	}
+	if period < PivotDaily || period > PivotMonthly {
+		return PivotPointsResult{}, &InputError{Func: "PivotPoints", Err: ErrInvalidPeriod, Detail: fmt.Sprintf("period %v", period)}
+	}
+	if method == PivotDeMark {
+		if err := b.requireOpen("PivotPoints"); err != nil {
+			return PivotPointsResult{}, err
+		}
+	}
+
+	n := b.Len()
+	r := PivotPointsResult{
+		Pivot: make([]float64, n),
+		S1:    make([]float64, n), S2: make([]float64, n), S3: make([]float64, n), S4: make([]float64, n),
+		R1: make([]float64, n), R2: make([]float64, n), R3: make([]float64, n), R4: make([]float64, n),
+	}
+	s := NewPivotPoints(method, period, session)
+	for i, t := range b.time {
+		if i > 0 && t.Before(b.time[i-1]) {
+			return PivotPointsResult{}, &InputError{Func: "PivotPoints", Err: ErrUnsortedTime, Detail: fmt.Sprintf("bar %d", i)}
+		}
+		var open float64
+		if b.open != nil {
+			open = b.open[i]
+		}
+		l, _ := s.Update(t, open, b.high[i], b.low[i], b.close[i])
+		r.Pivot[i] = l.Pivot
+		r.S1[i], r.S2[i], r.S3[i], r.S4[i] = l.S1, l.S2, l.S3, l.S4
+		r.R1[i], r.R2[i], r.R3[i], r.R4[i] = l.R1, l.R2, l.R3, l.R4
+	}
+	return r, nil
+}
+
+// Columns returns the pivot levels as named columns.
+func (r PivotPointsResult) Columns() []Column {
+	return []Column{
+		{Name: "pivot", Values: r.Pivot},
+		{Name: "s1", Values: r.S1},
+		{Name: "s2", Values: r.S2},
+		{Name: "s3", Values: r.S3},
+		{Name: "s4", Values: r.S4},
+		{Name: "r1", Values: r.R1},
+		{Name: "r2", Values: r.R2},
+		{Name: "r3", Values: r.R3},
+		{Name: "r4", Values: r.R4},
+	}
+}
+
+// PivotLevels holds the pivot levels of a single bar.
+type PivotLevels struct {
+	Pivot          float64
+	S1, S2, S3, S4 float64
+	R1, R2, R3, R4 float64
+}
+
+// nanPivotLevels has every level undefined.
+var nanPivotLevels = PivotLevels{
+	Pivot: math.NaN(),
+	S1:    math.NaN(), S2: math.NaN(), S3: math.NaN(), S4: math.NaN(),
+	R1: math.NaN(), R2: math.NaN(), R3: math.NaN(), R4: math.NaN(),
+}
+
+// ComputePivotLevels returns the levels of method from the open, high, low
+// and close of a period.
+func ComputePivotLevels(method PivotMethod, open, high, low, close float64) PivotLevels {
+	l := nanPivotLevels
+	rng := high - low
+	switch method {
+	case PivotFibonacci:
+		l.Pivot = (high + low + close) / 3
+		l.R1, l.S1 = l.Pivot+0.382*rng, l.Pivot-0.382*rng
+		l.R2, l.S2 = l.Pivot+0.618*rng, l.Pivot-0.618*rng
+		l.R3, l.S3 = l.Pivot+rng, l.Pivot-rng
+	case PivotCamarilla:
+		l.Pivot = (high + low + close) / 3
+		l.R1, l.S1 = close+rng*1.1/12, close-rng*1.1/12
+		l.R2, l.S2 = close+rng*1.1/6, close-rng*1.1/6
+		l.R3, l.S3 = close+rng*1.1/4, close-rng*1.1/4
+		l.R4, l.S4 = close+rng*1.1/2, close-rng*1.1/2
+	case PivotDeMark:
+		var x float64
+		switch {
+		case close < open:
+			x = high + 2*low + close
+		case close > open:
+			x = 2*high + low + close
+		default:
+			x = high + low + 2*close
+		}
+		l.Pivot = x / 4
+		l.R1, l.S1 = x/2-low, x/2-high
+	default:
+		if method == PivotWoodie {
+			l.Pivot = (high + low + 2*close) / 4
+		} else {
+			l.Pivot = (high + low + close) / 3
+		}
+		l.R1, l.S1 = 2*l.Pivot-low, 2*l.Pivot-high
+		l.R2, l.S2 = l.Pivot+rng, l.Pivot-rng
+		l.R3, l.S3 = high+2*(l.Pivot-low), low-2*(high-l.Pivot)
+	}
+	return l
+}
+
+// PivotPointsStream is the streaming counterpart of PivotPoints.
+type PivotPointsStream struct {
+	method  PivotMethod
+	period  PivotPeriod
+	session Session
+
+	key                    time.Time // start of the current period
+	started                bool
+	open, high, low, close float64
+	levels                 PivotLevels
+	ready                  bool
+}
+
+// NewPivotPoints returns streaming pivot points of method over the prior
+// period.
+func NewPivotPoints(method PivotMethod, period PivotPeriod, session Session) *PivotPointsStream {
+	if session.Location == nil {
+		session.Location = time.UTC
+	}
+	return &PivotPointsStream{method: method, period: period, session: session, levels: nanPivotLevels}
+}
+
+// Update adds a bar opening at t and returns the levels from the previous
+// period, which are NaN during the first period and outside the session.
+func (s *PivotPointsStream) Update(t time.Time, open, high, low, close float64) (PivotLevels, bool) {
+	key, ok := s.periodStart(t)
+	if !ok {
+		return nanPivotLevels, false
+	}
+	if !s.started || !key.Equal(s.key) {
+		if s.started {
+			s.levels = ComputePivotLevels(s.method, s.open, s.high, s.low, s.close)
+			s.ready = true
+		}
+		s.key, s.started = key, true
+		s.open, s.high, s.low = open, high, low
+	} else {
+		s.high = math.Max(s.high, high)
+		s.low = math.Min(s.low, low)
+	}
+	s.close = close
+	return s.levels, s.ready
+}
+
+// periodStart returns the start of the day, week or month containing t, or
+// false if t is outside the session.
+func (s *PivotPointsStream) periodStart(t time.Time) (time.Time, bool) {
+	const day = 24 * time.Hour
+	switch s.period {
+	case PivotWeekly:
+		start, _, ok := s.session.period(t, 7*day)
+		return start, ok
+	case PivotMonthly:
+		start, _, ok := s.session.period(t, day)
+		if !ok {
+			return time.Time{}, false
+		}
+		y, m, _ := start.Date()
+		return time.Date(y, m, 1, 0, 0, 0, 0, s.session.Location), true
+	}
+	start, _, ok := s.session.period(t, day)
+	return start, ok
+}
+
+// Ready reports whether a full period has completed.
+func (s *PivotPointsStream) Ready() bool { return s.ready }
+
+// Reset clears the stream.
+func (s *PivotPointsStream) Reset() {
+	s.started, s.ready = false, false
+	s.levels = nanPivotLevels
+}
+
+// Lookback returns 0: the warm-up of pivot points is a calendar period
+// rather than a number of bars.
+func (s *PivotPointsStream) Lookback() int { return 0 }
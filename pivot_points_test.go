@@ -0,0 +1,69 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputePivotLevels(t *testing.T) {
+	const open, high, low, close = 95.0, 110.0, 90.0, 105.0
+	p := (high + low + close) / 3
+	for _, c := range []struct {
+		method PivotMethod
+		got    func(PivotLevels) []float64
+		want   []float64
+	}{
+		{PivotClassic, func(l PivotLevels) []float64 { return []float64{l.Pivot, l.R1, l.S1, l.R2, l.S2, l.R3, l.S3} },
+			[]float64{p, 2*p - low, 2*p - high, p + 20, p - 20, high + 2*(p-low), low - 2*(high-p)}},
+		{PivotFibonacci, func(l PivotLevels) []float64 { return []float64{l.Pivot, l.R1, l.S1, l.R2, l.S3} },
+			[]float64{p, p + 7.64, p - 7.64, p + 12.36, p - 20}},
+		{PivotCamarilla, func(l PivotLevels) []float64 { return []float64{l.Pivot, l.R1, l.S2, l.R3, l.R4, l.S4} },
+			[]float64{p, 105 + 22.0/12, 105 - 22.0/6, 105 + 5.5, 116, 94}},
+		{PivotWoodie, func(l PivotLevels) []float64 { return []float64{l.Pivot, l.R1, l.S1} },
+			[]float64{102.5, 115, 95}},
+		// The close is above the open, so the high is weighted twice.
+		{PivotDeMark, func(l PivotLevels) []float64 { return []float64{l.Pivot, l.R1, l.S1, l.R2} },
+			[]float64{103.75, 117.5, 97.5, math.NaN()}},
+	} {
+		assertClose(t, c.method.String(), c.got(ComputePivotLevels(c.method, open, high, low, close)), c.want, 1e-9)
+	}
+}
+
+func TestPivotPointsFromPriorDay(t *testing.T) {
+	b := testBars(24 * 4) // four days of hourly bars
+	r, err := PivotPoints(b, PivotClassic, PivotDaily, Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for day := 0; day < 4; day++ {
+		lo, hi := 24*day, 24*day+24
+		if day == 0 {
+			if !math.IsNaN(r.Pivot[lo]) || !math.IsNaN(r.Pivot[hi-1]) {
+				t.Errorf("pivot defined during the first day")
+			}
+			continue
+		}
+		prev := b.Slice(lo-24, lo)
+		high, low := math.Inf(-1), math.Inf(1)
+		for i := range prev.close {
+			high, low = math.Max(high, prev.high[i]), math.Min(low, prev.low[i])
+		}
+		want := ComputePivotLevels(PivotClassic, prev.open[0], high, low, prev.close[23])
+		for i := lo; i < hi; i++ {
+			if r.Pivot[i] != want.Pivot || r.R1[i] != want.R1 || r.S3[i] != want.S3 {
+				t.Fatalf("bar %d: pivot %v R1 %v S3 %v, want %+v", i, r.Pivot[i], r.R1[i], r.S3[i], want)
+			}
+		}
+	}
+}
+
+func TestPivotPointsDeMarkNeedsOpen(t *testing.T) {
+	b := testBars(48)
+	b.open = nil
+	if _, err := PivotPoints(b, PivotDeMark, PivotDaily, Session{}); err == nil {
+		t.Error("DeMark pivots without Open succeeded")
+	}
+	if _, err := PivotPoints(b, PivotClassic, PivotDaily, Session{}); err != nil {
+		t.Errorf("classic pivots without Open: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package indicators
+
+// PriceSource selects the price an indicator is computed from.
+type PriceSource int
+
+const (
+	SourceClose PriceSource = iota
+	SourceOpen
+	SourceHigh
+	SourceLow
+	SourceHL2   // (high + low) / 2
+	SourceHLC3  // (high + low + close) / 3, the typical price
+	SourceOHLC4 // (open + high + low + close) / 4
+	SourceHLCC4 // (high + low + 2*close) / 4, the weighted close
+)
+
+// String returns the source name, e.g. "hlc3".
+func (s PriceSource) String() string {
+	switch s {
+	case SourceClose:
+		return "close"
+	case SourceOpen:
+		return "open"
+	case SourceHigh:
+		return "high"
+	case SourceLow:
+		return "low"
+	case SourceHL2:
+		return "hl2"
+	case SourceHLC3:
+		return "hlc3"
+	case SourceOHLC4:
+		return "ohlc4"
+	case SourceHLCC4:
+		return "hlcc4"
+	}
+	return "unknown"
+}
+
+// ParsePriceSource returns the PriceSource named name, as returned by
+// String.
+func ParsePriceSource(name string) (PriceSource, bool) {
+	for s := SourceClose; s <= SourceHLCC4; s++ {
+		if s.String() == name {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// needsOpen reports whether the source reads the open.
+func (s PriceSource) needsOpen() bool {
+	return s == SourceOpen || s == SourceOHLC4
+}
+
+// Value returns the source price of a single bar.
+func (s PriceSource) Value(open, high, low, close float64) float64 {
+	switch s {
+	case SourceOpen:
+		return open
+	case SourceHigh:
+		return high
+	case SourceLow:
+		return low
+	case SourceHL2:
+		return (high + low) / 2
+	case SourceHLC3:
+		return (high + low + close) / 3
+	case SourceOHLC4:
+		return (open + high + low + close) / 4
+	case SourceHLCC4:
+		return (high + low + 2*close) / 4
+	}
+	return close
+}
+
+// Series returns the source price of every bar of b. Single-column sources
+// return the column itself rather than a copy. Sources reading the open
+// report ErrMissingColumn if b has none.
+func (s PriceSource) Series(b *Bars) ([]float64, error) {
+	if s.needsOpen() {
+		if err := b.requireOpen("PriceSource " + s.String()); err != nil {
+			return nil, err
+		}
+	}
+	switch s {
+	case SourceClose:
+		return b.close, nil
+	case SourceOpen:
+		return b.open, nil
+	case SourceHigh:
+		return b.high, nil
+	case SourceLow:
+		return b.low, nil
+	}
+	out := make([]float64, b.Len())
+	for i := range out {
+		var open float64
+		if b.open != nil {
+			open = b.open[i]
+		}
+		out[i] = s.Value(open, b.high[i], b.low[i], b.close[i])
+	}
+	return out, nil
+}
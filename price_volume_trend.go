@@ -17,3 +17,56 @@ func PVT(prices, volumes []float64) []float64 {
 	}
 	return pvt
 }
+
+// PVTChecked is like PVT but reports invalid input as an error instead of
+// returning zeros.
+func PVTChecked(prices, volumes []float64) ([]float64, error) {
+	if err := firstError(
+		checkLengths("PVT", len(prices), len(volumes)),
+		checkData("PVT", len(prices), PVTLookback()),
+	); err != nil {
+		return nil, err
+	}
+	return PVT(prices, volumes), nil
+}
+
+// PVTBars calculates PVT over the closes and volumes of b.
+func PVTBars(b *Bars) ([]float64, error) {
+	return PVTChecked(b.close, b.volume)
+}
+
+// PVTLookback returns the number of leading warm-up values of PVT.
+func PVTLookback() int {
+	return 0
+}
+
+// PVTStream is the streaming counterpart of PVT.
+type PVTStream struct {
+	pvt       float64
+	prevPrice float64
+	seen      bool
+}
+
+// NewPVT returns a streaming Price Volume Trend.
+func NewPVT() *PVTStream {
+	return &PVTStream{}
+}
+
+// Update adds a bar and returns the cumulative PVT.
+func (s *PVTStream) Update(price, volume float64) (float64, bool) {
+	if s.seen && s.prevPrice != 0 {
+		s.pvt = s.pvt + ((price-s.prevPrice)/s.prevPrice)*volume
+	}
+	s.prevPrice = price
+	s.seen = true
+	return s.pvt, true
+}
+
+// Ready reports whether a price has been seen.
+func (s *PVTStream) Ready() bool { return s.seen }
+
+// Reset clears the running total.
+func (s *PVTStream) Reset() { *s = PVTStream{} }
+
+// Lookback returns the number of warm-up bars.
+func (s *PVTStream) Lookback() int { return 0 }
@@ -0,0 +1,233 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ParamKind is the type of an indicator parameter.
+type ParamKind int
+
+const (
+	IntParam ParamKind = iota
+	FloatParam
+	BoolParam
+	StringParam
+)
+
+// String returns the kind name.
+func (k ParamKind) String() string {
+	switch k {
+	case IntParam:
+		return "int"
+	case FloatParam:
+		return "float"
+	case BoolParam:
+		return "bool"
+	case StringParam:
+		return "string"
+	}
+	return "unknown"
+}
+
+// Param describes a parameter of a registered indicator.
+type Param struct {
+	Name    string
+	Kind    ParamKind
+	Default any // int, float64, bool or string according to Kind
+	Doc     string
+	// Choices lists the accepted values of a StringParam. Empty means any.
+	Choices []string
+}
+
+// Params holds parameter values by name. Resolved params hold an int,
+// float64, bool or string for every parameter of the descriptor.
+type Params map[string]any
+
+// Int returns the named int parameter, or 0 if it is absent.
+func (p Params) Int(name string) int {
+	v, _ := p[name].(int)
+	return v
+}
+
+// Float returns the named float parameter, or 0 if it is absent.
+func (p Params) Float(name string) float64 {
+	v, _ := p[name].(float64)
+	return v
+}
+
+// Bool returns the named bool parameter, or false if it is absent.
+func (p Params) Bool(name string) bool {
+	v, _ := p[name].(bool)
+	return v
+}
+
+// String returns the named string parameter, or "" if it is absent.
+func (p Params) String(name string) string {
+	v, _ := p[name].(string)
+	return v
+}
+
+// Column is a named series, such as one output of an indicator.
+type Column struct {
+	Name   string
+	Values []float64
+}
+
+// Descriptor describes a registered indicator: its parameters, the Bars
+// columns it reads, the columns it produces and how to invoke it.
+type Descriptor struct {
+	Name    string
+	Doc     string
+	Params  []Param
+	Inputs  []string // Bars columns read: "open", "high", "low", "close", "volume"
+	Outputs []string // names of the output columns, in Invoke order
+
+	// Lookback returns the warm-up length for resolved params.
+	Lookback func(p Params) int
+	// Compute calculates the outputs for resolved params, one column per
+	// entry of Outputs.
+	Compute func(b *Bars, p Params) ([]Column, error)
+}
+
+// Resolve validates p against the parameter schema and returns a copy with
+// defaults filled in. Values may be given as int, float64, bool or as a
+// string to parse. Unknown parameter names are rejected.
+func (d *Descriptor) Resolve(p Params) (Params, error) {
+	out := make(Params, len(d.Params))
+	for _, param := range d.Params {
+		out[param.Name] = param.Default
+	}
+	for name, v := range p {
+		param, ok := d.param(name)
+		if !ok {
+			return nil, fmt.Errorf("indicators: %s: unknown parameter %q", d.Name, name)
+		}
+		cv, err := convertParam(param.Kind, v)
+		if err == nil && len(param.Choices) > 0 && !slices.Contains(param.Choices, cv.(string)) {
+			err = fmt.Errorf("%q is not one of %s", cv, strings.Join(param.Choices, ", "))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("indicators: %s: parameter %q: %w", d.Name, name, err)
+		}
+		out[name] = cv
+	}
+	return out, nil
+}
+
+func (d *Descriptor) param(name string) (Param, bool) {
+	for _, param := range d.Params {
+		if param.Name == name {
+			return param, true
+		}
+	}
+	return Param{}, false
+}
+
+// Invoke resolves p and computes the indicator over b, returning one
+// column per output.
+func (d *Descriptor) Invoke(b *Bars, p Params) ([]Column, error) {
+	resolved, err := d.Resolve(p)
+	if err != nil {
+		return nil, err
+	}
+	return d.Compute(b, resolved)
+}
+
+// convertParam converts v to the Go type of kind.
+func convertParam(kind ParamKind, v any) (any, error) {
+	switch kind {
+	case IntParam:
+		switch x := v.(type) {
+		case int:
+			return x, nil
+		case int32:
+			return int(x), nil
+		case int64:
+			return int(x), nil
+		case float64:
+			if x != math.Trunc(x) {
+				return nil, fmt.Errorf("%v is not an integer", x)
+			}
+			return int(x), nil
+		case string:
+			return strconv.Atoi(x)
+		}
+	case FloatParam:
+		switch x := v.(type) {
+		case float64:
+			return x, nil
+		case float32:
+			return float64(x), nil
+		case int:
+			return float64(x), nil
+		case string:
+			return strconv.ParseFloat(x, 64)
+		}
+	case BoolParam:
+		switch x := v.(type) {
+		case bool:
+			return x, nil
+		case string:
+			return strconv.ParseBool(x)
+		}
+	case StringParam:
+		if x, ok := v.(string); ok {
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot use %T as %s", v, kind)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]*Descriptor{}
+)
+
+// Register adds d to the registry. It panics if the name is already
+// registered or d is missing its Compute function.
+func Register(d Descriptor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if d.Compute == nil {
+		panic("indicators: Register " + d.Name + " without Compute")
+	}
+	if _, dup := registry[d.Name]; dup {
+		panic("indicators: Register called twice for " + d.Name)
+	}
+	registry[d.Name] = &d
+}
+
+// Lookup returns the descriptor registered under name.
+func Lookup(name string) (*Descriptor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	d, ok := registry[name]
+	return d, ok
+}
+
+// Names returns the sorted names of all registered indicators.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Invoke computes the registered indicator name over b.
+func Invoke(name string, b *Bars, p Params) ([]Column, error) {
+	d, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("indicators: unknown indicator %q", name)
+	}
+	return d.Invoke(b, p)
+}
@@ -0,0 +1,700 @@
+package indicators
+
+import "time"
+
+// Registrations of the indicators of this package. Parameter defaults follow
+// the defaults the functions apply themselves where they have one.
+
+func init() {
+	for _, d := range builtinDescriptors() {
+		Register(d)
+	}
+}
+
+func intParam(name string, def int, doc string) Param {
+	return Param{Name: name, Kind: IntParam, Default: def, Doc: doc}
+}
+
+func floatParam(name string, def float64, doc string) Param {
+	return Param{Name: name, Kind: FloatParam, Default: def, Doc: doc}
+}
+
+func boolParam(name string, def bool, doc string) Param {
+	return Param{Name: name, Kind: BoolParam, Default: def, Doc: doc}
+}
+
+func choiceParam(name string, def string, choices []string, doc string) Param {
+	return Param{Name: name, Kind: StringParam, Default: def, Doc: doc, Choices: choices}
+}
+
+// parseChoice returns the value in [first, last] whose String is name, or
+// first. Choices are validated by Resolve before Compute is called.
+func parseChoice[T interface {
+	~int
+	String() string
+}](name string, first, last T) T {
+	for v := first; v <= last; v++ {
+		if v.String() == name {
+			return v
+		}
+	}
+	return first
+}
+
+// maParam is a parameter selecting an MAType by name.
+func maParam(name, def, doc string) Param {
+	return choiceParam(name, def, maTypes, doc)
+}
+
+// maType returns the MAType of the parameter name.
+func maType(p Params, name string) MAType {
+	return parseChoice(p.String(name), MASMA, MAZLEMA)
+}
+
+// candleOptions returns the CandleOptions of the candlestick parameters.
+func candleOptions(p Params) CandleOptions {
+	return CandleOptions{ATRPeriod: p.Int("atr_period"), TrendBars: p.Int("trend")}
+}
+
+// divergenceOptions returns the DivergenceOptions of the divergence
+// parameters.
+func divergenceOptions(p Params) DivergenceOptions {
+	return DivergenceOptions{Left: p.Int("left"), Right: p.Int("right"), MaxBars: p.Int("max_bars")}
+}
+
+// divergenceOscillator calculates the oscillator selected by the divergence
+// parameters over b.
+func divergenceOscillator(b *Bars, p Params) ([]float64, error) {
+	switch p.String("oscillator") {
+	case "kvo":
+		r, err := KVOBars(b)
+		return r.KVO, err
+	case "cmf":
+		return CMFBars(b, p.Int("period"))
+	case "stoch":
+		r, err := StochasticOscillatorBars(b, p.Int("period"), 3, false)
+		return r.StochK, err
+	}
+	return VWRSIBars(b, p.Int("period"))
+}
+
+// divergenceOscillatorLookback returns the lookback of divergenceOscillator.
+func divergenceOscillatorLookback(p Params) int {
+	switch p.String("oscillator") {
+	case "kvo":
+		return KVOLookback()
+	case "cmf":
+		return CMFLookback(p.Int("period"))
+	case "stoch":
+		return p.Int("period") - 1 // %K, without the signal line
+	}
+	return VWRSILookback(p.Int("period"))
+}
+
+// headShouldersOptions returns the HeadShouldersOptions of the
+// head_shoulders_pattern parameters.
+func headShouldersOptions(p Params) HeadShouldersOptions {
+	return HeadShouldersOptions{
+		Pivot:             p.Int("pivot"),
+		ShoulderTolerance: p.Float("tolerance"),
+		MaxBreakoutBars:   p.Int("max_breakout"),
+	}
+}
+
+// zigZagOptions returns the ZigZagOptions of the zigzag parameters.
+func zigZagOptions(p Params) ZigZagOptions {
+	return ZigZagOptions{
+		Percent:       p.Float("percent"),
+		ATRPeriod:     p.Int("atr_period"),
+		ATRMultiplier: p.Float("atr_multiplier"),
+	}
+}
+
+// ichimokuConfig returns the IchimokuConfig of the ichimoku parameters.
+func ichimokuConfig(p Params) IchimokuConfig {
+	return IchimokuConfig{
+		Tenkan:       p.Int("tenkan"),
+		Kijun:        p.Int("kijun"),
+		SenkouB:      p.Int("senkou_b"),
+		Displacement: p.Int("displacement"),
+		Causal:       p.Bool("causal"),
+	}
+}
+
+// fixedLookback returns a Lookback function for indicators without
+// parameters.
+func fixedLookback(n int) func(Params) int {
+	return func(Params) int { return n }
+}
+
+// single adapts a single-output Bars variant to Compute.
+func single(name string) func(values []float64, err error) ([]Column, error) {
+	return func(values []float64, err error) ([]Column, error) {
+		if err != nil {
+			return nil, err
+		}
+		return []Column{{Name: name, Values: values}}, nil
+	}
+}
+
+// swingColumns adapts a Bars variant returning swings to Compute, placing
+// them at their confirmation bars in the columns prefix_high and prefix_low.
+func swingColumns(prefix string, n int) func(swings []Swing, err error) ([]Column, error) {
+	return func(swings []Swing, err error) ([]Column, error) {
+		if err != nil {
+			return nil, err
+		}
+		highs, lows := SwingSeries(swings, n, true)
+		return []Column{{Name: prefix + "_high", Values: highs}, {Name: prefix + "_low", Values: lows}}, nil
+	}
+}
+
+// columns adapts a multi-output Bars variant to Compute.
+func columns[R interface{ Columns() []Column }](r R, err error) ([]Column, error) {
+	if err != nil {
+		return nil, err
+	}
+	return r.Columns(), nil
+}
+
+var (
+	inputsC    = []string{"close"}
+	inputsCV   = []string{"close", "volume"}
+	inputsHLC  = []string{"high", "low", "close"}
+	inputsHLCV = []string{"high", "low", "close", "volume"}
+	inputsOHLC = []string{"open", "high", "low", "close"}
+
+	candlePatterns = []string{
+		"doji", "dragonfly_doji", "gravestone_doji", "spinning_top", "marubozu",
+		"hammer", "hanging_man", "inverted_hammer", "shooting_star", "engulfing", "harami",
+		"piercing", "dark_cloud_cover", "morning_star", "evening_star",
+		"three_white_soldiers", "three_black_crows",
+	}
+	divergenceOscillators = []string{"vwrsi", "kvo", "cmf", "stoch"}
+	maTypes               = []string{"sma", "ema", "rma", "wma", "hma", "dema", "tema", "kama", "alma", "t3", "vwma", "zlema"}
+	priceSources          = []string{"close", "open", "high", "low", "hl2", "hlc3", "ohlc4", "hlcc4"}
+	vwapOutputs           = []string{
+		"vwap", "vwap_stddev", "vwap_upper1", "vwap_lower1",
+		"vwap_upper2", "vwap_lower2", "vwap_upper3", "vwap_lower3",
+	}
+)
+
+func builtinDescriptors() []Descriptor {
+	return []Descriptor{
+		{
+			Name:     "atr_sma",
+			Doc:      "Simple moving average of the Average True Range.",
+			Params:   []Param{intParam("period", 14, "ATR and averaging period")},
+			Inputs:   inputsHLC,
+			Outputs:  []string{"atr_sma"},
+			Lookback: func(p Params) int { return ATRSMALookback(p.Int("period")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("atr_sma")(ATRSMABars(b, p.Int("period")))
+			},
+		},
+		{
+			Name: "anchored_vwap",
+			Doc:  "VWAP anchored at a bar, with 1/2/3 deviation bands.",
+			Params: []Param{
+				intParam("anchor", 0, "index of the anchor bar"),
+				choiceParam("source", "hlc3", priceSources, "price to average"),
+			},
+			Inputs:   inputsHLCV,
+			Outputs:  vwapOutputs,
+			Lookback: func(p Params) int { return AnchoredVWAPLookback(p.Int("anchor")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				source := parseChoice(p.String("source"), SourceClose, SourceHLCC4)
+				return columns(AnchoredVWAPBars(b, source, p.Int("anchor")))
+			},
+		},
+		{
+			Name:     "bbands_percent",
+			Doc:      "Position of the close within 20-period Bollinger Bands.",
+			Params:   []Param{maParam("ma", "sma", "middle band average type")},
+			Inputs:   inputsC,
+			Outputs:  []string{"bbands_percent"},
+			Lookback: func(p Params) int { return BbandsPercentMALookback(maType(p, "ma")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("bbands_percent")(BbandsPercentMABars(b, maType(p, "ma")))
+			},
+		},
+		{
+			Name: "candlestick",
+			Doc:  "Candlestick pattern: 1 bullish, -1 bearish, 0 none, with body and shadow sizes relative to the ATR.",
+			Params: []Param{
+				choiceParam("pattern", "engulfing", candlePatterns, "pattern to detect"),
+				intParam("atr_period", 14, "ATR period sizing bodies and shadows"),
+				intParam("trend", 5, "bars of the trend before a hammer or shooting star"),
+			},
+			Inputs:  inputsOHLC,
+			Outputs: []string{"candlestick"},
+			Lookback: func(p Params) int {
+				return CandlestickPatternLookback(candleOptions(p))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				pattern := parseChoice(p.String("pattern"), CandleDoji, CandleThreeBlackCrows)
+				return single("candlestick")(CandlestickPatternBars(b, pattern, candleOptions(p)))
+			},
+		},
+		{
+			Name:     "cmf",
+			Doc:      "Chaikin Money Flow.",
+			Params:   []Param{intParam("period", 20, "summation period")},
+			Inputs:   inputsHLCV,
+			Outputs:  []string{"cmf"},
+			Lookback: func(p Params) int { return CMFLookback(p.Int("period")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("cmf")(CMFBars(b, p.Int("period")))
+			},
+		},
+		{
+			Name:     "disp14",
+			Doc:      "Displacement of the close from its 14-period average, in percent.",
+			Params:   []Param{maParam("ma", "sma", "average type")},
+			Inputs:   inputsC,
+			Outputs:  []string{"disp14"},
+			Lookback: func(p Params) int { return Disp14MALookback(maType(p, "ma")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("disp14")(Disp14MABars(b, maType(p, "ma")))
+			},
+		},
+		{
+			Name: "divergence",
+			Doc:  "Regular and hidden divergences between price and an oscillator: 1 bullish, -1 bearish at confirmation, with strength.",
+			Params: []Param{
+				choiceParam("oscillator", "vwrsi", divergenceOscillators, "oscillator compared with price"),
+				intParam("period", 14, "oscillator period (vwrsi, cmf, stoch)"),
+				intParam("left", 5, "bars before a swing point"),
+				intParam("right", 5, "bars after a swing point"),
+				intParam("max_bars", 60, "largest distance between the price pivots"),
+				choiceParam("source", "close", priceSources, "price compared with the oscillator"),
+			},
+			Inputs:  inputsHLCV,
+			Outputs: []string{"divergence", "divergence_strength"},
+			Lookback: func(p Params) int {
+				return divergenceOscillatorLookback(p) + DetectDivergencesLookback(divergenceOptions(p))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				osc, err := divergenceOscillator(b, p)
+				if err != nil {
+					return nil, err
+				}
+				source := parseChoice(p.String("source"), SourceClose, SourceHLCC4)
+				return columns(DetectDivergencesBars(b, source, osc, divergenceOptions(p)))
+			},
+		},
+		{
+			Name: "donchian",
+			Doc:  "Donchian Channels.",
+			Params: []Param{
+				intParam("lower", 20, "lowest low period"),
+				intParam("upper", 20, "highest high period"),
+			},
+			Inputs:  []string{"high", "low"},
+			Outputs: []string{"donchian_lower", "donchian_upper", "donchian_mid"},
+			Lookback: func(p Params) int {
+				return DonchianLookback(p.Int("lower"), p.Int("upper"))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				lower, upper, mid, err := DonchianBars(b, p.Int("lower"), p.Int("upper"))
+				if err != nil {
+					return nil, err
+				}
+				return DonchianColumns(lower, upper, mid), nil
+			},
+		},
+		{
+			Name:     "elder_bull",
+			Doc:      "Elder Bull: EMA(close, 13) - EMA(close, 26).",
+			Params:   []Param{maParam("ma", "ema", "average type")},
+			Inputs:   inputsC,
+			Outputs:  []string{"elder_bull"},
+			Lookback: func(p Params) int { return ElderBullMALookback(maType(p, "ma")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("elder_bull")(ElderBullMABars(b, maType(p, "ma")))
+			},
+		},
+		{
+			Name:     "elder_bear",
+			Doc:      "Elder Bear: close - EMA(close, 13).",
+			Params:   []Param{maParam("ma", "ema", "average type")},
+			Inputs:   inputsC,
+			Outputs:  []string{"elder_bear"},
+			Lookback: func(p Params) int { return ElderBearMALookback(maType(p, "ma")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("elder_bear")(ElderBearMABars(b, maType(p, "ma")))
+			},
+		},
+		{
+			Name:     "fractals",
+			Doc:      "Williams fractal highs and lows, at the bar that confirms them.",
+			Inputs:   []string{"high", "low"},
+			Outputs:  []string{"fractal_high", "fractal_low"},
+			Lookback: fixedLookback(SwingPivotsLookback(2, 2)),
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return swingColumns("fractal", b.Len())(FractalsBars(b))
+			},
+		},
+		{
+			Name:     "ema",
+			Doc:      "Exponential moving average of the close.",
+			Params:   []Param{intParam("span", 20, "smoothing span")},
+			Inputs:   inputsC,
+			Outputs:  []string{"ema"},
+			Lookback: func(Params) int { return EMALookback() },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("ema")(EMABars(b, int32(p.Int("span"))))
+			},
+		},
+		{
+			Name:     "eom",
+			Doc:      "Ease of Movement.",
+			Params:   []Param{intParam("window", 14, "smoothing window")},
+			Inputs:   []string{"high", "low", "volume"},
+			Outputs:  []string{"eom"},
+			Lookback: fixedLookback(EOMLookback()),
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("eom")(EOMBars(b, p.Int("window")))
+			},
+		},
+		{
+			Name: "force_index",
+			Doc:  "Force Index smoothed with an EMA.",
+			Params: []Param{
+				intParam("length", 13, "average length"),
+				maParam("ma", "ema", "average type"),
+			},
+			Inputs:  inputsCV,
+			Outputs: []string{"force_index"},
+			Lookback: func(p Params) int {
+				return ForceIndexMALookback(p.Int("length"), maType(p, "ma"))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("force_index")(ForceIndexMABars(b, p.Int("length"), maType(p, "ma")))
+			},
+		},
+		{
+			Name:     "head_shoulders",
+			Doc:      "Head and shoulders pattern flags.",
+			Inputs:   []string{"high", "close"},
+			Outputs:  []string{"head_shoulders"},
+			Lookback: fixedLookback(HeadShouldersLookback()),
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("head_shoulders")(HeadShouldersBars(b))
+			},
+		},
+		{
+			Name: "head_shoulders_pattern",
+			Doc:  "Swing-based head-and-shoulders: -1 at a top's neckline break, 1 at an inverse pattern's, with neckline and target.",
+			Params: []Param{
+				intParam("pivot", 3, "bars on each side of a swing point"),
+				floatParam("tolerance", 0.3, "largest shoulder difference as a fraction of the head height"),
+				intParam("max_breakout", 0, "bars after the right shoulder to break the neckline (0: shoulder distance)"),
+			},
+			Inputs:  inputsHLC,
+			Outputs: []string{"hs_signal", "hs_neckline", "hs_target"},
+			Lookback: func(p Params) int {
+				return DetectHeadShouldersLookback(headShouldersOptions(p))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return columns(DetectHeadShouldersBars(b, headShouldersOptions(p)))
+			},
+		},
+		{
+			Name:     "hma",
+			Doc:      "Hull moving average of the close.",
+			Params:   []Param{intParam("length", 10, "averaging length")},
+			Inputs:   inputsC,
+			Outputs:  []string{"hma"},
+			Lookback: func(p Params) int { return HMALookback(p.Int("length")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("hma")(HMABars(b, p.Int("length")))
+			},
+		},
+		{
+			Name: "ichimoku",
+			Doc:  "Ichimoku Cloud; chikou_span is NaN in causal mode.",
+			Params: []Param{
+				intParam("tenkan", 9, "Tenkan-sen period"),
+				intParam("kijun", 26, "Kijun-sen period"),
+				intParam("senkou_b", 52, "Senkou Span B period"),
+				intParam("displacement", 26, "bars the spans are plotted ahead"),
+				boolParam("causal", false, "leave chikou_span, which uses future closes, NaN"),
+			},
+			Inputs: inputsHLC,
+			Outputs: []string{
+				"tenkan_sen", "kijun_sen", "senkou_span_a", "senkou_span_b", "chikou_span",
+			},
+			Lookback: func(p Params) int {
+				return IchimokuWithLookback(ichimokuConfig(p))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return columns(IchimokuWithBars(b, ichimokuConfig(p)))
+			},
+		},
+		{
+			Name:     "inst_block_trade",
+			Doc:      "Institutional block trade flags.",
+			Inputs:   []string{"open", "close", "volume"},
+			Outputs:  []string{"inst_block_trade"},
+			Lookback: fixedLookback(InstBlockTradeLookback()),
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("inst_block_trade")(InstBlockTradeBars(b))
+			},
+		},
+		{
+			Name:     "kvo",
+			Doc:      "Klinger Volume Oscillator and its signal line.",
+			Params:   []Param{maParam("ma", "ema", "type of the volume trend and signal averages")},
+			Inputs:   inputsHLCV,
+			Outputs:  []string{"kvo", "kvo_signal"},
+			Lookback: func(p Params) int { return KVOMALookback(maType(p, "ma")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return columns(KVOMABars(b, maType(p, "ma")))
+			},
+		},
+		{
+			Name: "ma",
+			Doc:  "Moving average of the given type; vwma is weighted by volume.",
+			Params: []Param{
+				maParam("type", "sma", "average type"),
+				intParam("period", 20, "window length"),
+				choiceParam("source", "close", priceSources, "price to average"),
+			},
+			Inputs:  inputsCV,
+			Outputs: []string{"ma"},
+			Lookback: func(p Params) int {
+				return MALookback(p.Int("period"), maType(p, "type"))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				source := parseChoice(p.String("source"), SourceClose, SourceHLCC4)
+				return single("ma")(MABars(b, source, p.Int("period"), maType(p, "type")))
+			},
+		},
+		{
+			Name:     "pivot",
+			Doc:      "Classic pivot points with two support and resistance levels.",
+			Inputs:   inputsHLC,
+			Outputs:  []string{"pivot", "s1", "r1", "s2", "r2"},
+			Lookback: fixedLookback(PivotLookback()),
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				pivot, s1, r1, s2, r2, err := PivotBars(b)
+				if err != nil {
+					return nil, err
+				}
+				return PivotColumns(pivot, s1, r1, s2, r2), nil
+			},
+		},
+		{
+			Name: "pivot_points",
+			Doc:  "Pivot levels from the previous day, week or month applied to the bars of the next. Times are UTC.",
+			Params: []Param{
+				choiceParam("method", "classic", []string{"classic", "fibonacci", "camarilla", "woodie", "demark"}, "pivot formulas"),
+				choiceParam("period", "daily", []string{"daily", "weekly", "monthly"}, "period the levels are computed from"),
+			},
+			Inputs:   []string{"time", "high", "low", "close"},
+			Outputs:  []string{"pivot", "s1", "s2", "s3", "s4", "r1", "r2", "r3", "r4"},
+			Lookback: fixedLookback(0),
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				method := parseChoice(p.String("method"), PivotClassic, PivotDeMark)
+				period := parseChoice(p.String("period"), PivotDaily, PivotMonthly)
+				return columns(PivotPoints(b, method, period, Session{}))
+			},
+		},
+		{
+			Name:     "pvt",
+			Doc:      "Price Volume Trend.",
+			Inputs:   inputsCV,
+			Outputs:  []string{"pvt"},
+			Lookback: fixedLookback(PVTLookback()),
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("pvt")(PVTBars(b))
+			},
+		},
+		{
+			Name:     "rolling_std",
+			Doc:      "Rolling sample standard deviation of the close.",
+			Params:   []Param{intParam("window", 20, "window length")},
+			Inputs:   inputsC,
+			Outputs:  []string{"rolling_std"},
+			Lookback: func(p Params) int { return RollingStdLookback(p.Int("window")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("rolling_std")(RollingStdBars(b, p.Int("window")))
+			},
+		},
+		{
+			Name:     "rolling_vwap",
+			Doc:      "Rolling volume-weighted average of the typical price.",
+			Params:   []Param{intParam("period", 20, "window length")},
+			Inputs:   inputsHLCV,
+			Outputs:  []string{"rolling_vwap"},
+			Lookback: func(p Params) int { return RollingVWAPLookback(p.Int("period")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("rolling_vwap")(RollingVWAPBars(b, p.Int("period")))
+			},
+		},
+		{
+			Name:     "sma",
+			Doc:      "Simple moving average of the close.",
+			Params:   []Param{intParam("period", 20, "window length")},
+			Inputs:   inputsC,
+			Outputs:  []string{"sma"},
+			Lookback: func(p Params) int { return SMALookback(p.Int("period")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("sma")(SMABars(b, p.Int("period")))
+			},
+		},
+		{
+			Name: "session_vwap",
+			Doc:  "VWAP restarting each session, with 1/2/3 deviation bands. Times are UTC.",
+			Params: []Param{
+				intParam("open", 0, "session open in minutes after midnight"),
+				intParam("length", 0, "session length in minutes, 0 for a full day"),
+				choiceParam("source", "hlc3", priceSources, "price to average"),
+			},
+			Inputs:   []string{"time", "high", "low", "close", "volume"},
+			Outputs:  vwapOutputs,
+			Lookback: fixedLookback(0),
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				source := parseChoice(p.String("source"), SourceClose, SourceHLCC4)
+				return columns(SessionVWAP(b, source, Session{
+					Open:   time.Duration(p.Int("open")) * time.Minute,
+					Length: time.Duration(p.Int("length")) * time.Minute,
+				}))
+			},
+		},
+		{
+			Name: "stoch",
+			Doc:  "Stochastic Oscillator %K and its signal line.",
+			Params: []Param{
+				intParam("window", 14, "%K lookback window"),
+				intParam("smooth_window", 3, "signal line window"),
+				boolParam("fill_na", false, "replace undefined %K values with 50"),
+				maParam("signal_ma", "sma", "signal line average type"),
+			},
+			Inputs:  inputsHLC,
+			Outputs: []string{"stoch_k", "stoch_k_signal"},
+			Lookback: func(p Params) int {
+				return StochasticOscillatorMALookback(p.Int("window"), p.Int("smooth_window"), maType(p, "signal_ma"))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return columns(StochasticOscillatorMABars(b, p.Int("window"), p.Int("smooth_window"), p.Bool("fill_na"), maType(p, "signal_ma")))
+			},
+		},
+		{
+			Name: "swing_pivots",
+			Doc:  "Pivot highs and lows with left and right strength, at the bar that confirms them.",
+			Params: []Param{
+				intParam("left", 5, "bars before a pivot that it must exceed"),
+				intParam("right", 5, "bars after a pivot that must not exceed it"),
+			},
+			Inputs:  []string{"high", "low"},
+			Outputs: []string{"swing_high", "swing_low"},
+			Lookback: func(p Params) int {
+				return SwingPivotsLookback(p.Int("left"), p.Int("right"))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return swingColumns("swing", b.Len())(SwingPivotsBars(b, p.Int("left"), p.Int("right")))
+			},
+		},
+		{
+			Name: "supertrend",
+			Doc:  "Supertrend with its direction (1 or -1), long and short bands, distance of the close and flips.",
+			Params: []Param{
+				intParam("length", 7, "ATR length"),
+				floatParam("multiplier", 3, "ATR band multiplier"),
+				choiceParam("atr", "wilder", []string{"wilder", "sma", "ema"}, "ATR smoothing"),
+				choiceParam("source", "hl2", priceSources, "price the bands are centered on"),
+			},
+			Inputs: inputsHLC,
+			Outputs: []string{
+				"supertrend", "supertrend_direction", "supertrend_long", "supertrend_short",
+				"supertrend_distance", "supertrend_flip",
+			},
+			Lookback: func(p Params) int {
+				return SupertrendWithLookback(p.Int("length"), parseChoice(p.String("atr"), ATRWilder, ATRExponential))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return columns(SupertrendWithBars(b,
+					parseChoice(p.String("source"), SourceClose, SourceHLCC4),
+					p.Int("length"), p.Float("multiplier"),
+					parseChoice(p.String("atr"), ATRWilder, ATRExponential)))
+			},
+		},
+		{
+			Name: "volume_weighted_macd",
+			Doc:  "MACD of volume-weighted moving averages.",
+			Params: []Param{
+				intParam("fast", 12, "fast period"),
+				intParam("slow", 26, "slow period"),
+				intParam("signal", 9, "signal period"),
+				maParam("ma", "ema", "average type"),
+			},
+			Inputs:  inputsCV,
+			Outputs: []string{"vw_macd", "vw_macd_signal", "vw_macd_hist"},
+			Lookback: func(p Params) int {
+				return VolumeWeightedMACDMALookback(p.Int("fast"), p.Int("slow"), p.Int("signal"), maType(p, "ma"))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				macd, signal, hist, err := VolumeWeightedMACDMABars(b, p.Int("fast"), p.Int("slow"), p.Int("signal"), maType(p, "ma"))
+				if err != nil {
+					return nil, err
+				}
+				return VolumeWeightedMACDColumns(macd, signal, hist), nil
+			},
+		},
+		{
+			Name:     "vortex",
+			Doc:      "Vortex Indicator VI+ and VI-.",
+			Params:   []Param{intParam("period", 14, "summation period")},
+			Inputs:   inputsHLC,
+			Outputs:  []string{"vi_plus", "vi_minus"},
+			Lookback: func(p Params) int { return VortexLookback(p.Int("period")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return columns(VortexBars(b, p.Int("period")))
+			},
+		},
+		{
+			Name:     "vwrsi",
+			Doc:      "Volume-weighted RSI.",
+			Params:   []Param{intParam("period", 14, "RSI period")},
+			Inputs:   inputsCV,
+			Outputs:  []string{"vwrsi"},
+			Lookback: func(p Params) int { return VWRSILookback(p.Int("period")) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("vwrsi")(VWRSIBars(b, p.Int("period")))
+			},
+		},
+		{
+			Name: "zigzag",
+			Doc:  "ZigZag swing highs and lows, at the bar that confirms them.",
+			Params: []Param{
+				floatParam("percent", 5, "reversal threshold in percent"),
+				intParam("atr_period", 0, "ATR period of an ATR reversal threshold (0: use percent)"),
+				floatParam("atr_multiplier", 3, "ATR multiple of an ATR reversal threshold"),
+			},
+			Inputs:   inputsHLC,
+			Outputs:  []string{"zigzag_high", "zigzag_low"},
+			Lookback: func(p Params) int { return ZigZagLookback(zigZagOptions(p)) },
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return swingColumns("zigzag", b.Len())(ZigZagBars(b, zigZagOptions(p)))
+			},
+		},
+		{
+			Name: "zscore",
+			Doc:  "Rolling z-score of the close.",
+			Params: []Param{
+				intParam("window", 20, "window length"),
+				maParam("ma", "sma", "type of the mean"),
+			},
+			Inputs:  inputsC,
+			Outputs: []string{"zscore"},
+			Lookback: func(p Params) int {
+				return ZScoreMALookback(p.Int("window"), maType(p, "ma"))
+			},
+			Compute: func(b *Bars, p Params) ([]Column, error) {
+				return single("zscore")(ZScoreMABars(b, p.Int("window"), maType(p, "ma")))
+			},
+		},
+	}
+}
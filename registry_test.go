@@ -0,0 +1,95 @@
+package indicators
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRegistryDescriptors(t *testing.T) {
+	b := testBars(300)
+	inputs := []string{"time", "open", "high", "low", "close", "volume"}
+	for _, name := range Names() {
+		d, _ := Lookup(name)
+		if d.Name != name || d.Doc == "" || len(d.Outputs) == 0 {
+			t.Errorf("%s: incomplete descriptor", name)
+		}
+		for _, in := range d.Inputs {
+			if !slices.Contains(inputs, in) {
+				t.Errorf("%s: unknown input %q", name, in)
+			}
+		}
+		variants := []Params{nil}
+		for _, p := range d.Params {
+			if _, err := convertParam(p.Kind, p.Default); err != nil {
+				t.Errorf("%s: default of %s: %v", name, p.Name, err)
+			}
+			if p.Choices != nil && !slices.Contains(p.Choices, p.Default.(string)) {
+				t.Errorf("%s: default %v of %s is not a choice", name, p.Default, p.Name)
+			}
+			for _, c := range p.Choices {
+				variants = append(variants, Params{p.Name: c})
+			}
+			if p.Kind == BoolParam {
+				variants = append(variants, Params{p.Name: !p.Default.(bool)})
+			}
+		}
+		// Every variant returns the columns listed in Outputs, in order.
+		for _, v := range variants {
+			cols, err := d.Invoke(b, v)
+			if err != nil {
+				continue
+			}
+			got := make([]string, len(cols))
+			for i, c := range cols {
+				got[i] = c.Name
+			}
+			if !slices.Equal(got, d.Outputs) {
+				t.Errorf("%s %v: columns %v, want %v", name, v, got, d.Outputs)
+			}
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	d, _ := Lookup("supertrend")
+	p, err := d.Resolve(Params{"length": "10", "multiplier": 2, "atr": "ema"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Int("length") != 10 || p.Float("multiplier") != 2 || p.String("atr") != "ema" || p.String("source") != "hl2" {
+		t.Errorf("resolved %v", p)
+	}
+	for _, bad := range []Params{
+		{"period": 3},
+		{"length": 2.5},
+		{"length": "x"},
+		{"atr": "median"},
+		{"multiplier": true},
+	} {
+		if _, err := d.Resolve(bad); err == nil {
+			t.Errorf("Resolve(%v) succeeded", bad)
+		}
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	b := testBars(100)
+	cols, err := Invoke("sma", b, Params{"period": 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "sma", cols[0].Values, SMA(b.close, 7), 0)
+	if _, err := Invoke("nope", b, nil); err == nil {
+		t.Error("Invoke of an unknown indicator succeeded")
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering sma twice did not panic")
+		}
+	}()
+	d, _ := Lookup("sma")
+	Register(*d)
+}
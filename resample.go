@@ -0,0 +1,201 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Session describes the trading day that resampled bars are aligned to.
+// The zero Session is a continuous 24-hour day starting at midnight UTC.
+type Session struct {
+	// Location is the time zone of the session. Defaults to UTC.
+	Location *time.Location
+	// Open is the offset of the session open from midnight, e.g. 9h30m.
+	Open time.Duration
+	// Length is the duration of the session, e.g. 6h30m. Bars outside the
+	// session are dropped. Zero means the session lasts until the next open.
+	Length time.Duration
+}
+
+// ResampleOptions configures Resample.
+type ResampleOptions struct {
+	Session Session
+	// Interval is the duration of the source bars, whose Time is their open.
+	// It decides when a higher bar is complete. If zero it is inferred as the
+	// smallest gap between consecutive bars.
+	Interval time.Duration
+}
+
+// Resampled holds bars aggregated to a higher timeframe together with the
+// mapping back to the source bars.
+type Resampled struct {
+	// Bars are the aggregated bars, with Time set to the start of each
+	// period. Open is present if the source bars have it.
+	*Bars
+	// End is the end of each period, at which the aggregated bar is
+	// complete. Periods are cut short at the session close.
+	End []time.Time
+	// Index is the position in Bars of the period each source bar was
+	// aggregated into, or -1 for bars outside the session.
+	Index []int
+
+	source   []time.Time
+	interval time.Duration
+}
+
+// Resample aggregates b into bars of the given period: the first open, the
+// highest high, the lowest low, the last close and the total volume. Periods
+// start at the session open; a period of a day or more must be a whole
+// number of days, and such periods are counted in days from Monday
+// 1970-01-05 so that 7-day bars start on Mondays. b must have a Time column
+// in ascending order.
+func Resample(b *Bars, period time.Duration, opt ResampleOptions) (*Resampled, error) {
+	if !b.HasTime() {
+		return nil, &InputError{Func: "Resample", Err: ErrMissingColumn, Detail: "Time"}
+	}
+	const day = 24 * time.Hour
+	if period <= 0 || (period >= day && period%day != 0) {
+		return nil, &InputError{Func: "Resample", Err: ErrInvalidPeriod, Detail: fmt.Sprintf("period=%v, must be positive and a whole number of days if a day or more", period)}
+	}
+	for i := 1; i < len(b.time); i++ {
+		if b.time[i].Before(b.time[i-1]) {
+			return nil, &InputError{Func: "Resample", Err: ErrUnsortedTime, Detail: fmt.Sprintf("bar %d", i)}
+		}
+	}
+	s := opt.Session
+	if s.Location == nil {
+		s.Location = time.UTC
+	}
+	interval := opt.Interval
+	if interval <= 0 {
+		interval = minInterval(b.time)
+	}
+
+	n := b.Len()
+	r := &Resampled{
+		Bars:     &Bars{},
+		Index:    make([]int, n),
+		source:   b.time,
+		interval: interval,
+	}
+	if b.HasOpen() {
+		r.open = []float64{}
+	}
+	var curStart time.Time
+	for i, t := range b.time {
+		start, end, ok := s.period(t, period)
+		if !ok {
+			r.Index[i] = -1
+			continue
+		}
+		last := len(r.time) - 1
+		if last < 0 || !start.Equal(curStart) {
+			curStart = start
+			r.time = append(r.time, start)
+			r.End = append(r.End, end)
+			if r.open != nil {
+				r.open = append(r.open, b.open[i])
+			}
+			r.high = append(r.high, b.high[i])
+			r.low = append(r.low, b.low[i])
+			r.close = append(r.close, b.close[i])
+			r.volume = append(r.volume, b.volume[i])
+			r.Index[i] = last + 1
+			continue
+		}
+		r.high[last] = math.Max(r.high[last], b.high[i])
+		r.low[last] = math.Min(r.low[last], b.low[i])
+		r.close[last] = b.close[i]
+		r.volume[last] += b.volume[i]
+		r.Index[i] = last
+	}
+	return r, nil
+}
+
+// minInterval returns the smallest positive gap between consecutive times,
+// or 0 if there is none.
+func minInterval(times []time.Time) time.Duration {
+	var m time.Duration
+	for i := 1; i < len(times); i++ {
+		if d := times[i].Sub(times[i-1]); d > 0 && (m == 0 || d < m) {
+			m = d
+		}
+	}
+	return m
+}
+
+// epochMonday is the day periods of a day or more are counted from.
+var epochMonday = time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// period returns the bounds of the period of the given length containing t,
+// or false if t is outside the session.
+func (s Session) period(t time.Time, length time.Duration) (start, end time.Time, ok bool) {
+	const day = 24 * time.Hour
+	lt := t.In(s.Location)
+	y, m, d := lt.Date()
+	open := s.openOn(y, m, d, 0)
+	if open.After(t) {
+		open = s.openOn(y, m, d, -1)
+		y, m, d = open.Date()
+	}
+	sessionClose := s.openOn(y, m, d, 1)
+	if s.Length > 0 {
+		sessionClose = open.Add(s.Length)
+	}
+	if !t.Before(sessionClose) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	if length < day {
+		start = open.Add(t.Sub(open) / length * length)
+		end = start.Add(length)
+		if end.After(sessionClose) {
+			end = sessionClose
+		}
+		return start, end, true
+	}
+
+	days := int(length / day)
+	index := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(epochMonday) / day)
+	first := index - ((index%days)+days)%days
+	offset := first - index
+	start = s.openOn(y, m, d, offset)
+	sy, sm, sd := start.Date()
+	end = s.openOn(sy, sm, sd, days)
+	if s.Length > 0 {
+		end = s.openOn(sy, sm, sd, days-1).Add(s.Length)
+	}
+	return start, end, true
+}
+
+// openOn returns the session open on the given date plus days. The open is
+// a wall-clock time, so it does not move across daylight saving changes.
+func (s Session) openOn(y int, m time.Month, d, days int) time.Time {
+	return time.Date(y, m, d+days, 0, 0, 0, int(s.Open), s.Location)
+}
+
+// Project maps series, one value per resampled bar, back onto the source
+// bars without lookahead: each source bar gets the value of the latest
+// resampled bar complete by its close, that is whose End is not after the
+// source bar's Time plus the interval. Bars before the first complete
+// period get NaN.
+func (r *Resampled) Project(series []float64) ([]float64, error) {
+	if err := checkLengths("Project", len(series), len(r.time)); err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(r.source))
+	j := 0
+	for i, t := range r.source {
+		closeTime := t.Add(r.interval)
+		for j < len(r.End) && !r.End[j].After(closeTime) {
+			j++
+		}
+		if j == 0 {
+			out[i] = math.NaN()
+		} else {
+			out[i] = series[j-1]
+		}
+	}
+	return out, nil
+}
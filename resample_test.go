@@ -0,0 +1,131 @@
+package indicators
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestResampleAggregates(t *testing.T) {
+	b := testBars(48) // hourly from midnight
+	r, err := Resample(b, 4*time.Hour, ResampleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 12 {
+		t.Fatalf("%d bars, want 12", r.Len())
+	}
+	for k := 0; k < r.Len(); k++ {
+		lo, hi := 4*k, 4*k+4
+		high, low, volume := math.Inf(-1), math.Inf(1), 0.0
+		for i := lo; i < hi; i++ {
+			high, low = math.Max(high, b.high[i]), math.Min(low, b.low[i])
+			volume += b.volume[i]
+			if r.Index[i] != k {
+				t.Errorf("Index[%d] = %d, want %d", i, r.Index[i], k)
+			}
+		}
+		if !r.time[k].Equal(b.time[lo]) || !r.End[k].Equal(b.time[lo].Add(4*time.Hour)) {
+			t.Errorf("bar %d spans %v to %v", k, r.time[k], r.End[k])
+		}
+		if r.open[k] != b.open[lo] || r.high[k] != high || r.low[k] != low || r.close[k] != b.close[hi-1] || math.Abs(r.volume[k]-volume) > 1e-9 {
+			t.Errorf("bar %d = %v %v %v %v %v", k, r.open[k], r.high[k], r.low[k], r.close[k], r.volume[k])
+		}
+	}
+}
+
+func TestResampleSession(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	// Half-hourly bars over a day, with a 9:30 to 16:00 session.
+	start := time.Date(2024, 3, 5, 0, 0, 0, 0, ny)
+	n := 48
+	times := make([]time.Time, n)
+	for i := range times {
+		times[i] = start.Add(time.Duration(i) * 30 * time.Minute)
+	}
+	_, high, low, close, volume := testOHLCV(n)
+	b, _ := NewBars(BarColumns{Time: times, High: high, Low: low, Close: close, Volume: volume})
+	session := Session{Location: ny, Open: 9*time.Hour + 30*time.Minute, Length: 6*time.Hour + 30*time.Minute}
+	r, err := Resample(b, time.Hour, ResampleOptions{Session: session})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 9:30 to 16:00 is six full hours and a half hour cut at the close.
+	if r.Len() != 7 {
+		t.Fatalf("%d bars, want 7", r.Len())
+	}
+	if r.time[0].In(ny).Format("15:04") != "09:30" || r.End[6].In(ny).Format("15:04") != "16:00" {
+		t.Errorf("session bars span %v to %v", r.time[0], r.End[6])
+	}
+	for i, tm := range times {
+		inSession := !tm.Before(start.Add(session.Open)) && tm.Before(start.Add(session.Open+session.Length))
+		if (r.Index[i] >= 0) != inSession {
+			t.Errorf("bar at %v: Index %d", tm.Format("15:04"), r.Index[i])
+		}
+	}
+}
+
+func TestResampleWeeksStartOnMonday(t *testing.T) {
+	b := testBars(24 * 20)
+	r, err := Resample(b, 7*24*time.Hour, ResampleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, tm := range r.time[1:] {
+		if tm.Weekday() != time.Monday {
+			t.Errorf("week %d starts on %v", k+1, tm.Weekday())
+		}
+	}
+	// The first bars, from Tuesday 2024-01-02, belong to the week started
+	// the day before.
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !r.time[0].Equal(want) {
+		t.Errorf("first week starts %v, want %v", r.time[0], want)
+	}
+}
+
+func TestResampleProjectHasNoLookahead(t *testing.T) {
+	b := testBars(48)
+	r, err := Resample(b, 4*time.Hour, ResampleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Project(r.close)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range got {
+		// A 4-hour bar is complete at the close of its last hourly bar.
+		complete := (i+1)/4 - 1
+		if complete < 0 {
+			if !math.IsNaN(got[i]) {
+				t.Errorf("[%d] = %v before the first complete bar", i, got[i])
+			}
+			continue
+		}
+		if got[i] != r.close[complete] {
+			t.Errorf("[%d] = %v, want the close of bar %d", i, got[i], complete)
+		}
+	}
+	if _, err := r.Project(r.close[1:]); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("Project of a short series: %v", err)
+	}
+}
+
+func TestResampleErrors(t *testing.T) {
+	b := testBars(10)
+	if _, err := Resample(b, 36*time.Hour, ResampleOptions{}); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("36h period: %v", err)
+	}
+	b.time[5], b.time[6] = b.time[6], b.time[5]
+	if _, err := Resample(b, time.Hour, ResampleOptions{}); !errors.Is(err, ErrUnsortedTime) {
+		t.Errorf("unsorted times: %v", err)
+	}
+	b.time = nil
+	if _, err := Resample(b, time.Hour, ResampleOptions{}); !errors.Is(err, ErrMissingColumn) {
+		t.Errorf("no times: %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package indicators
+
+import "math"
+
+// Rolling window kernels shared by the batch and streaming indicators. Each
+// one updates in O(1) amortized time per value instead of rescanning the
+// window.
+
+// rollingSum maintains the sum of the last period values with Neumaier
+// compensated summation. NaN and infinite values are counted instead of
+// being added, so that they only affect the windows that contain them, as
+// with a direct re-summation of the window.
+type rollingSum struct {
+	window              *ring
+	sum, comp           float64
+	nan, posInf, negInf int
+}
+
+func newRollingSum(period int) *rollingSum {
+	return &rollingSum{window: newRing(period)}
+}
+
+// push adds v to the window, evicting the oldest value once it is full.
+func (r *rollingSum) push(v float64) {
+	if r.window.full() {
+		r.add(r.window.at(0), -1)
+	}
+	r.window.push(v)
+	r.add(v, 1)
+}
+
+func (r *rollingSum) add(v float64, sign int) {
+	switch {
+	case math.IsNaN(v):
+		r.nan += sign
+	case math.IsInf(v, 1):
+		r.posInf += sign
+	case math.IsInf(v, -1):
+		r.negInf += sign
+	default:
+		if sign < 0 {
+			v = -v
+		}
+		t := r.sum + v
+		if math.Abs(r.sum) >= math.Abs(v) {
+			r.comp += (r.sum - t) + v
+		} else {
+			r.comp += (v - t) + r.sum
+		}
+		r.sum = t
+	}
+}
+
+// value returns the sum of the values in the window.
+func (r *rollingSum) value() float64 {
+	switch {
+	case r.nan > 0 || (r.posInf > 0 && r.negInf > 0):
+		return math.NaN()
+	case r.posInf > 0:
+		return math.Inf(1)
+	case r.negInf > 0:
+		return math.Inf(-1)
+	}
+	return r.sum + r.comp
+}
+
+// full reports whether the window holds period values.
+func (r *rollingSum) full() bool {
+	return r.window.full()
+}
+
+func (r *rollingSum) reset() {
+	r.window.reset()
+	r.sum, r.comp = 0, 0
+	r.nan, r.posInf, r.negInf = 0, 0, 0
+}
+
+// rollingVar maintains the mean and sum of squared deviations of the last
+// period values with Welford's algorithm, which avoids the cancellation of
+// the sum of squares formula. While the window holds a NaN or infinity the
+// variance is NaN; once it has left, the statistics are rebuilt from the
+// window. A window of identical values has a variance of exactly zero.
+type rollingVar struct {
+	window    *ring
+	mean, m2  float64
+	nonFinite int
+	run       int // number of trailing identical values
+}
+
+func newRollingVar(period int) *rollingVar {
+	return &rollingVar{window: newRing(period)}
+}
+
+// push adds v to the window, evicting the oldest value once it is full.
+func (r *rollingVar) push(v float64) {
+	if r.window.count > 0 && v == r.window.at(r.window.count-1) {
+		r.run++
+	} else {
+		r.run = 1
+	}
+
+	evicted, full := r.window.at(0), r.window.full()
+	r.window.push(v)
+	if isNonFinite(v) {
+		r.nonFinite++
+	}
+	if full && isNonFinite(evicted) {
+		r.nonFinite--
+		if r.nonFinite == 0 {
+			r.rebuild()
+			return
+		}
+	}
+	if r.nonFinite > 0 {
+		return
+	}
+
+	if !full {
+		n := float64(r.window.count)
+		delta := v - r.mean
+		r.mean += delta / n
+		r.m2 += delta * (v - r.mean)
+		return
+	}
+	oldMean := r.mean
+	delta := v - evicted
+	r.mean += delta / float64(r.window.count)
+	r.m2 += delta * (v - r.mean + evicted - oldMean)
+}
+
+// rebuild recomputes the statistics from the values in the window.
+func (r *rollingVar) rebuild() {
+	r.mean, r.m2 = 0, 0
+	for i := 0; i < r.window.count; i++ {
+		v := r.window.at(i)
+		delta := v - r.mean
+		r.mean += delta / float64(i+1)
+		r.m2 += delta * (v - r.mean)
+	}
+}
+
+// variance returns the sample variance of the window.
+func (r *rollingVar) variance() float64 {
+	if r.nonFinite > 0 || r.window.count < 2 {
+		return math.NaN()
+	}
+	if r.run >= r.window.count {
+		return 0
+	}
+	variance := r.m2 / float64(r.window.count-1)
+	if variance < 0 {
+		variance = 0
+	}
+	return variance
+}
+
+// full reports whether the window holds period values.
+func (r *rollingVar) full() bool {
+	return r.window.full()
+}
+
+func (r *rollingVar) reset() {
+	r.window.reset()
+	r.mean, r.m2 = 0, 0
+	r.nonFinite, r.run = 0, 0
+}
+
+func isNonFinite(v float64) bool {
+	return math.IsNaN(v) || math.IsInf(v, 0)
+}
+
+// rollingExtreme tracks the maximum (or minimum) of the last period values
+// with a monotonic deque. NaN values are skipped, as in maxInSlice and
+// minInSlice.
+type rollingExtreme struct {
+	period int
+	max    bool
+	idx    []int
+	val    []float64
+	head   int
+	seen   int
+}
+
+func newRollingMax(period int) *rollingExtreme {
+	return &rollingExtreme{period: period, max: true}
+}
+
+func newRollingMin(period int) *rollingExtreme {
+	return &rollingExtreme{period: period}
+}
+
+// push adds v to the window, evicting values older than period.
+func (r *rollingExtreme) push(v float64) {
+	i := r.seen
+	r.seen++
+	for r.head < len(r.idx) && r.idx[r.head] <= i-r.period {
+		r.head++
+	}
+	if !math.IsNaN(v) {
+		for len(r.idx) > r.head {
+			last := r.val[len(r.val)-1]
+			if (r.max && last > v) || (!r.max && last < v) {
+				break
+			}
+			r.idx = r.idx[:len(r.idx)-1]
+			r.val = r.val[:len(r.val)-1]
+		}
+		r.idx = append(r.idx, i)
+		r.val = append(r.val, v)
+	}
+	// Reclaim the space of evicted entries once they dominate the deque.
+	if r.head > 32 && r.head*2 > len(r.idx) {
+		n := copy(r.idx, r.idx[r.head:])
+		copy(r.val, r.val[r.head:])
+		r.idx, r.val = r.idx[:n], r.val[:n]
+		r.head = 0
+	}
+}
+
+// value returns the extreme of the window, or -Inf (+Inf for a minimum) if
+// it holds no value other than NaN.
+func (r *rollingExtreme) value() float64 {
+	if r.head == len(r.idx) {
+		if r.max {
+			return math.Inf(-1)
+		}
+		return math.Inf(1)
+	}
+	return r.val[r.head]
+}
+
+// empty reports whether the window holds no value other than NaN.
+func (r *rollingExtreme) empty() bool {
+	return r.head == len(r.idx)
+}
+
+// full reports whether period values have been pushed.
+func (r *rollingExtreme) full() bool {
+	return r.seen >= r.period
+}
+
+func (r *rollingExtreme) reset() {
+	r.idx, r.val = r.idx[:0], r.val[:0]
+	r.head, r.seen = 0, 0
+}
@@ -2,34 +2,77 @@ package indicators
 
 import "math"
 
+// RollingStd calculates the rolling sample standard deviation of data, using
+// Welford's algorithm over the window. Values during warm-up are NaN, and so
+// is every value for a non-positive window.
 func RollingStd(data []float64, window int) []float64 {
-	result := make([]float64, len(data))
-
-	if window <= 0 || len(data) < window {
-		return result
+	if window <= 0 {
+		return constant(len(data), math.NaN())
 	}
+	result := make([]float64, len(data))
 
 	// Calculate the rolling standard deviation
-	for i := range data {
-		if i+1 < window {
-			result[i] = math.NaN()
-			continue
-		}
-
-		sum := 0.0
-		sumSq := 0.0
-		for j := i - window + 1; j <= i; j++ {
-			sum += data[j]
-			sumSq += data[j] * data[j]
-		}
-
-		mean := sum / float64(window)
-		// Sample variance: divide by (window - 1)
-		variance := (sumSq - float64(window)*mean*mean) / float64(window-1)
-		if variance < 0 {
-			variance = 0
-		}
-		result[i] = math.Sqrt(variance)
+	std := NewRollingStd(window)
+	for i, v := range data {
+		result[i], _ = std.Update(v)
 	}
 	return result
 }
+
+// RollingStdChecked is like RollingStd but reports invalid input as an
+// error. The sample standard deviation needs a window of at least 2.
+func RollingStdChecked(data []float64, window int) ([]float64, error) {
+	if err := firstError(
+		checkPeriod("RollingStd", "window", window, 2),
+		checkData("RollingStd", len(data), RollingStdLookback(window)),
+	); err != nil {
+		return nil, err
+	}
+	return RollingStd(data, window), nil
+}
+
+// RollingStdBars calculates RollingStd over the closes of b.
+func RollingStdBars(b *Bars, window int) ([]float64, error) {
+	return RollingStdChecked(b.close, window)
+}
+
+// RollingStdLookback returns the number of leading warm-up values of
+// RollingStd.
+func RollingStdLookback(window int) int {
+	return window - 1
+}
+
+// RollingStdStream is the streaming counterpart of RollingStd.
+type RollingStdStream struct {
+	window int
+	values *rollingVar
+}
+
+// NewRollingStd returns a streaming sample standard deviation over window
+// values.
+func NewRollingStd(window int) *RollingStdStream {
+	if window <= 0 {
+		panic("window must be greater than 0")
+	}
+	return &RollingStdStream{window: window, values: newRollingVar(window)}
+}
+
+// Update adds v and returns the current standard deviation, or NaN during
+// warm-up.
+func (s *RollingStdStream) Update(v float64) (float64, bool) {
+	s.values.push(v)
+	if !s.values.full() {
+		return math.NaN(), false
+	}
+	// Sample variance: divide by (window - 1)
+	return math.Sqrt(s.values.variance()), true
+}
+
+// Ready reports whether a full window has been seen.
+func (s *RollingStdStream) Ready() bool { return s.values.full() }
+
+// Reset clears the window.
+func (s *RollingStdStream) Reset() { s.values.reset() }
+
+// Lookback returns the number of warm-up bars.
+func (s *RollingStdStream) Lookback() int { return s.window - 1 }
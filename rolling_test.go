@@ -0,0 +1,194 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// naiveWindow calls f with the window of period values ending at each
+// index from period-1 on, and returns its results, NaN before.
+func naiveWindow(data []float64, period int, f func(w []float64) float64) []float64 {
+	out := constant(len(data), math.NaN())
+	for i := period - 1; i < len(data); i++ {
+		out[i] = f(data[i-period+1 : i+1])
+	}
+	return out
+}
+
+func naiveStd(w []float64) float64 {
+	mean := naiveSum(w) / float64(len(w))
+	var ss float64
+	for _, v := range w {
+		ss += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(ss / float64(len(w)-1))
+}
+
+func naiveExtreme(max bool) func(w []float64) float64 {
+	return func(w []float64) float64 {
+		ext := math.Inf(1)
+		if max {
+			ext = math.Inf(-1)
+		}
+		for _, v := range w {
+			if (max && v > ext) || (!max && v < ext) {
+				ext = v
+			}
+		}
+		return ext
+	}
+}
+
+// gappyData returns a test series with NaN and infinite values in it.
+func gappyData(n int) []float64 {
+	_, _, _, close, _ := testOHLCV(n)
+	close[10] = math.NaN()
+	close[11] = math.NaN()
+	close[40] = math.Inf(1)
+	close[70] = math.Inf(-1)
+	close[71] = math.Inf(1)
+	return close
+}
+
+func TestRollingKernelsMatchNaive(t *testing.T) {
+	data := gappyData(200)
+	for _, period := range []int{1, 2, 5, 30} {
+		name := fmt.Sprint("period ", period)
+
+		sum := newRollingSum(period)
+		gotSum := make([]float64, len(data))
+		for i, v := range data {
+			sum.push(v)
+			gotSum[i] = math.NaN()
+			if sum.full() {
+				gotSum[i] = sum.value()
+			}
+		}
+		assertClose(t, "sum "+name, gotSum, naiveWindow(data, period, naiveSum), 1e-9)
+
+		for _, max := range []bool{true, false} {
+			r := newRollingMin(period)
+			if max {
+				r = newRollingMax(period)
+			}
+			got := make([]float64, len(data))
+			for i, v := range data {
+				r.push(v)
+				got[i] = math.NaN()
+				if r.full() {
+					got[i] = r.value()
+				}
+			}
+			assertClose(t, fmt.Sprint("extreme max=", max, " ", name), got, naiveWindow(data, period, naiveExtreme(max)), 0)
+		}
+	}
+}
+
+func TestRollingVarAfterNonFinite(t *testing.T) {
+	data := gappyData(200)
+	const period = 8
+	v := newRollingVar(period)
+	want := naiveWindow(data, period, func(w []float64) float64 {
+		for _, x := range w {
+			if isNonFinite(x) {
+				return math.NaN()
+			}
+		}
+		s := naiveStd(w)
+		return s * s
+	})
+	got := make([]float64, len(data))
+	for i, x := range data {
+		v.push(x)
+		got[i] = math.NaN()
+		if v.full() {
+			got[i] = v.variance()
+		}
+	}
+	assertClose(t, "variance", got, want, 1e-9)
+
+	// A window of identical values has no rounding residue.
+	v.reset()
+	for i := 0; i < 3*period; i++ {
+		v.push(0.1 * float64(i%3))
+	}
+	for i := 0; i < period; i++ {
+		v.push(0.3)
+	}
+	if got := v.variance(); got != 0 {
+		t.Errorf("variance of a constant window = %v, want 0", got)
+	}
+}
+
+func TestRollingIndicatorsMatchNaive(t *testing.T) {
+	_, high, low, close, volume := testOHLCV(500)
+	for _, period := range []int{2, 14, 200} {
+		name := fmt.Sprint("period ", period)
+		assertClose(t, "sma "+name, SMA(close, period), naiveWindow(close, period, func(w []float64) float64 {
+			return naiveSum(w) / float64(len(w))
+		}), 1e-9)
+		// The incremental update rounds relative to the prices, around 100.
+		assertClose(t, "std "+name, RollingStd(close, period), naiveWindow(close, period, naiveStd), 1e-8)
+
+		lower, upper, _ := Donchian(high, low, period, period)
+		assertClose(t, "donchian lower "+name, lower, naiveWindow(low, period, naiveExtreme(false)), 0)
+		assertClose(t, "donchian upper "+name, upper, naiveWindow(high, period, naiveExtreme(true)), 0)
+
+		tpv := make([]float64, len(close))
+		for i := range close {
+			tpv[i] = (high[i] + low[i] + close[i]) / 3 * volume[i]
+		}
+		tpvSum := naiveWindow(tpv, period, naiveSum)
+		volSum := naiveWindow(volume, period, naiveSum)
+		want := make([]float64, len(close))
+		for i := range want {
+			want[i] = tpvSum[i] / volSum[i]
+		}
+		assertClose(t, "vwap "+name, RollingVWAP(high, low, close, volume, period), want, 1e-9)
+	}
+}
+
+func BenchmarkSMA(b *testing.B) {
+	_, _, _, close, _ := testOHLCV(100000)
+	for _, period := range []int{20, 1000, 10000} {
+		b.Run(fmt.Sprint(period), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				SMA(close, period)
+			}
+		})
+	}
+}
+
+func BenchmarkRollingStd(b *testing.B) {
+	_, _, _, close, _ := testOHLCV(100000)
+	for _, window := range []int{20, 1000, 10000} {
+		b.Run(fmt.Sprint(window), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				RollingStd(close, window)
+			}
+		})
+	}
+}
+
+func BenchmarkDonchian(b *testing.B) {
+	_, high, low, _, _ := testOHLCV(100000)
+	for _, period := range []int{20, 1000, 10000} {
+		b.Run(fmt.Sprint(period), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Donchian(high, low, period, period)
+			}
+		})
+	}
+}
+
+func BenchmarkRollingVWAP(b *testing.B) {
+	_, high, low, close, volume := testOHLCV(100000)
+	for _, period := range []int{20, 1000, 10000} {
+		b.Run(fmt.Sprint(period), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				RollingVWAP(high, low, close, volume, period)
+			}
+		})
+	}
+}
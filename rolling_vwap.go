@@ -1,5 +1,9 @@
 package indicators
 
+import "math"
+
+// RollingVWAP calculates the VWAP of the typical price over the last period
+// bars. Values before the first full window are NaN.
 func RollingVWAP(highs, lows, closes, volumes []float64, period int) []float64 {
 	n := len(highs)
 	if len(lows) != n || len(closes) != n || len(volumes) != n {
@@ -32,9 +36,94 @@ func RollingVWAP(highs, lows, closes, volumes []float64, period int) []float64 {
 		if i+1 >= period {
 			vwaps[i] = sumTPV / sumVol
 		} else {
-			vwaps[i] = 0 // or math.NaN() if you prefer
+			vwaps[i] = math.NaN()
 		}
 	}
 
 	return vwaps
 }
+
+// RollingVWAPChecked is like RollingVWAP but reports invalid input as an
+// error instead of panicking.
+func RollingVWAPChecked(highs, lows, closes, volumes []float64, period int) ([]float64, error) {
+	if err := firstError(
+		checkLengths("RollingVWAP", len(highs), len(lows), len(closes), len(volumes)),
+		checkPeriod("RollingVWAP", "period", period, 1),
+		checkData("RollingVWAP", len(highs), RollingVWAPLookback(period)),
+	); err != nil {
+		return nil, err
+	}
+	return RollingVWAP(highs, lows, closes, volumes, period), nil
+}
+
+// RollingVWAPBars calculates RollingVWAP over b.
+func RollingVWAPBars(b *Bars, period int) ([]float64, error) {
+	return RollingVWAPChecked(b.high, b.low, b.close, b.volume, period)
+}
+
+// RollingVWAPLookback returns the number of leading warm-up values of
+// RollingVWAP.
+func RollingVWAPLookback(period int) int {
+	return period - 1
+}
+
+// RollingVWAPStream is the streaming counterpart of RollingVWAP.
+type RollingVWAPStream struct {
+	period   int
+	tpvQueue *ring
+	volQueue *ring
+	seen     int
+
+	sumTPV, sumVol float64
+}
+
+// NewRollingVWAP returns a streaming VWAP over the last period bars.
+func NewRollingVWAP(period int) *RollingVWAPStream {
+	if period <= 0 {
+		panic("period must be greater than 0")
+	}
+	return &RollingVWAPStream{
+		period:   period,
+		tpvQueue: newRing(period),
+		volQueue: newRing(period),
+	}
+}
+
+// Update adds a bar and returns the current VWAP, or NaN during warm-up.
+func (s *RollingVWAPStream) Update(high, low, close, volume float64) (float64, bool) {
+	typicalPrice := (high + low + close) / 3
+	tpv := typicalPrice * volume
+
+	evict := s.tpvQueue.full()
+	oldTPV, oldVol := s.tpvQueue.at(0), s.volQueue.at(0)
+	s.tpvQueue.push(tpv)
+	s.volQueue.push(volume)
+
+	s.sumTPV += tpv
+	s.sumVol += volume
+
+	if evict {
+		s.sumTPV -= oldTPV
+		s.sumVol -= oldVol
+	}
+
+	s.seen++
+	if s.seen < s.period {
+		return math.NaN(), false
+	}
+	return s.sumTPV / s.sumVol, true
+}
+
+// Ready reports whether a full window has been seen.
+func (s *RollingVWAPStream) Ready() bool { return s.seen >= s.period }
+
+// Reset clears the window and running sums.
+func (s *RollingVWAPStream) Reset() {
+	s.tpvQueue.reset()
+	s.volQueue.reset()
+	s.seen = 0
+	s.sumTPV, s.sumVol = 0, 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *RollingVWAPStream) Lookback() int { return s.period - 1 }
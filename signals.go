@@ -0,0 +1,224 @@
+package indicators
+
+import "math"
+
+// The functions in this file turn indicator series into event series. Events
+// are 1 (or -1 for the bearish side of a signed event) at the bars where
+// they occur and 0 elsewhere. A NaN input, such as an indicator during its
+// warm-up, makes the output at that bar NaN rather than 0, so that an
+// undefined comparison is never mistaken for the absence of an event.
+
+// CrossAbove returns 1 at the bars where x rises above y: x was at or below
+// y at the previous bar and is above it now. The first bar is NaN. It
+// returns nil if the lengths differ.
+func CrossAbove(x, y []float64) []float64 {
+	return crossSeries(x, y, func(c float64) float64 { return math.Max(c, 0) })
+}
+
+// CrossBelow returns 1 at the bars where x falls below y: x was at or above
+// y at the previous bar and is below it now. The first bar is NaN. It
+// returns nil if the lengths differ.
+func CrossBelow(x, y []float64) []float64 {
+	return crossSeries(x, y, func(c float64) float64 { return math.Max(-c, 0) })
+}
+
+// Cross returns 1 where x crosses above y, -1 where it crosses below and 0
+// elsewhere, such as for KVO against KVOSignal. The first bar is NaN. It
+// returns nil if the lengths differ.
+func Cross(x, y []float64) []float64 {
+	return crossSeries(x, y, func(c float64) float64 { return c })
+}
+
+// CrossAboveLevel is CrossAbove against a constant level.
+func CrossAboveLevel(x []float64, level float64) []float64 {
+	return CrossAbove(x, constant(len(x), level))
+}
+
+// CrossBelowLevel is CrossBelow against a constant level.
+func CrossBelowLevel(x []float64, level float64) []float64 {
+	return CrossBelow(x, constant(len(x), level))
+}
+
+// CrossLevel is Cross against a constant level, such as zero for ElderBull.
+func CrossLevel(x []float64, level float64) []float64 {
+	return Cross(x, constant(len(x), level))
+}
+
+// crossSeries runs a CrossStream over x and y and maps each signed crossing
+// through f.
+func crossSeries(x, y []float64, f func(float64) float64) []float64 {
+	if len(x) != len(y) {
+		return nil
+	}
+	out := make([]float64, len(x))
+	var s CrossStream
+	for i := range x {
+		c, ok := s.Update(x[i], y[i])
+		if !ok {
+			out[i] = math.NaN()
+			continue
+		}
+		out[i] = f(c)
+	}
+	return out
+}
+
+// CrossStream is the streaming counterpart of Cross. Its zero value is ready
+// to use.
+type CrossStream struct {
+	px, py float64
+	seen   int
+}
+
+// Update adds a pair of values and returns 1 if x crossed above y, -1 if it
+// crossed below and 0 otherwise. It reports false, with NaN, at the first
+// pair and whenever a value of this or the previous pair is NaN.
+func (s *CrossStream) Update(x, y float64) (float64, bool) {
+	px, py := s.px, s.py
+	s.px, s.py = x, y
+	s.seen++
+	if s.seen < 2 || math.IsNaN(px) || math.IsNaN(py) || math.IsNaN(x) || math.IsNaN(y) {
+		return math.NaN(), false
+	}
+	switch {
+	case px <= py && x > y:
+		return 1, true
+	case px >= py && x < y:
+		return -1, true
+	}
+	return 0, true
+}
+
+// Ready reports whether a previous pair has been seen.
+func (s *CrossStream) Ready() bool { return s.seen > 1 }
+
+// Reset forgets the previous pair.
+func (s *CrossStream) Reset() { s.seen = 0 }
+
+// Lookback returns the number of warm-up values.
+func (s *CrossStream) Lookback() int { return 1 }
+
+// Hysteresis returns 1 while x is in a zone and 0 while it is out of it. If
+// enter is above exit the zone is entered when x rises above enter and left
+// when it falls below exit; otherwise it is entered when x falls below enter
+// and left when it rises above exit. The gap between the two thresholds keeps
+// noise around a single threshold from toggling the state, as with a z-score
+// entering at 2 and leaving at 0.5. The state is NaN where x is NaN and is
+// carried across such bars.
+func Hysteresis(x []float64, enter, exit float64) []float64 {
+	out := make([]float64, len(x))
+	s := NewHysteresis(enter, exit)
+	for i, v := range x {
+		out[i], _ = s.Update(v)
+	}
+	return out
+}
+
+// Transitions returns 1 where a 0/1 state such as that of Hysteresis turns
+// on, -1 where it turns off and 0 elsewhere. A state of 1 at the first
+// defined bar is an entry. Bars where the state is NaN are NaN.
+func Transitions(state []float64) []float64 {
+	out := make([]float64, len(state))
+	var prev float64
+	for i, v := range state {
+		if math.IsNaN(v) {
+			out[i] = math.NaN()
+			continue
+		}
+		switch {
+		case v != 0 && prev == 0:
+			out[i] = 1
+		case v == 0 && prev != 0:
+			out[i] = -1
+		}
+		prev = v
+	}
+	return out
+}
+
+// HysteresisStream is the streaming counterpart of Hysteresis.
+type HysteresisStream struct {
+	enter, exit float64
+	in          bool
+	seen        int
+}
+
+// NewHysteresis returns a streaming Hysteresis with the given thresholds.
+func NewHysteresis(enter, exit float64) *HysteresisStream {
+	return &HysteresisStream{enter: enter, exit: exit}
+}
+
+// Update adds a value and returns the state after it: 1 in the zone and 0
+// out of it, or NaN and false if v is NaN.
+func (s *HysteresisStream) Update(v float64) (float64, bool) {
+	if math.IsNaN(v) {
+		return math.NaN(), false
+	}
+	s.seen++
+	above := s.enter > s.exit
+	switch {
+	case !s.in && ((above && v > s.enter) || (!above && v < s.enter)):
+		s.in = true
+	case s.in && ((above && v < s.exit) || (!above && v > s.exit)):
+		s.in = false
+	}
+	if s.in {
+		return 1, true
+	}
+	return 0, true
+}
+
+// In reports whether the value is in the zone.
+func (s *HysteresisStream) In() bool { return s.in }
+
+// Ready reports whether a defined value has been seen.
+func (s *HysteresisStream) Ready() bool { return s.seen > 0 }
+
+// Reset leaves the zone.
+func (s *HysteresisStream) Reset() { s.in, s.seen = false, 0 }
+
+// Lookback returns the number of warm-up values, which is zero.
+func (s *HysteresisStream) Lookback() int { return 0 }
+
+// BarsSince returns the number of bars since the last bar at which event was
+// nonzero, 0 at the event itself, or NaN before the first event. NaN event
+// values count as no event.
+func BarsSince(event []float64) []float64 {
+	out := make([]float64, len(event))
+	last := -1
+	for i, e := range event {
+		if e != 0 && !math.IsNaN(e) {
+			last = i
+		}
+		if last < 0 {
+			out[i] = math.NaN()
+			continue
+		}
+		out[i] = float64(i - last)
+	}
+	return out
+}
+
+// ValueWhen returns the value of x at the occurrence-th most recent bar at
+// which event was nonzero, counting the current bar, with 0 for the latest
+// event; for example, the close at the last crossing. Bars before enough
+// events are NaN, and NaN event values count as no event. It returns nil if
+// the lengths differ or occurrence is negative.
+func ValueWhen(event, x []float64, occurrence int) []float64 {
+	if len(event) != len(x) || occurrence < 0 {
+		return nil
+	}
+	out := make([]float64, len(x))
+	values := newRing(occurrence + 1)
+	for i, e := range event {
+		if e != 0 && !math.IsNaN(e) {
+			values.push(x[i])
+		}
+		if !values.full() {
+			out[i] = math.NaN()
+			continue
+		}
+		out[i] = values.at(0)
+	}
+	return out
+}
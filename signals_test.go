@@ -0,0 +1,67 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCross(t *testing.T) {
+	nan := math.NaN()
+	x := []float64{1, 2, 3, 3, 2, 1, nan, 3, 1}
+	y := []float64{2, 2, 2, 3, 3, 2, 2, 2, 2}
+	// Touching y (bars 1 and 3) is not a crossing, but leaving it is.
+	assertClose(t, "Cross", Cross(x, y), []float64{nan, 0, 1, 0, -1, 0, nan, nan, -1}, 0)
+	assertClose(t, "CrossAbove", CrossAbove(x, y), []float64{nan, 0, 1, 0, 0, 0, nan, nan, 0}, 0)
+	assertClose(t, "CrossBelow", CrossBelow(x, y), []float64{nan, 0, 0, 0, 1, 0, nan, nan, 1}, 0)
+	assertClose(t, "CrossLevel", CrossLevel(x, 2), []float64{nan, 0, 1, 0, 0, -1, nan, nan, -1}, 0)
+	assertClose(t, "CrossAboveLevel", CrossAboveLevel(x, 2.5), []float64{nan, 0, 1, 0, 0, 0, nan, nan, 0}, 0)
+	assertClose(t, "CrossBelowLevel", CrossBelowLevel(x, 2.5), []float64{nan, 0, 0, 0, 1, 0, nan, nan, 1}, 0)
+	if Cross(x, y[1:]) != nil {
+		t.Error("Cross of different lengths is not nil")
+	}
+
+	var s CrossStream
+	for pass := 0; pass < 2; pass++ {
+		for i := range x {
+			c, ok := s.Update(x[i], y[i])
+			if want := Cross(x, y)[i]; ok == math.IsNaN(want) || (ok && c != want) {
+				t.Errorf("pass %d: stream [%d] = %v, %v, want %v", pass, i, c, ok, want)
+			}
+		}
+		s.Reset()
+	}
+}
+
+func TestHysteresis(t *testing.T) {
+	nan := math.NaN()
+	// Enter above 2, leave below 0.5; noise around 2 does not toggle.
+	x := []float64{0, 1, 2.1, 1.9, 2.1, 1, nan, 0.6, 0.4, 1.9, 2.5}
+	state := Hysteresis(x, 2, 0.5)
+	assertClose(t, "above", state, []float64{0, 0, 1, 1, 1, 1, nan, 1, 0, 0, 1}, 0)
+	assertClose(t, "Transitions", Transitions(state), []float64{0, 0, 1, 0, 0, 0, nan, 0, -1, 0, 1}, 0)
+	// Mirrored thresholds define a zone below enter.
+	assertClose(t, "below", Hysteresis(negate(x), -2, -0.5), state, 0)
+	assertClose(t, "Transitions from in", Transitions([]float64{nan, 1, 1, 0}), []float64{nan, 1, 0, -1}, 0)
+
+	s := NewHysteresis(2, 0.5)
+	s.Update(3)
+	if !s.In() {
+		t.Error("not in the zone above enter")
+	}
+	s.Reset()
+	if s.In() || s.Ready() {
+		t.Error("Reset kept the state")
+	}
+}
+
+func TestBarsSinceAndValueWhen(t *testing.T) {
+	nan := math.NaN()
+	event := []float64{0, 1, 0, nan, -1, 0, 0, 1}
+	x := []float64{10, 11, 12, 13, 14, 15, 16, 17}
+	assertClose(t, "BarsSince", BarsSince(event), []float64{nan, 0, 1, 2, 0, 1, 2, 0}, 0)
+	assertClose(t, "ValueWhen 0", ValueWhen(event, x, 0), []float64{nan, 11, 11, 11, 14, 14, 14, 17}, 0)
+	assertClose(t, "ValueWhen 1", ValueWhen(event, x, 1), []float64{nan, nan, nan, nan, 11, 11, 11, 14}, 0)
+	if ValueWhen(event, x, -1) != nil || ValueWhen(event, x[1:], 0) != nil {
+		t.Error("ValueWhen of invalid input is not nil")
+	}
+}
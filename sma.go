@@ -1,21 +1,77 @@
 package indicators
 
+import "math"
+
+// SMA calculates the Simple Moving Average of data, with a running sum over
+// the window. Values before the first full window are NaN.
 func SMA(data []float64, period int) []float64 {
 	// Initialize the result slice
 	result := make([]float64, len(data))
+	if period <= 0 {
+		for i := range result {
+			result[i] = math.NaN()
+		}
+		return result
+	}
 
 	// Calculate the SMA
-	for i := 0; i < len(data); i++ {
-		if i < period-1 {
-			result[i] = 0.0 // Not enough data for SMA
-			continue
-		}
-		sum := 0.0
-		for j := 0; j < period; j++ {
-			sum += data[i-j]
-		}
-		result[i] = sum / float64(period)
+	sma := NewSMA(period)
+	for i, v := range data {
+		result[i], _ = sma.Update(v)
 	}
 
 	return result
 }
+
+// SMAChecked is like SMA but reports invalid input as an error.
+func SMAChecked(data []float64, period int) ([]float64, error) {
+	if err := firstError(
+		checkPeriod("SMA", "period", period, 1),
+		checkData("SMA", len(data), SMALookback(period)),
+	); err != nil {
+		return nil, err
+	}
+	return SMA(data, period), nil
+}
+
+// SMABars calculates SMA over the closes of b.
+func SMABars(b *Bars, period int) ([]float64, error) {
+	return SMAChecked(b.close, period)
+}
+
+// SMALookback returns the number of leading warm-up values of SMA.
+func SMALookback(period int) int {
+	return period - 1
+}
+
+// SMAStream is the streaming counterpart of SMA.
+type SMAStream struct {
+	period int
+	sum    *rollingSum
+}
+
+// NewSMA returns a streaming Simple Moving Average over period values.
+func NewSMA(period int) *SMAStream {
+	if period <= 0 {
+		panic("period must be greater than 0")
+	}
+	return &SMAStream{period: period, sum: newRollingSum(period)}
+}
+
+// Update adds v and returns the current SMA, or NaN during warm-up.
+func (s *SMAStream) Update(v float64) (float64, bool) {
+	s.sum.push(v)
+	if !s.sum.full() {
+		return math.NaN(), false // Not enough data for SMA
+	}
+	return s.sum.value() / float64(s.period), true
+}
+
+// Ready reports whether a full window has been seen.
+func (s *SMAStream) Ready() bool { return s.sum.full() }
+
+// Reset clears the window.
+func (s *SMAStream) Reset() { s.sum.reset() }
+
+// Lookback returns the number of warm-up bars.
+func (s *SMAStream) Lookback() int { return s.period - 1 }
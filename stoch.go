@@ -12,50 +12,166 @@ type StochasticResult struct {
 
 // StochasticOscillator calculates the %K and %D series
 func StochasticOscillator(high, low, close []float64, window, smoothWindow int, fillNa bool) StochasticResult {
+	return StochasticOscillatorMA(high, low, close, window, smoothWindow, fillNa, MASMA)
+}
+
+// StochasticOscillatorMA is like StochasticOscillator with a signal line
+// smoothed by an average of type signal. The signal line is NaN until both
+// %K and the average have left their warm-up.
+func StochasticOscillatorMA(high, low, close []float64, window, smoothWindow int, fillNa bool, signal MAType) StochasticResult {
 	n := len(close)
 	stochK := make([]float64, n)
+	stochKSignal := make([]float64, n)
+	if window <= 0 || smoothWindow < signal.minPeriod() {
+		for i := range stochK {
+			stochK[i], stochKSignal[i] = math.NaN(), math.NaN()
+		}
+		return StochasticResult{StochK: stochK, StochKSignal: stochKSignal}
+	}
 
+	s := NewStochasticOscillatorMA(window, smoothWindow, fillNa, signal)
 	for i := 0; i < n; i++ {
-		if i+1 < window {
-			stochK[i] = math.NaN()
-			continue
-		}
-		lowMin := math.MaxFloat64
-		highMax := -math.MaxFloat64
-		for j := i + 1 - window; j <= i; j++ {
-			if low[j] < lowMin {
-				lowMin = low[j]
-			}
-			if high[j] > highMax {
-				highMax = high[j]
-			}
-		}
+		stochK[i], stochKSignal[i], _ = s.Update(high[i], low[i], close[i])
+	}
+
+	return StochasticResult{
+		StochK:       stochK,
+		StochKSignal: stochKSignal,
+	}
+}
+
+// StochasticOscillatorChecked is like StochasticOscillator but reports
+// invalid input as an error.
+func StochasticOscillatorChecked(high, low, close []float64, window, smoothWindow int, fillNa bool) (StochasticResult, error) {
+	if err := firstError(
+		checkLengths("StochasticOscillator", len(close), len(high), len(low)),
+		checkPeriod("StochasticOscillator", "window", window, 1),
+		checkPeriod("StochasticOscillator", "smoothWindow", smoothWindow, 1),
+		checkData("StochasticOscillator", len(close), StochasticOscillatorLookback(window, smoothWindow)),
+	); err != nil {
+		return StochasticResult{}, err
+	}
+	return StochasticOscillator(high, low, close, window, smoothWindow, fillNa), nil
+}
+
+// StochasticOscillatorMAChecked is like StochasticOscillatorMA but reports
+// invalid input as an error.
+func StochasticOscillatorMAChecked(high, low, close []float64, window, smoothWindow int, fillNa bool, signal MAType) (StochasticResult, error) {
+	if err := firstError(
+		checkLengths("StochasticOscillator", len(close), len(high), len(low)),
+		checkPeriod("StochasticOscillator", "window", window, 1),
+		checkMA("StochasticOscillator", "smoothWindow", smoothWindow, signal),
+		checkData("StochasticOscillator", len(close), StochasticOscillatorMALookback(window, smoothWindow, signal)),
+	); err != nil {
+		return StochasticResult{}, err
+	}
+	return StochasticOscillatorMA(high, low, close, window, smoothWindow, fillNa, signal), nil
+}
+
+// StochasticOscillatorBars calculates StochasticOscillator over b.
+func StochasticOscillatorBars(b *Bars, window, smoothWindow int, fillNa bool) (StochasticResult, error) {
+	return StochasticOscillatorChecked(b.high, b.low, b.close, window, smoothWindow, fillNa)
+}
+
+// StochasticOscillatorMABars calculates StochasticOscillatorMA over b.
+func StochasticOscillatorMABars(b *Bars, window, smoothWindow int, fillNa bool, signal MAType) (StochasticResult, error) {
+	return StochasticOscillatorMAChecked(b.high, b.low, b.close, window, smoothWindow, fillNa, signal)
+}
+
+// Columns returns %K and its signal line as named columns.
+func (r StochasticResult) Columns() []Column {
+	return []Column{
+		{Name: "stoch_k", Values: r.StochK},
+		{Name: "stoch_k_signal", Values: r.StochKSignal},
+	}
+}
+
+// StochasticOscillatorLookback returns the number of leading warm-up values
+// of the StochasticOscillator signal line. StochK warms up window-1 bars.
+func StochasticOscillatorLookback(window, smoothWindow int) int {
+	return StochasticOscillatorMALookback(window, smoothWindow, MASMA)
+}
+
+// StochasticOscillatorMALookback returns the number of leading warm-up
+// values of the StochasticOscillatorMA signal line.
+func StochasticOscillatorMALookback(window, smoothWindow int, signal MAType) int {
+	return window - 1 + MALookback(smoothWindow, signal)
+}
+
+// StochasticStream is the streaming counterpart of StochasticOscillator.
+type StochasticStream struct {
+	window       int
+	smoothWindow int
+	highs        *rollingExtreme
+	lows         *rollingExtreme
+	signal       *MAStream
+	fillNa       bool
+}
+
+// NewStochasticOscillator returns a streaming Stochastic Oscillator.
+func NewStochasticOscillator(window, smoothWindow int, fillNa bool) *StochasticStream {
+	return NewStochasticOscillatorMA(window, smoothWindow, fillNa, MASMA)
+}
+
+// NewStochasticOscillatorMA returns a streaming Stochastic Oscillator with a
+// signal line smoothed by an average of type signal.
+func NewStochasticOscillatorMA(window, smoothWindow int, fillNa bool, signal MAType) *StochasticStream {
+	if window <= 0 {
+		panic("window must be greater than 0")
+	}
+	return &StochasticStream{
+		window:       window,
+		smoothWindow: smoothWindow,
+		highs:        newRollingMax(window),
+		lows:         newRollingMin(window),
+		signal:       NewMA(signal, smoothWindow),
+		fillNa:       fillNa,
+	}
+}
+
+// Update adds a bar and returns the current %K and %D values.
+func (s *StochasticStream) Update(high, low, close float64) (stochK, stochKSignal float64, ready bool) {
+	s.highs.push(high)
+	s.lows.push(low)
+
+	stochK, stochKSignal = math.NaN(), math.NaN()
+	if s.highs.full() {
+		lowMin := s.lows.value()
+		highMax := s.highs.value()
 		denom := highMax - lowMin
 		if denom == 0 {
-			stochK[i] = 0
+			stochK = 0
 		} else {
-			stochK[i] = 100 * (close[i] - lowMin) / denom
+			stochK = 100 * (close - lowMin) / denom
+		}
+		if v, ok := s.signal.Update(stochK); ok {
+			stochKSignal = v
 		}
 	}
 
-	stochKSignal := SMA(stochK, smoothWindow)
-
 	// Optional: fill NaN values
-	if fillNa {
-		for i := range stochK {
-			if math.IsNaN(stochK[i]) {
-				stochK[i] = 50
-			}
+	if s.fillNa {
+		if math.IsNaN(stochK) {
+			stochK = 50
 		}
-		for i := range stochKSignal {
-			if math.IsNaN(stochKSignal[i]) {
-				stochKSignal[i] = 50
-			}
+		if math.IsNaN(stochKSignal) {
+			stochKSignal = 50
 		}
 	}
+	return stochK, stochKSignal, s.Ready()
+}
 
-	return StochasticResult{
-		StochK:       stochK,
-		StochKSignal: stochKSignal,
-	}
+// Ready reports whether both %K and %D have left their warm-up period.
+func (s *StochasticStream) Ready() bool { return s.signal.Ready() }
+
+// Reset clears both windows and the signal line.
+func (s *StochasticStream) Reset() {
+	s.highs.reset()
+	s.lows.reset()
+	s.signal.Reset()
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *StochasticStream) Lookback() int {
+	return StochasticOscillatorMALookback(s.window, s.smoothWindow, s.signal.Type())
 }
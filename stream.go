@@ -0,0 +1,60 @@
+package indicators
+
+// Indicator is the common interface implemented by every streaming indicator.
+// Streaming indicators consume one bar at a time through a type specific
+// Update method and produce the same values as their batch counterparts,
+// including NaN during the warm-up period.
+type Indicator interface {
+	// Ready reports whether enough bars have been consumed to emit a value.
+	Ready() bool
+	// Reset clears all internal state so the indicator can be reused.
+	Reset()
+	// Lookback returns the number of leading bars whose values are part of
+	// the warm-up period, matching the Lookback function of the batch
+	// indicator.
+	Lookback() int
+}
+
+// SeriesIndicator is an Indicator fed with a single value per bar.
+type SeriesIndicator interface {
+	Indicator
+	// Update consumes the next value and returns the indicator value for it
+	// together with whether the indicator is past its warm-up period.
+	Update(v float64) (float64, bool)
+}
+
+// ring is a fixed capacity FIFO of the most recent values.
+type ring struct {
+	buf   []float64
+	head  int // index of the oldest value
+	count int
+}
+
+func newRing(size int) *ring {
+	return &ring{buf: make([]float64, size)}
+}
+
+// push appends v, evicting the oldest value once the ring is full.
+func (r *ring) push(v float64) {
+	if r.count < len(r.buf) {
+		r.buf[(r.head+r.count)%len(r.buf)] = v
+		r.count++
+		return
+	}
+	r.buf[r.head] = v
+	r.head = (r.head + 1) % len(r.buf)
+}
+
+// at returns the i-th value counting from the oldest.
+func (r *ring) at(i int) float64 {
+	return r.buf[(r.head+i)%len(r.buf)]
+}
+
+// full reports whether the ring holds as many values as its capacity.
+func (r *ring) full() bool {
+	return r.count == len(r.buf)
+}
+
+func (r *ring) reset() {
+	r.head, r.count = 0, 0
+}
@@ -0,0 +1,149 @@
+package indicators
+
+import "testing"
+
+// streamed feeds the bars 0..n-1 to update and collects its results.
+func streamed(n int, update func(i int) float64) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = update(i)
+	}
+	return out
+}
+
+// TestStreamsMatchBatch checks that every streaming indicator reproduces
+// its batch function bit for bit, warm-up NaNs included.
+func TestStreamsMatchBatch(t *testing.T) {
+	const n = 400
+	open, high, low, close, volume := testOHLCV(n)
+
+	kvo := KVO(high, low, close, volume)
+	stoch := StochasticOscillator(high, low, close, 14, 3, false)
+	vortex := Vortex(high, low, close, 14)
+	vwMACD, vwSignal, vwHist := VolumeWeightedMACD(close, volume, 12, 26, 9)
+	pivot, s1, _, _, r2 := Pivot(high, low, close)
+	lower, upper, mid := Donchian(high, low, 10, 20)
+
+	kvoS, stochS, vortexS, vwS := NewKVO(), NewStochasticOscillator(14, 3, false), NewVortex(14), NewVolumeWeightedMACD(12, 26, 9)
+	var kvoSignal, stochSignal, viMinus, vwSig, vwH []float64
+	pivotS, donchianS := NewPivot(), NewDonchian(10, 20)
+	var ps1, pr2, dUpper, dMid []float64
+
+	for _, c := range []struct {
+		name   string
+		batch  []float64
+		update func(i int) float64
+	}{
+		{"SMA", SMA(close, 20), series(NewSMA(20), close)},
+		{"EMA", EMA(close, 12), func(s *EMAStream) func(int) float64 {
+			return func(i int) float64 { v, _ := s.Update(close[i]); return v }
+		}(NewEMA(12))},
+		{"HMA", HMA(close, 16), series(NewHMA(16), close)},
+		{"RollingStd", RollingStd(close, 20), series(NewRollingStd(20), close)},
+		{"ZScore", ZScore(close, 20), series(NewZScore(20), close)},
+		{"BbandsPercent", BbandsPercent(close), series(NewBbandsPercent(), close)},
+		{"Disp14", Disp14(close), series(NewDisp14(), close)},
+		{"ElderBull", ElderBull(close), series(NewElderBull(), close)},
+		{"ElderBear", ElderBear(close), series(NewElderBear(), close)},
+		{"ATRSMA", ATRSMA(high, low, close, 14), func(s *ATRSMAStream) func(int) float64 {
+			return func(i int) float64 { v, _ := s.Update(high[i], low[i], close[i]); return v }
+		}(NewATRSMA(14))},
+		{"CMF", CMF(high, low, close, volume, 21), func(s *CMFStream) func(int) float64 {
+			return func(i int) float64 { v, _ := s.Update(high[i], low[i], close[i], volume[i]); return v }
+		}(NewCMF(21))},
+		{"RollingVWAP", RollingVWAP(high, low, close, volume, 20), func(s *RollingVWAPStream) func(int) float64 {
+			return func(i int) float64 { v, _ := s.Update(high[i], low[i], close[i], volume[i]); return v }
+		}(NewRollingVWAP(20))},
+		{"EOM", EOM(high, low, volume, 14), func(s *EOMStream) func(int) float64 {
+			return func(i int) float64 { v, _ := s.Update(high[i], low[i], volume[i]); return v }
+		}(NewEOM())},
+		{"ForceIndex", ForceIndex(close, volume, 13), func(s *ForceIndexStream) func(int) float64 {
+			return func(i int) float64 { v, _ := s.Update(close[i], volume[i]); return v }
+		}(NewForceIndex(13))},
+		{"VWRSI", VWRSI(close, volume, 14), func(s *VWRSIStream) func(int) float64 {
+			return func(i int) float64 { v, _ := s.Update(close[i], volume[i]); return v }
+		}(NewVWRSI(14))},
+		{"PVT", PVT(close, volume), func(s *PVTStream) func(int) float64 {
+			return func(i int) float64 { v, _ := s.Update(close[i], volume[i]); return v }
+		}(NewPVT())},
+		{"InstBlockTrade", InstBlockTrade(open, close, volume), func(s *InstBlockTradeStream) func(int) float64 {
+			return func(i int) float64 { v, _ := s.Update(open[i], close[i], volume[i]); return v }
+		}(NewInstBlockTrade())},
+		{"KVO", kvo.KVO, func(i int) float64 {
+			v, sig, _ := kvoS.Update(high[i], low[i], close[i], volume[i])
+			kvoSignal = append(kvoSignal, sig)
+			return v
+		}},
+		{"Stochastic", stoch.StochK, func(i int) float64 {
+			k, d, _ := stochS.Update(high[i], low[i], close[i])
+			stochSignal = append(stochSignal, d)
+			return k
+		}},
+		{"Vortex", vortex.VIPlus, func(i int) float64 {
+			plus, minus, _ := vortexS.Update(high[i], low[i], close[i])
+			viMinus = append(viMinus, minus)
+			return plus
+		}},
+		{"VolumeWeightedMACD", vwMACD, func(i int) float64 {
+			m, sig, h, _ := vwS.Update(close[i], volume[i])
+			vwSig, vwH = append(vwSig, sig), append(vwH, h)
+			return m
+		}},
+		{"Pivot", pivot, func(i int) float64 {
+			p, s, _, _, r := pivotS.Update(high[i], low[i], close[i])
+			ps1, pr2 = append(ps1, s), append(pr2, r)
+			return p
+		}},
+		{"Donchian", lower, func(i int) float64 {
+			l, u, m, _ := donchianS.Update(high[i], low[i])
+			dUpper, dMid = append(dUpper, u), append(dMid, m)
+			return l
+		}},
+	} {
+		assertClose(t, c.name, streamed(n, c.update), c.batch, 0)
+	}
+	assertClose(t, "KVO signal", kvoSignal, kvo.KVOSignal, 0)
+	assertClose(t, "Stochastic signal", stochSignal, stoch.StochKSignal, 0)
+	assertClose(t, "Vortex VI-", viMinus, vortex.VIMinus, 0)
+	assertClose(t, "VolumeWeightedMACD signal", vwSig, vwSignal, 0)
+	assertClose(t, "VolumeWeightedMACD hist", vwH, vwHist, 0)
+	assertClose(t, "Pivot S1", ps1, s1, 0)
+	assertClose(t, "Pivot R2", pr2, r2, 0)
+	assertClose(t, "Donchian upper", dUpper, upper, 0)
+	assertClose(t, "Donchian mid", dMid, mid, 0)
+}
+
+func series(s SeriesIndicator, data []float64) func(int) float64 {
+	return func(i int) float64 {
+		v, _ := s.Update(data[i])
+		return v
+	}
+}
+
+func TestStreamResetAndLookback(t *testing.T) {
+	_, _, _, close, _ := testOHLCV(100)
+	for name, s := range map[string]SeriesIndicator{
+		"SMA":        NewSMA(10),
+		"HMA":        NewHMA(9),
+		"RollingStd": NewRollingStd(10),
+		"ZScore":     NewZScore(10),
+		"MA":         NewMA(MATEMA, 10),
+	} {
+		first := streamed(len(close), series(s, close))
+		ready := -1
+		for i, v := range first {
+			if v == v {
+				ready = i
+				break
+			}
+		}
+		if ready != s.Lookback() {
+			t.Errorf("%s: first value at %d, Lookback %d", name, ready, s.Lookback())
+		}
+		s.Reset()
+		if s.Ready() {
+			t.Errorf("%s: Ready after Reset", name)
+		}
+		assertClose(t, name+" after Reset", streamed(len(close), series(s, close)), first, 0)
+	}
+}
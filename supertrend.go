@@ -1,16 +1,23 @@
 package indicators
 
 import (
+	"fmt"
 	"math"
-
-	cgotalib "github.com/blazer-org/cgo-talib"
 )
 
+// SupertrendResult holds the Supertrend series. Trend, Long, Short and
+// Distance are NaN during the warm-up of the ATR, when Direction holds the
+// initial bullish assumption.
 type SupertrendResult struct {
 	Trend     []float64
 	Direction []int
 	Long      []float64
 	Short     []float64
+	// Distance is the close minus the trend line, positive above it.
+	Distance []float64
+	// Flips are the indices of the bars where Direction changes, leaving out
+	// the warm-up period.
+	Flips []int
 }
 
 // SuperTrend calculates the Super Trend indicator for a given time series
@@ -20,6 +27,24 @@ func Supertrend(high, low, close []float64, length int, multiplier float64) *Sup
 		return nil
 	}
 
+	// Compute hl2 (average of high and low)
+	hl2 := make([]float64, n)
+	for i := 0; i < n; i++ {
+		hl2[i] = (high[i] + low[i]) / 2.0
+	}
+	return SupertrendWith(hl2, high, low, close, length, multiplier, ATRWilder)
+}
+
+// SupertrendWith calculates Supertrend with bands around source, usually a
+// PriceSource series, and the ATR smoothed with method. Unknown methods fall
+// back to ATRWilder.
+func SupertrendWith(source, high, low, close []float64, length int, multiplier float64, method ATRMethod) *SupertrendResult {
+	n := len(close)
+	if n == 0 || len(high) != n || len(low) != n || len(source) != n {
+		return nil
+	}
+	method = method.orWilder()
+
 	// Set default parameters if needed
 	if length <= 0 {
 		length = 7
@@ -27,38 +52,44 @@ func Supertrend(high, low, close []float64, length int, multiplier float64) *Sup
 	if multiplier <= 0 {
 		multiplier = 3.0
 	}
+	lookback := method.lookback(length)
 
 	// Initialize output slices
 	direction := make([]int, n)
 	trend := make([]float64, n)
 	long := make([]float64, n)
 	short := make([]float64, n)
+	distance := make([]float64, n)
 	for i := 0; i < n; i++ {
 		long[i], short[i] = math.NaN(), math.NaN()
 	}
+	distance[0] = math.NaN()
 	for i := range direction {
 		direction[i] = 1 // Start with bullish assumption
 	}
 
-	// Compute hl2 (average of high and low)
-	hl2 := make([]float64, n)
-	for i := 0; i < n; i++ {
-		hl2[i] = (high[i] + low[i]) / 2.0
-	}
-
 	// Compute ATR
-	atr := cgotalib.Atr(high, low, close, int32(length))
-	// atr := ATR(high, low, close, length)
+	var atr []float64
+	if method == ATRWilder {
+		atr = taAtr(high, low, close, int32(length))
+	} else {
+		atr = make([]float64, n)
+		k := newATRKernel(method, length)
+		for i := range atr {
+			atr[i], _ = k.update(high[i], low[i], close[i])
+		}
+	}
 
 	// Compute upperband and lowerband
 	upperband := make([]float64, n)
 	lowerband := make([]float64, n)
 	for i := 0; i < n; i++ {
-		upperband[i] = hl2[i] + multiplier*atr[i]
-		lowerband[i] = hl2[i] - multiplier*atr[i]
+		upperband[i] = source[i] + multiplier*atr[i]
+		lowerband[i] = source[i] - multiplier*atr[i]
 	}
 
 	// Main Supertrend logic
+	var flips []int
 	for i := 1; i < n; i++ {
 		if close[i] > upperband[i-1] {
 			direction[i] = 1
@@ -81,6 +112,13 @@ func Supertrend(high, low, close []float64, length int, multiplier float64) *Sup
 			trend[i] = upperband[i]
 			short[i] = upperband[i]
 		}
+		distance[i] = close[i] - trend[i]
+		if i-1 >= lookback && direction[i] != direction[i-1] {
+			flips = append(flips, i)
+		}
+	}
+	for i := 0; i < n && i < lookback; i++ {
+		trend[i], long[i], short[i], distance[i] = math.NaN(), math.NaN(), math.NaN(), math.NaN()
 	}
 
 	return &SupertrendResult{
@@ -88,5 +126,207 @@ func Supertrend(high, low, close []float64, length int, multiplier float64) *Sup
 		Direction: direction,
 		Long:      long,
 		Short:     short,
+		Distance:  distance,
+		Flips:     flips,
+	}
+}
+
+// SupertrendPoint holds the Supertrend values for a single bar.
+type SupertrendPoint struct {
+	Trend     float64
+	Direction int
+	Long      float64
+	Short     float64
+	Distance  float64
+	// Flip reports whether Direction changed at this bar after warm-up.
+	Flip bool
+}
+
+// SupertrendChecked is like Supertrend but reports invalid input as an error
+// instead of returning nil. The length must be positive; a non-positive
+// multiplier still selects the default.
+func SupertrendChecked(high, low, close []float64, length int, multiplier float64) (*SupertrendResult, error) {
+	if err := firstError(
+		checkLengths("Supertrend", len(close), len(high), len(low)),
+		checkPeriod("Supertrend", "length", length, 1),
+		checkData("Supertrend", len(close), SupertrendLookback(length)),
+	); err != nil {
+		return nil, err
+	}
+	return Supertrend(high, low, close, length, multiplier), nil
+}
+
+// SupertrendWithChecked is like SupertrendWith but reports invalid input as
+// an error instead of returning nil. An unknown method is reported as
+// ErrInvalidParam.
+func SupertrendWithChecked(source, high, low, close []float64, length int, multiplier float64, method ATRMethod) (*SupertrendResult, error) {
+	if method < ATRWilder || method > ATRExponential {
+		return nil, &InputError{Func: "Supertrend", Err: ErrInvalidParam, Detail: fmt.Sprintf("ATR method %v", method)}
+	}
+	if err := firstError(
+		checkLengths("Supertrend", len(close), len(high), len(low), len(source)),
+		checkPeriod("Supertrend", "length", length, 1),
+		checkData("Supertrend", len(close), SupertrendWithLookback(length, method)),
+	); err != nil {
+		return nil, err
+	}
+	return SupertrendWith(source, high, low, close, length, multiplier, method), nil
+}
+
+// SupertrendBars calculates Supertrend over b.
+func SupertrendBars(b *Bars, length int, multiplier float64) (*SupertrendResult, error) {
+	return SupertrendChecked(b.high, b.low, b.close, length, multiplier)
+}
+
+// SupertrendWithBars calculates SupertrendWith over b with bands around the
+// source price.
+func SupertrendWithBars(b *Bars, source PriceSource, length int, multiplier float64, method ATRMethod) (*SupertrendResult, error) {
+	src, err := source.Series(b)
+	if err != nil {
+		return nil, err
+	}
+	return SupertrendWithChecked(src, b.high, b.low, b.close, length, multiplier, method)
+}
+
+// Columns returns the Supertrend series as named columns, with the direction
+// converted to float64 and the flips as 1 at each flip bar and 0 elsewhere.
+// Both are NaN where Trend is, during warm-up.
+func (r *SupertrendResult) Columns() []Column {
+	direction := make([]float64, len(r.Direction))
+	flips := make([]float64, len(r.Direction))
+	for i, d := range r.Direction {
+		direction[i] = float64(d)
+		if math.IsNaN(r.Trend[i]) {
+			direction[i], flips[i] = math.NaN(), math.NaN()
+		}
+	}
+	for _, i := range r.Flips {
+		flips[i] = 1
+	}
+	return []Column{
+		{Name: "supertrend", Values: r.Trend},
+		{Name: "supertrend_direction", Values: direction},
+		{Name: "supertrend_long", Values: r.Long},
+		{Name: "supertrend_short", Values: r.Short},
+		{Name: "supertrend_distance", Values: r.Distance},
+		{Name: "supertrend_flip", Values: flips},
 	}
 }
+
+// SupertrendLookback returns the number of leading warm-up values of
+// Supertrend, which is the lookback of its ATR.
+func SupertrendLookback(length int) int {
+	return SupertrendWithLookback(length, ATRWilder)
+}
+
+// SupertrendWithLookback returns the number of leading warm-up values of
+// SupertrendWith, which is the lookback of its ATR.
+func SupertrendWithLookback(length int, method ATRMethod) int {
+	if length <= 0 {
+		length = 7
+	}
+	return method.lookback(length)
+}
+
+// SupertrendStream is the streaming counterpart of Supertrend.
+type SupertrendStream struct {
+	multiplier float64
+	source     PriceSource
+	lookback   int
+	atr        atrKernel
+
+	prevUpper, prevLower float64
+	prevDirection        int
+	seen                 int
+}
+
+// NewSupertrend returns a streaming Supertrend. Non-positive parameters
+// default to a length of 7 and a multiplier of 3 as in Supertrend.
+func NewSupertrend(length int, multiplier float64) *SupertrendStream {
+	return NewSupertrendWith(SourceHL2, length, multiplier, ATRWilder)
+}
+
+// NewSupertrendWith returns a streaming SupertrendWith with bands around the
+// source price. Sources reading the open must be fed with UpdateOHLC, and
+// unknown methods fall back to ATRWilder.
+func NewSupertrendWith(source PriceSource, length int, multiplier float64, method ATRMethod) *SupertrendStream {
+	if length <= 0 {
+		length = 7
+	}
+	if multiplier <= 0 {
+		multiplier = 3.0
+	}
+	return &SupertrendStream{
+		multiplier: multiplier,
+		source:     source,
+		lookback:   method.lookback(length),
+		atr:        newATRKernel(method, length),
+	}
+}
+
+// Update adds a bar and returns the Supertrend values for it, which are NaN
+// during warm-up as in SupertrendResult.
+func (s *SupertrendStream) Update(high, low, close float64) (SupertrendPoint, bool) {
+	return s.UpdateOHLC(math.NaN(), high, low, close)
+}
+
+// UpdateOHLC is like Update for sources that read the open.
+func (s *SupertrendStream) UpdateOHLC(open, high, low, close float64) (SupertrendPoint, bool) {
+	return s.updateSource(s.source.Value(open, high, low, close), high, low, close)
+}
+
+// updateSource adds a bar with the source price src.
+func (s *SupertrendStream) updateSource(src, high, low, close float64) (SupertrendPoint, bool) {
+	atr, ready := s.atr.update(high, low, close)
+	upperband := src + s.multiplier*atr
+	lowerband := src - s.multiplier*atr
+
+	p := SupertrendPoint{Direction: 1, Long: math.NaN(), Short: math.NaN(), Distance: math.NaN()}
+	if s.seen > 0 {
+		if close > s.prevUpper {
+			p.Direction = 1
+		} else if close < s.prevLower {
+			p.Direction = -1
+		} else {
+			p.Direction = s.prevDirection
+			if p.Direction > 0 && lowerband < s.prevLower {
+				lowerband = s.prevLower
+			}
+			if p.Direction < 0 && upperband > s.prevUpper {
+				upperband = s.prevUpper
+			}
+		}
+
+		if p.Direction > 0 {
+			p.Trend = lowerband
+			p.Long = lowerband
+		} else {
+			p.Trend = upperband
+			p.Short = upperband
+		}
+		p.Distance = close - p.Trend
+		p.Flip = s.seen-1 >= s.lookback && p.Direction != s.prevDirection
+	}
+
+	s.prevUpper, s.prevLower = upperband, lowerband
+	s.prevDirection = p.Direction
+	s.seen++
+	if !ready {
+		p.Trend, p.Long, p.Short, p.Distance = math.NaN(), math.NaN(), math.NaN(), math.NaN()
+	}
+	return p, ready
+}
+
+// Ready reports whether the ATR has left its warm-up period.
+func (s *SupertrendStream) Ready() bool { return s.seen > s.lookback }
+
+// Reset clears the ATR and band state.
+func (s *SupertrendStream) Reset() {
+	s.atr.reset()
+	s.prevUpper, s.prevLower = 0, 0
+	s.prevDirection = 0
+	s.seen = 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *SupertrendStream) Lookback() int { return s.lookback }
@@ -0,0 +1,103 @@
+package indicators
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSupertrendFlips(t *testing.T) {
+	// A rise, a fall and a rise again flip the direction twice.
+	var high, low, close []float64
+	price := 100.0
+	for i := 0; i < 120; i++ {
+		switch {
+		case i < 40:
+			price += 1
+		case i < 80:
+			price -= 1
+		default:
+			price += 1
+		}
+		high = append(high, price+0.5)
+		low = append(low, price-0.5)
+		close = append(close, price)
+	}
+	r := Supertrend(high, low, close, 7, 3)
+	if len(r.Flips) != 2 {
+		t.Fatalf("Flips = %v, want two", r.Flips)
+	}
+	if f := r.Flips[0]; f <= 40 || f > 50 || r.Direction[f] != -1 {
+		t.Errorf("first flip at %d to %d, want a flip down shortly after bar 40", f, r.Direction[f])
+	}
+	if f := r.Flips[1]; f <= 80 || f > 90 || r.Direction[f] != 1 {
+		t.Errorf("second flip at %d to %d, want a flip up shortly after bar 80", f, r.Direction[f])
+	}
+	for i := SupertrendLookback(7); i < len(close); i++ {
+		if r.Direction[i] > 0 && (math.IsNaN(r.Long[i]) || r.Trend[i] > close[i]) {
+			t.Fatalf("bar %d: uptrend line %v above close %v", i, r.Trend[i], close[i])
+		}
+		if r.Direction[i] < 0 && (math.IsNaN(r.Short[i]) || r.Trend[i] < close[i]) {
+			t.Fatalf("bar %d: downtrend line %v below close %v", i, r.Trend[i], close[i])
+		}
+	}
+}
+
+func TestSupertrendStreamMatchesBatch(t *testing.T) {
+	open, high, low, close, _ := testOHLCV(300)
+	for _, method := range []ATRMethod{ATRWilder, ATRExponential} {
+		src := make([]float64, len(close))
+		for i := range src {
+			src[i] = SourceHL2.Value(open[i], high[i], low[i], close[i])
+		}
+		want := SupertrendWith(src, high, low, close, 10, 2, method)
+		s := NewSupertrendWith(SourceHL2, 10, 2, method)
+		var flips []int
+		trend := make([]float64, len(close))
+		for i := range close {
+			p, _ := s.UpdateOHLC(open[i], high[i], low[i], close[i])
+			trend[i] = p.Trend
+			if p.Flip {
+				flips = append(flips, i)
+			}
+		}
+		lb := SupertrendWithLookback(10, method)
+		assertClose(t, method.String(), trend[lb:], want.Trend[lb:], 1e-9)
+		if !slices.Equal(flips, want.Flips) {
+			t.Errorf("%v: stream flips %v, want %v", method, flips, want.Flips)
+		}
+	}
+}
+
+func TestSupertrendUnknownMethodIsWilder(t *testing.T) {
+	_, high, low, close, _ := testOHLCV(100)
+	want := SupertrendWith(close, high, low, close, 7, 3, ATRWilder)
+	got := SupertrendWith(close, high, low, close, 7, 3, ATRMethod(99))
+	assertClose(t, "trend", got.Trend, want.Trend, 0)
+	if !slices.Equal(got.Flips, want.Flips) {
+		t.Errorf("flips %v, want %v", got.Flips, want.Flips)
+	}
+	if lb := SupertrendWithLookback(7, ATRMethod(99)); lb != SupertrendLookback(7) {
+		t.Errorf("lookback %d, want %d", lb, SupertrendLookback(7))
+	}
+	if lb := NewSupertrendWith(SourceClose, 7, 3, ATRMethod(99)).Lookback(); lb != SupertrendLookback(7) {
+		t.Errorf("stream lookback %d, want %d", lb, SupertrendLookback(7))
+	}
+}
+
+func TestSupertrendCheckedErrors(t *testing.T) {
+	_, high, low, close, _ := testOHLCV(50)
+	if _, err := SupertrendChecked(high, low, close, 0, 3); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("length 0: err = %v, want ErrInvalidPeriod", err)
+	}
+	if _, err := SupertrendWithChecked(close, high, low, close, 7, 3, ATRMethod(99)); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("unknown method: err = %v, want ErrInvalidParam", err)
+	}
+	if err := SupertrendInto(new(Workspace), new(SupertrendResult), high, low, close, -1, 3); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("Into length -1: err = %v, want ErrInvalidPeriod", err)
+	}
+	if _, err := SupertrendChecked(high, low[1:], close, 7, 3); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("short low: err = %v, want ErrLengthMismatch", err)
+	}
+}
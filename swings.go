@@ -0,0 +1,196 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+)
+
+// Swing is a swing high or low. A swing is only known some bars after it
+// occurred; Confirmed is the first bar at which it can be acted on, so
+// backtests should use it rather than Index.
+type Swing struct {
+	Index     int // bar of the high or low
+	Confirmed int // bar at which the swing became known
+	Price     float64
+	High      bool // a swing high, else a swing low
+}
+
+// SwingPivots finds the pivot highs and lows with left and right strength:
+// highs above the left highs before them and not exceeded by the right highs
+// after them, and likewise for lows. Of equal highs or lows the first is the
+// pivot. Each pivot is confirmed right bars after it. The swings are in order
+// of Index, with a high before a low on the same bar.
+func SwingPivots(high, low []float64, left, right int) []Swing {
+	if len(high) != len(low) || left < 0 || right < 0 {
+		return nil
+	}
+	var swings []Swing
+	s := NewSwingPivots(left, right)
+	for i := range high {
+		found, _ := s.Update(high[i], low[i])
+		swings = append(swings, found...)
+	}
+	return swings
+}
+
+// PivotHighs returns the pivot highs of SwingPivots over high alone.
+func PivotHighs(high []float64, left, right int) []Swing {
+	return filterSwings(SwingPivots(high, high, left, right), true)
+}
+
+// PivotLows returns the pivot lows of SwingPivots over low alone.
+func PivotLows(low []float64, left, right int) []Swing {
+	return filterSwings(SwingPivots(low, low, left, right), false)
+}
+
+func filterSwings(swings []Swing, high bool) []Swing {
+	out := swings[:0]
+	for _, s := range swings {
+		if s.High == high {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Fractals finds the Williams fractals of high and low: pivots with a
+// strength of two bars on either side.
+func Fractals(high, low []float64) []Swing {
+	return SwingPivots(high, low, 2, 2)
+}
+
+// SwingPivotsChecked is like SwingPivots but reports invalid input as an
+// error.
+func SwingPivotsChecked(high, low []float64, left, right int) ([]Swing, error) {
+	if left < 0 || right < 0 {
+		return nil, &InputError{Func: "SwingPivots", Err: ErrInvalidPeriod, Detail: fmt.Sprintf("left=%d, right=%d, must not be negative", left, right)}
+	}
+	if err := firstError(
+		checkLengths("SwingPivots", len(high), len(low)),
+		checkData("SwingPivots", len(high), SwingPivotsLookback(left, right)),
+	); err != nil {
+		return nil, err
+	}
+	return SwingPivots(high, low, left, right), nil
+}
+
+// SwingPivotsBars calculates SwingPivots over b.
+func SwingPivotsBars(b *Bars, left, right int) ([]Swing, error) {
+	return SwingPivotsChecked(b.high, b.low, left, right)
+}
+
+// FractalsBars calculates Fractals over b.
+func FractalsBars(b *Bars) ([]Swing, error) {
+	return SwingPivotsChecked(b.high, b.low, 2, 2)
+}
+
+// SwingPivotsLookback returns the number of bars before the first bar at
+// which a pivot can be confirmed.
+func SwingPivotsLookback(left, right int) int {
+	return left + right
+}
+
+// SwingSeries spreads swings over n bars: highs holds the price of each
+// swing high and lows that of each swing low, at its Confirmed bar or, if
+// atConfirmation is false, at its Index. Other bars are NaN. Only the
+// confirmed placement is free of lookahead.
+func SwingSeries(swings []Swing, n int, atConfirmation bool) (highs, lows []float64) {
+	highs, lows = make([]float64, n), make([]float64, n)
+	for i := range highs {
+		highs[i], lows[i] = math.NaN(), math.NaN()
+	}
+	for _, s := range swings {
+		i := s.Index
+		if atConfirmation {
+			i = s.Confirmed
+		}
+		if i < 0 || i >= n {
+			continue
+		}
+		if s.High {
+			highs[i] = s.Price
+		} else {
+			lows[i] = s.Price
+		}
+	}
+	return highs, lows
+}
+
+// SwingPivotStream is the streaming counterpart of SwingPivots.
+type SwingPivotStream struct {
+	left, right int
+	highs, lows *ring
+	found       []Swing
+	seen        int
+}
+
+// NewSwingPivots returns streaming pivot highs and lows with left and right
+// strength.
+func NewSwingPivots(left, right int) *SwingPivotStream {
+	if left < 0 || right < 0 {
+		panic("left and right must not be negative")
+	}
+	return &SwingPivotStream{
+		left:  left,
+		right: right,
+		highs: newRing(left + right + 1),
+		lows:  newRing(left + right + 1),
+	}
+}
+
+// NewFractals returns streaming Williams fractals.
+func NewFractals() *SwingPivotStream { return NewSwingPivots(2, 2) }
+
+// Update adds a bar and returns the pivots confirmed at it, right bars after
+// they occurred. The returned slice is reused by the next call.
+func (s *SwingPivotStream) Update(high, low float64) ([]Swing, bool) {
+	s.highs.push(high)
+	s.lows.push(low)
+	s.seen++
+	s.found = s.found[:0]
+	if !s.Ready() {
+		return s.found, false
+	}
+	index := s.seen - 1 - s.right
+	if isPivot(s.highs, s.left, 1) {
+		s.found = append(s.found, Swing{Index: index, Confirmed: s.seen - 1, Price: s.highs.at(s.left), High: true})
+	}
+	if isPivot(s.lows, s.left, -1) {
+		s.found = append(s.found, Swing{Index: index, Confirmed: s.seen - 1, Price: s.lows.at(s.left)})
+	}
+	return s.found, true
+}
+
+// isPivot reports whether the value at position center of window is above
+// (sign 1) or below (sign -1) the values before it and not exceeded by those
+// after it.
+func isPivot(window *ring, center int, sign float64) bool {
+	v := window.at(center)
+	if math.IsNaN(v) {
+		return false
+	}
+	for j := 0; j < window.count; j++ {
+		w := window.at(j)
+		switch {
+		case j == center:
+		case math.IsNaN(w):
+			return false
+		case sign*w > sign*v || (j < center && w == v):
+			return false
+		}
+	}
+	return true
+}
+
+// Ready reports whether enough bars have been seen to confirm a pivot.
+func (s *SwingPivotStream) Ready() bool { return s.highs.full() }
+
+// Reset clears the window.
+func (s *SwingPivotStream) Reset() {
+	s.highs.reset()
+	s.lows.reset()
+	s.seen = 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *SwingPivotStream) Lookback() int { return SwingPivotsLookback(s.left, s.right) }
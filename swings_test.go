@@ -0,0 +1,114 @@
+package indicators
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSwingPivots(t *testing.T) {
+	high := []float64{1, 2, 5, 3, 2, 4, 4, 3, 1}
+	// Of the equal highs at bars 5 and 6 only the first is a pivot; the low
+	// at bar 4 is confirmed two bars later.
+	want := []Swing{
+		{Index: 2, Confirmed: 4, Price: 5, High: true},
+		{Index: 4, Confirmed: 6, Price: 2},
+		{Index: 5, Confirmed: 7, Price: 4, High: true},
+	}
+	if got := SwingPivots(high, high, 2, 2); !slices.Equal(got, want) {
+		t.Errorf("SwingPivots = %+v, want %+v", got, want)
+	}
+	if got := PivotHighs(high, 2, 2); !slices.Equal(got, []Swing{want[0], want[2]}) {
+		t.Errorf("PivotHighs = %+v", got)
+	}
+	if got := PivotLows(high, 2, 2); !slices.Equal(got, want[1:2]) {
+		t.Errorf("PivotLows = %+v", got)
+	}
+	// A NaN in the window hides the pivot.
+	high[1] = math.NaN()
+	if got := PivotHighs(high, 2, 2); len(got) != 1 || got[0].Index != 5 {
+		t.Errorf("PivotHighs with a NaN = %+v", got)
+	}
+}
+
+func TestSwingPivotsMatchDefinition(t *testing.T) {
+	_, high, low, _, _ := testOHLCV(300)
+	for _, lr := range [][2]int{{2, 2}, {3, 1}, {0, 4}, {5, 0}} {
+		left, right := lr[0], lr[1]
+		var want []Swing
+		for i := left; i+right < len(high); i++ {
+			isHigh, isLow := true, true
+			for j := i - left; j <= i+right; j++ {
+				if j < i {
+					isHigh = isHigh && high[j] < high[i]
+					isLow = isLow && low[j] > low[i]
+				} else if j > i {
+					isHigh = isHigh && high[j] <= high[i]
+					isLow = isLow && low[j] >= low[i]
+				}
+			}
+			if isHigh {
+				want = append(want, Swing{Index: i, Confirmed: i + right, Price: high[i], High: true})
+			}
+			if isLow {
+				want = append(want, Swing{Index: i, Confirmed: i + right, Price: low[i]})
+			}
+		}
+		if got := SwingPivots(high, low, left, right); !slices.Equal(got, want) {
+			t.Errorf("left %d, right %d: %d swings, want %d", left, right, len(got), len(want))
+		}
+	}
+	if !slices.Equal(Fractals(high, low), SwingPivots(high, low, 2, 2)) {
+		t.Error("Fractals differ from 2/2 pivots")
+	}
+}
+
+func TestSwingPivotStream(t *testing.T) {
+	_, high, low, _, _ := testOHLCV(200)
+	want := SwingPivots(high, low, 3, 2)
+	s := NewSwingPivots(3, 2)
+	for pass := 0; pass < 2; pass++ {
+		var got []Swing
+		for i := range high {
+			found, ok := s.Update(high[i], low[i])
+			if ok != (i >= s.Lookback()) {
+				t.Fatalf("bar %d: ready %v", i, ok)
+			}
+			for _, sw := range found {
+				if sw.Confirmed != i {
+					t.Errorf("swing %+v returned at bar %d", sw, i)
+				}
+			}
+			got = append(got, found...)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("pass %d: stream differs from batch", pass)
+		}
+		s.Reset()
+	}
+}
+
+func TestSwingSeries(t *testing.T) {
+	swings := []Swing{{Index: 2, Confirmed: 4, Price: 5, High: true}, {Index: 4, Confirmed: 6, Price: 2}, {Index: 7, Confirmed: 9, Price: 1}}
+	nan := math.NaN()
+	highs, lows := SwingSeries(swings, 8, true)
+	assertClose(t, "confirmed highs", highs, []float64{nan, nan, nan, nan, 5, nan, nan, nan}, 0)
+	assertClose(t, "confirmed lows", lows, []float64{nan, nan, nan, nan, nan, nan, 2, nan}, 0)
+	highs, lows = SwingSeries(swings, 8, false)
+	assertClose(t, "highs", highs, []float64{nan, nan, 5, nan, nan, nan, nan, nan}, 0)
+	assertClose(t, "lows", lows, []float64{nan, nan, nan, nan, 2, nan, nan, 1}, 0)
+}
+
+func TestSwingPivotsChecked(t *testing.T) {
+	_, high, low, _, _ := testOHLCV(10)
+	if _, err := SwingPivotsChecked(high, low, -1, 2); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("negative left: %v", err)
+	}
+	if _, err := SwingPivotsChecked(high, low[1:], 2, 2); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("short low: %v", err)
+	}
+	if _, err := SwingPivotsChecked(high, low, 5, 5); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("10 bars for 5/5 pivots: %v", err)
+	}
+}
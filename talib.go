@@ -0,0 +1,68 @@
+package indicators
+
+// Pure-Go versions of the TA-Lib functions used by this package. They share
+// the cgo-talib signatures and output layout: every result has len(input)
+// values, with zeros in place of the lookback period.
+
+func goSma(real []float64, timePeriod int32) []float64 {
+	out := make([]float64, len(real))
+	sma := newTalibSMA(int(timePeriod))
+	for i, v := range real {
+		out[i], _ = sma.update(v)
+	}
+	return out
+}
+
+func goEma(real []float64, timePeriod int32) []float64 {
+	out := make([]float64, len(real))
+	ema := newTalibEMA(int(timePeriod))
+	for i, v := range real {
+		out[i], _ = ema.update(v)
+	}
+	return out
+}
+
+func goWma(real []float64, timePeriod int32) []float64 {
+	out := make([]float64, len(real))
+	wma := newTalibWMA(int(timePeriod))
+	for i, v := range real {
+		out[i], _ = wma.update(v)
+	}
+	return out
+}
+
+func goAtr(high, low, close []float64, timePeriod int32) []float64 {
+	out := make([]float64, len(high))
+	atr := newTalibATR(int(timePeriod))
+	for i := range high {
+		out[i], _ = atr.update(high[i], low[i], close[i])
+	}
+	return out
+}
+
+// goBBands only supports a simple moving average middle band (mAType 0);
+// other types yield zeros.
+func goBBands(real []float64, timePeriod int32, nbDevUp, nbDevDn float64, mAType int32) ([]float64, []float64, []float64) {
+	upper := make([]float64, len(real))
+	middle := make([]float64, len(real))
+	lower := make([]float64, len(real))
+	if mAType != 0 {
+		return upper, middle, lower
+	}
+	bbands := newTalibBBands(int(timePeriod), nbDevUp, nbDevDn)
+	for i, v := range real {
+		upper[i], middle[i], lower[i], _ = bbands.update(v)
+	}
+	return upper, middle, lower
+}
+
+func goMacd(real []float64, fastPeriod, slowPeriod, signalPeriod int32) ([]float64, []float64, []float64) {
+	macd := make([]float64, len(real))
+	signal := make([]float64, len(real))
+	hist := make([]float64, len(real))
+	m := newTalibMACD(int(fastPeriod), int(slowPeriod), int(signalPeriod))
+	for i, v := range real {
+		macd[i], signal[i], hist[i], _ = m.update(v)
+	}
+	return macd, signal, hist
+}
@@ -0,0 +1,16 @@
+//go:build cgotalib
+
+package indicators
+
+import cgotalib "github.com/blazer-org/cgo-talib"
+
+// TA-Lib backend used by the indicators, provided by the C library. Building
+// without the cgotalib tag selects the pure-Go implementations instead.
+var (
+	taSma    = cgotalib.Sma
+	taEma    = cgotalib.Ema
+	taWma    = cgotalib.Wma
+	taAtr    = cgotalib.Atr
+	taBBands = cgotalib.BBands
+	taMacd   = cgotalib.Macd
+)
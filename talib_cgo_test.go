@@ -0,0 +1,35 @@
+//go:build cgotalib
+
+package indicators
+
+import (
+	"testing"
+
+	cgotalib "github.com/blazer-org/cgo-talib"
+)
+
+// TestTalibKernelsMatchCgo checks the pure-Go kernels against the C library
+// they reproduce.
+func TestTalibKernelsMatchCgo(t *testing.T) {
+	_, high, low, close, _ := testOHLCV(500)
+	const tol = 1e-9
+	for _, p := range []int32{2, 5, 14, 30} {
+		assertClose(t, "sma", goSma(close, p), cgotalib.Sma(close, p), tol)
+		assertClose(t, "ema", goEma(close, p), cgotalib.Ema(close, p), tol)
+		assertClose(t, "wma", goWma(close, p), cgotalib.Wma(close, p), tol)
+		assertClose(t, "atr", goAtr(high, low, close, p), cgotalib.Atr(high, low, close, p), tol)
+
+		upper, middle, lower := goBBands(close, p, 2, 1.5, 0)
+		wantUpper, wantMiddle, wantLower := cgotalib.BBands(close, p, 2, 1.5, 0)
+		assertClose(t, "bbands upper", upper, wantUpper, tol)
+		assertClose(t, "bbands middle", middle, wantMiddle, tol)
+		assertClose(t, "bbands lower", lower, wantLower, tol)
+	}
+	for _, c := range [][3]int32{{12, 26, 9}, {5, 13, 1}, {3, 4, 5}} {
+		macd, signal, hist := goMacd(close, c[0], c[1], c[2])
+		wantMACD, wantSignal, wantHist := cgotalib.Macd(close, c[0], c[1], c[2])
+		assertClose(t, "macd", macd, wantMACD, tol)
+		assertClose(t, "macd signal", signal, wantSignal, tol)
+		assertClose(t, "macd hist", hist, wantHist, tol)
+	}
+}
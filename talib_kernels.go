@@ -0,0 +1,319 @@
+package indicators
+
+import "math"
+
+// The kernels below reproduce the recurrences of the TA-Lib functions used by
+// this package one value at a time, so that streaming indicators match the
+// batch results computed through cgo-talib. Like the cgo-talib wrappers they
+// emit 0 until the lookback is reached and stay at 0 for parameters TA-Lib
+// rejects.
+
+// talibPerToK converts a period to an EMA smoothing factor as TA-Lib does.
+func talibPerToK(period int) float64 {
+	return 2.0 / float64(period+1)
+}
+
+// talibSMA reproduces TA_SMA.
+type talibSMA struct {
+	period int
+	valid  bool
+	window *ring
+	total  float64
+}
+
+func newTalibSMA(period int) *talibSMA {
+	valid := period >= 2 && period <= 100000
+	if !valid {
+		period = 1
+	}
+	return &talibSMA{period: period, valid: valid, window: newRing(period)}
+}
+
+func (s *talibSMA) update(v float64) (float64, bool) {
+	if !s.valid {
+		return 0, false
+	}
+	s.total += v
+	s.window.push(v)
+	if !s.window.full() {
+		return 0, false
+	}
+	out := s.total / float64(s.period)
+	s.total -= s.window.at(0)
+	return out, true
+}
+
+func (s *talibSMA) reset() {
+	s.window.reset()
+	s.total = 0
+}
+
+// talibEMA reproduces TA_EMA, which seeds the average with the SMA of the
+// first period values.
+type talibEMA struct {
+	period int
+	k      float64
+	valid  bool
+	seen   int
+	prev   float64
+}
+
+func newTalibEMA(period int) *talibEMA {
+	return &talibEMA{
+		period: period,
+		k:      talibPerToK(period),
+		valid:  period >= 1 && period <= 100000,
+	}
+}
+
+func (e *talibEMA) update(v float64) (float64, bool) {
+	if !e.valid {
+		return 0, false
+	}
+	e.seen++
+	switch {
+	case e.seen < e.period:
+		e.prev += v
+		return 0, false
+	case e.seen == e.period:
+		e.prev += v
+		e.prev /= float64(e.period)
+	default:
+		e.prev = ((v - e.prev) * e.k) + e.prev
+	}
+	return e.prev, true
+}
+
+func (e *talibEMA) reset() {
+	e.seen = 0
+	e.prev = 0
+}
+
+// talibWMA reproduces TA_WMA, which maintains the weighted sum with a running
+// subtraction instead of re-weighting the window.
+type talibWMA struct {
+	period        int
+	valid         bool
+	divider       float64
+	window        *ring
+	periodSum     float64
+	periodSub     float64
+	trailingValue float64
+	seen          int
+}
+
+func newTalibWMA(period int) *talibWMA {
+	valid := period >= 2 && period <= 100000
+	if !valid {
+		period = 1
+	}
+	return &talibWMA{
+		period:  period,
+		valid:   valid,
+		divider: float64((period * (period + 1)) >> 1),
+		window:  newRing(period),
+	}
+}
+
+func (w *talibWMA) update(v float64) (float64, bool) {
+	if !w.valid {
+		return 0, false
+	}
+	w.seen++
+	w.window.push(v)
+	if w.seen < w.period {
+		w.periodSub += v
+		w.periodSum += v * float64(w.seen)
+		return 0, false
+	}
+	w.periodSub += v
+	w.periodSub -= w.trailingValue
+	w.periodSum += v * float64(w.period)
+	w.trailingValue = w.window.at(0)
+	out := w.periodSum / w.divider
+	w.periodSum -= w.periodSub
+	return out, true
+}
+
+func (w *talibWMA) reset() {
+	w.window.reset()
+	w.periodSum, w.periodSub, w.trailingValue = 0, 0, 0
+	w.seen = 0
+}
+
+// talibATR reproduces TA_ATR, Wilder's smoothing of the true range seeded
+// with the SMA of the first period true ranges.
+type talibATR struct {
+	period    int
+	valid     bool
+	prevClose float64
+	seen      int
+	prev      float64
+}
+
+func newTalibATR(period int) *talibATR {
+	return &talibATR{period: period, valid: period >= 1 && period <= 100000}
+}
+
+func (a *talibATR) update(high, low, close float64) (float64, bool) {
+	if !a.valid {
+		return 0, false
+	}
+	prevClose := a.prevClose
+	a.prevClose = close
+	a.seen++
+	if a.seen == 1 {
+		return 0, false
+	}
+
+	greatest := high - low
+	if val2 := math.Abs(prevClose - high); val2 > greatest {
+		greatest = val2
+	}
+	if val3 := math.Abs(prevClose - low); val3 > greatest {
+		greatest = val3
+	}
+
+	if a.period == 1 {
+		return greatest, true
+	}
+	switch trCount := a.seen - 1; {
+	case trCount < a.period:
+		a.prev += greatest
+		return 0, false
+	case trCount == a.period:
+		a.prev += greatest
+		a.prev /= float64(a.period)
+	default:
+		a.prev *= float64(a.period - 1)
+		a.prev += greatest
+		a.prev /= float64(a.period)
+	}
+	return a.prev, true
+}
+
+func (a *talibATR) reset() {
+	a.prevClose, a.prev = 0, 0
+	a.seen = 0
+}
+
+// talibMACD reproduces TA_MACD. Both averages start at the first bar where
+// the slow average is defined, so the fast one is seeded with the SMA of the
+// fast period values ending there rather than of the first ones.
+type talibMACD struct {
+	fast, slow, signal int
+	valid              bool
+	kFast, kSlow       float64
+	window             *ring
+	seen               int
+	slowEMA, fastEMA   float64
+	signalEMA          *talibEMA
+}
+
+func newTalibMACD(fast, slow, signal int) *talibMACD {
+	valid := fast >= 2 && fast <= 100000 &&
+		slow >= 2 && slow <= 100000 &&
+		signal >= 1 && signal <= 100000
+	if slow < fast {
+		fast, slow = slow, fast
+	}
+	if !valid {
+		fast, slow, signal = 1, 1, 1
+	}
+	return &talibMACD{
+		fast:      fast,
+		slow:      slow,
+		signal:    signal,
+		valid:     valid,
+		kFast:     talibPerToK(fast),
+		kSlow:     talibPerToK(slow),
+		window:    newRing(fast),
+		signalEMA: newTalibEMA(signal),
+	}
+}
+
+func (m *talibMACD) update(v float64) (macd, signal, hist float64, ready bool) {
+	if !m.valid {
+		return 0, 0, 0, false
+	}
+	m.seen++
+	m.window.push(v)
+	switch {
+	case m.seen < m.slow:
+		m.slowEMA += v
+		return 0, 0, 0, false
+	case m.seen == m.slow:
+		m.slowEMA += v
+		m.slowEMA /= float64(m.slow)
+		m.fastEMA = 0
+		for i := 0; i < m.fast; i++ {
+			m.fastEMA += m.window.at(i)
+		}
+		m.fastEMA /= float64(m.fast)
+	default:
+		m.slowEMA = ((v - m.slowEMA) * m.kSlow) + m.slowEMA
+		m.fastEMA = ((v - m.fastEMA) * m.kFast) + m.fastEMA
+	}
+
+	macd = m.fastEMA - m.slowEMA
+	signal, ready = m.signalEMA.update(macd)
+	if !ready {
+		return 0, 0, 0, false
+	}
+	return macd, signal, macd - signal, true
+}
+
+func (m *talibMACD) reset() {
+	m.window.reset()
+	m.seen = 0
+	m.slowEMA, m.fastEMA = 0, 0
+	m.signalEMA.reset()
+}
+
+// talibBBands reproduces TA_BBANDS with an SMA middle band, whose standard
+// deviation is derived from a running sum of squares around that SMA.
+type talibBBands struct {
+	sma              *talibSMA
+	squares          *ring
+	total2           float64
+	nbDevUp, nbDevDn float64
+}
+
+func newTalibBBands(period int, nbDevUp, nbDevDn float64) *talibBBands {
+	sma := newTalibSMA(period)
+	return &talibBBands{
+		sma:     sma,
+		squares: newRing(sma.period),
+		nbDevUp: nbDevUp,
+		nbDevDn: nbDevDn,
+	}
+}
+
+func (b *talibBBands) update(v float64) (upper, middle, lower float64, ready bool) {
+	if !b.sma.valid {
+		return 0, 0, 0, false
+	}
+	middle, ready = b.sma.update(v)
+	sq := v * v
+	b.total2 += sq
+	b.squares.push(sq)
+	if !ready {
+		return 0, 0, 0, false
+	}
+
+	meanValue2 := b.total2 / float64(b.sma.period)
+	b.total2 -= b.squares.at(0)
+	meanValue2 -= middle * middle
+
+	stdDev := 0.0
+	if !(meanValue2 < 0.00000001) {
+		stdDev = math.Sqrt(meanValue2)
+	}
+	return middle + stdDev*b.nbDevUp, middle, middle - stdDev*b.nbDevDn, true
+}
+
+func (b *talibBBands) reset() {
+	b.sma.reset()
+	b.squares.reset()
+	b.total2 = 0
+}
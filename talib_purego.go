@@ -0,0 +1,14 @@
+//go:build !cgotalib
+
+package indicators
+
+// TA-Lib backend used by the indicators. Build with the cgotalib tag to use
+// the C library through cgo-talib instead.
+var (
+	taSma    = goSma
+	taEma    = goEma
+	taWma    = goWma
+	taAtr    = goAtr
+	taBBands = goBBands
+	taMacd   = goMacd
+)
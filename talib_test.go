@@ -0,0 +1,159 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+// The reference functions below compute the TA-Lib functions from their
+// definitions, window by window, rather than with the running updates of
+// the kernels. Like cgo-talib they return len(input) values with zeros in
+// place of the lookback period.
+
+func refSMA(x []float64, period int) []float64 {
+	out := make([]float64, len(x))
+	for i := period - 1; i < len(x); i++ {
+		out[i] = naiveSum(x[i-period+1:i+1]) / float64(period)
+	}
+	return out
+}
+
+// refEMA seeds the average with the SMA of the period values ending at
+// start, and returns zeros before it.
+func refEMA(x []float64, period, start int) []float64 {
+	out := make([]float64, len(x))
+	if start >= len(x) {
+		return out
+	}
+	k := 2 / float64(period+1)
+	prev := naiveSum(x[start-period+1:start+1]) / float64(period)
+	out[start] = prev
+	for i := start + 1; i < len(x); i++ {
+		prev = k*x[i] + (1-k)*prev
+		out[i] = prev
+	}
+	return out
+}
+
+func refWMA(x []float64, period int) []float64 {
+	out := make([]float64, len(x))
+	divider := float64(period * (period + 1) / 2)
+	for i := period - 1; i < len(x); i++ {
+		var sum float64
+		for j := 0; j < period; j++ {
+			sum += float64(j+1) * x[i-period+1+j]
+		}
+		out[i] = sum / divider
+	}
+	return out
+}
+
+func refATR(high, low, close []float64, period int) []float64 {
+	out := make([]float64, len(close))
+	tr := make([]float64, len(close))
+	for i := 1; i < len(close); i++ {
+		tr[i] = math.Max(high[i]-low[i], math.Max(math.Abs(high[i]-close[i-1]), math.Abs(low[i]-close[i-1])))
+	}
+	if period >= len(close) {
+		return out
+	}
+	prev := naiveSum(tr[1:period+1]) / float64(period)
+	out[period] = prev
+	for i := period + 1; i < len(close); i++ {
+		prev = (prev*float64(period-1) + tr[i]) / float64(period)
+		out[i] = prev
+	}
+	return out
+}
+
+func refBBands(x []float64, period int, up, dn float64) (upper, middle, lower []float64) {
+	upper, middle, lower = make([]float64, len(x)), refSMA(x, period), make([]float64, len(x))
+	for i := period - 1; i < len(x); i++ {
+		var ss float64
+		for _, v := range x[i-period+1 : i+1] {
+			ss += (v - middle[i]) * (v - middle[i])
+		}
+		std := math.Sqrt(ss / float64(period))
+		upper[i], lower[i] = middle[i]+up*std, middle[i]-dn*std
+	}
+	return upper, middle, lower
+}
+
+// refMACD starts both averages where the slow one is defined and the signal
+// line where it is defined in turn.
+func refMACD(x []float64, fast, slow, signal int) (macd, sig, hist []float64) {
+	start := slow - 1
+	slowEMA, fastEMA := refEMA(x, slow, start), refEMA(x, fast, start)
+	line := make([]float64, len(x))
+	for i := start; i < len(x); i++ {
+		line[i] = fastEMA[i] - slowEMA[i]
+	}
+	end := start + signal - 1
+	signalEMA := make([]float64, len(x))
+	if end < len(x) {
+		copy(signalEMA[start:], refEMA(line[start:], signal, signal-1))
+	}
+	macd, sig, hist = make([]float64, len(x)), make([]float64, len(x)), make([]float64, len(x))
+	for i := end; i < len(x); i++ {
+		macd[i], sig[i], hist[i] = line[i], signalEMA[i], line[i]-signalEMA[i]
+	}
+	return macd, sig, hist
+}
+
+func TestTalibKernelsHandValues(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5}
+	assertClose(t, "sma", goSma(x, 3), []float64{0, 0, 2, 3, 4}, 1e-12)
+	assertClose(t, "ema", goEma(x, 3), []float64{0, 0, 2, 3, 4}, 1e-12)
+	// A period of 1, as for the MACD signal line, follows the input.
+	assertClose(t, "ema period 1", goEma(x, 1), x, 0)
+	assertClose(t, "wma", goWma(x, 3), []float64{0, 0, 14.0 / 6, 20.0 / 6, 26.0 / 6}, 1e-12)
+
+	// True ranges 2, 3 and 1; the ATR is seeded with the mean of the first
+	// two, then smoothed as (2.5 + 1) / 2.
+	high := []float64{10, 11, 13, 12}
+	low := []float64{9, 9, 11, 11}
+	close := []float64{10, 10, 12, 11.5}
+	assertClose(t, "atr", goAtr(high, low, close, 2), []float64{0, 0, 2.5, 1.75}, 1e-12)
+
+	upper, middle, lower := goBBands([]float64{1, 3, 1, 3}, 2, 2, 1, 0)
+	assertClose(t, "bbands middle", middle, []float64{0, 2, 2, 2}, 1e-12)
+	assertClose(t, "bbands upper", upper, []float64{0, 4, 4, 4}, 1e-12)
+	assertClose(t, "bbands lower", lower, []float64{0, 1, 1, 1}, 1e-12)
+}
+
+func TestTalibKernelsMatchReference(t *testing.T) {
+	_, high, low, close, _ := testOHLCV(300)
+	for _, period := range []int{2, 5, 14, 30} {
+		p := int32(period)
+		assertClose(t, "sma", goSma(close, p), refSMA(close, period), 1e-9)
+		assertClose(t, "ema", goEma(close, p), refEMA(close, period, period-1), 1e-9)
+		assertClose(t, "wma", goWma(close, p), refWMA(close, period), 1e-9)
+		assertClose(t, "atr", goAtr(high, low, close, p), refATR(high, low, close, period), 1e-9)
+
+		upper, middle, lower := goBBands(close, p, 2, 1.5, 0)
+		wantUpper, wantMiddle, wantLower := refBBands(close, period, 2, 1.5)
+		// TA-Lib derives the deviation from the mean of squares, which
+		// cancels with prices around 100.
+		assertClose(t, "bbands upper", upper, wantUpper, 1e-6)
+		assertClose(t, "bbands middle", middle, wantMiddle, 1e-9)
+		assertClose(t, "bbands lower", lower, wantLower, 1e-6)
+	}
+
+	for _, c := range [][3]int{{12, 26, 9}, {5, 13, 1}, {3, 4, 5}} {
+		macd, signal, hist := goMacd(close, int32(c[0]), int32(c[1]), int32(c[2]))
+		wantMACD, wantSignal, wantHist := refMACD(close, c[0], c[1], c[2])
+		assertClose(t, "macd", macd, wantMACD, 1e-9)
+		assertClose(t, "macd signal", signal, wantSignal, 1e-9)
+		assertClose(t, "macd hist", hist, wantHist, 1e-9)
+	}
+}
+
+func TestTalibKernelsRejectedPeriods(t *testing.T) {
+	x := []float64{1, 2, 3}
+	zeros := make([]float64, len(x))
+	assertClose(t, "sma", goSma(x, 1), zeros, 0)
+	assertClose(t, "ema", goEma(x, 0), zeros, 0)
+	assertClose(t, "wma", goWma(x, 0), zeros, 0)
+	_, middle, _ := goBBands(x, 2, 2, 2, 1)
+	assertClose(t, "bbands with an EMA middle band", middle, zeros, 0)
+}
@@ -1,10 +1,9 @@
 package indicators
 
-import (
-	cgotalib "github.com/blazer-org/cgo-talib"
-)
+import "math"
 
 // VolumeWeightedMACD calculates the Volume Weighted MACD for a given time series.
+// Values during warm-up are NaN.
 func VolumeWeightedMACD(close []float64, volume []float64, fastPeriod, slowPeriod, signalPeriod int) ([]float64, []float64, []float64) {
 	if len(close) != len(volume) {
 		return nil, nil, nil // Ensure close and volume have the same length
@@ -15,5 +14,180 @@ func VolumeWeightedMACD(close []float64, volume []float64, fastPeriod, slowPerio
 		vwClose[i] = (close[i] * volume[i])
 	}
 
-	return cgotalib.Macd(vwClose, int32(fastPeriod), int32(slowPeriod), int32(signalPeriod))
+	macd, signal, hist := taMacd(vwClose, int32(fastPeriod), int32(slowPeriod), int32(signalPeriod))
+	lookback := VolumeWeightedMACDLookback(fastPeriod, slowPeriod, signalPeriod)
+	for i := 0; i < len(macd) && i < lookback; i++ {
+		macd[i], signal[i], hist[i] = math.NaN(), math.NaN(), math.NaN()
+	}
+	return macd, signal, hist
+}
+
+// VolumeWeightedMACDMA is like VolumeWeightedMACD with the fast, slow and
+// signal averages of type t. MAEMA is the TA-Lib MACD of
+// VolumeWeightedMACD, with EMAs seeded by the SMA of their first values.
+func VolumeWeightedMACDMA(close, volume []float64, fastPeriod, slowPeriod, signalPeriod int, t MAType) ([]float64, []float64, []float64) {
+	if t == MAEMA {
+		return VolumeWeightedMACD(close, volume, fastPeriod, slowPeriod, signalPeriod)
+	}
+	if len(close) != len(volume) {
+		return nil, nil, nil
+	}
+	n := len(close)
+	macd, signal, hist := make([]float64, n), make([]float64, n), make([]float64, n)
+	s := NewVolumeWeightedMACDMA(fastPeriod, slowPeriod, signalPeriod, t)
+	for i := range close {
+		macd[i], signal[i], hist[i], _ = s.Update(close[i], volume[i])
+	}
+	return macd, signal, hist
+}
+
+// VolumeWeightedMACDChecked is like VolumeWeightedMACD but reports invalid
+// input as an error instead of returning nil slices.
+func VolumeWeightedMACDChecked(close []float64, volume []float64, fastPeriod, slowPeriod, signalPeriod int) (macd, signal, hist []float64, err error) {
+	if err = firstError(
+		checkLengths("VolumeWeightedMACD", len(close), len(volume)),
+		checkPeriod("VolumeWeightedMACD", "fastPeriod", fastPeriod, 2),
+		checkPeriod("VolumeWeightedMACD", "slowPeriod", slowPeriod, 2),
+		checkPeriod("VolumeWeightedMACD", "signalPeriod", signalPeriod, 1),
+		checkData("VolumeWeightedMACD", len(close), VolumeWeightedMACDLookback(fastPeriod, slowPeriod, signalPeriod)),
+	); err != nil {
+		return nil, nil, nil, err
+	}
+	macd, signal, hist = VolumeWeightedMACD(close, volume, fastPeriod, slowPeriod, signalPeriod)
+	return macd, signal, hist, nil
+}
+
+// VolumeWeightedMACDMAChecked is like VolumeWeightedMACDMA but reports
+// invalid input as an error instead of returning nil slices.
+func VolumeWeightedMACDMAChecked(close []float64, volume []float64, fastPeriod, slowPeriod, signalPeriod int, t MAType) (macd, signal, hist []float64, err error) {
+	if t == MAEMA {
+		return VolumeWeightedMACDChecked(close, volume, fastPeriod, slowPeriod, signalPeriod)
+	}
+	if err = firstError(
+		checkLengths("VolumeWeightedMACD", len(close), len(volume)),
+		checkMA("VolumeWeightedMACD", "fastPeriod", fastPeriod, t),
+		checkMA("VolumeWeightedMACD", "slowPeriod", slowPeriod, t),
+		checkMA("VolumeWeightedMACD", "signalPeriod", signalPeriod, t),
+		checkData("VolumeWeightedMACD", len(close), VolumeWeightedMACDMALookback(fastPeriod, slowPeriod, signalPeriod, t)),
+	); err != nil {
+		return nil, nil, nil, err
+	}
+	macd, signal, hist = VolumeWeightedMACDMA(close, volume, fastPeriod, slowPeriod, signalPeriod, t)
+	return macd, signal, hist, nil
+}
+
+// VolumeWeightedMACDBars calculates VolumeWeightedMACD over the closes and
+// volumes of b.
+func VolumeWeightedMACDBars(b *Bars, fastPeriod, slowPeriod, signalPeriod int) (macd, signal, hist []float64, err error) {
+	return VolumeWeightedMACDChecked(b.close, b.volume, fastPeriod, slowPeriod, signalPeriod)
+}
+
+// VolumeWeightedMACDMABars calculates VolumeWeightedMACDMA over the closes
+// and volumes of b.
+func VolumeWeightedMACDMABars(b *Bars, fastPeriod, slowPeriod, signalPeriod int, t MAType) (macd, signal, hist []float64, err error) {
+	return VolumeWeightedMACDMAChecked(b.close, b.volume, fastPeriod, slowPeriod, signalPeriod, t)
+}
+
+// VolumeWeightedMACDColumns returns the results of VolumeWeightedMACD as
+// named columns.
+func VolumeWeightedMACDColumns(macd, signal, hist []float64) []Column {
+	return []Column{
+		{Name: "vw_macd", Values: macd},
+		{Name: "vw_macd_signal", Values: signal},
+		{Name: "vw_macd_hist", Values: hist},
+	}
+}
+
+// VolumeWeightedMACDLookback returns the number of leading warm-up values
+// of VolumeWeightedMACD.
+func VolumeWeightedMACDLookback(fastPeriod, slowPeriod, signalPeriod int) int {
+	if slowPeriod < fastPeriod {
+		slowPeriod = fastPeriod
+	}
+	return slowPeriod - 1 + signalPeriod - 1
+}
+
+// VolumeWeightedMACDMALookback returns the number of leading warm-up values
+// of VolumeWeightedMACDMA.
+func VolumeWeightedMACDMALookback(fastPeriod, slowPeriod, signalPeriod int, t MAType) int {
+	if t == MAEMA {
+		return VolumeWeightedMACDLookback(fastPeriod, slowPeriod, signalPeriod)
+	}
+	return max(MALookback(fastPeriod, t), MALookback(slowPeriod, t)) + MALookback(signalPeriod, t)
+}
+
+// VolumeWeightedMACDStream is the streaming counterpart of
+// VolumeWeightedMACD.
+type VolumeWeightedMACDStream struct {
+	macd *talibMACD
+	// fast, slow and signal are set instead of macd for other types than
+	// MAEMA.
+	fast, slow, signal *MAStream
+}
+
+// NewVolumeWeightedMACD returns a streaming Volume Weighted MACD.
+func NewVolumeWeightedMACD(fastPeriod, slowPeriod, signalPeriod int) *VolumeWeightedMACDStream {
+	return &VolumeWeightedMACDStream{macd: newTalibMACD(fastPeriod, slowPeriod, signalPeriod)}
+}
+
+// NewVolumeWeightedMACDMA returns a streaming Volume Weighted MACD with
+// averages of type t.
+func NewVolumeWeightedMACDMA(fastPeriod, slowPeriod, signalPeriod int, t MAType) *VolumeWeightedMACDStream {
+	if t == MAEMA {
+		return NewVolumeWeightedMACD(fastPeriod, slowPeriod, signalPeriod)
+	}
+	return &VolumeWeightedMACDStream{
+		fast:   NewMA(t, fastPeriod),
+		slow:   NewMA(t, slowPeriod),
+		signal: NewMA(t, signalPeriod),
+	}
+}
+
+// Update adds a bar and returns the MACD, signal and histogram values, or
+// NaNs during warm-up.
+func (s *VolumeWeightedMACDStream) Update(close, volume float64) (macd, signal, hist float64, ready bool) {
+	if s.fast == nil {
+		if macd, signal, hist, ready = s.macd.update(close * volume); !ready {
+			return math.NaN(), math.NaN(), math.NaN(), false
+		}
+		return macd, signal, hist, true
+	}
+	fast, fastReady := s.fast.Update(close * volume)
+	slow, slowReady := s.slow.Update(close * volume)
+	if !fastReady || !slowReady {
+		return math.NaN(), math.NaN(), math.NaN(), false
+	}
+	macd = fast - slow
+	if signal, ready = s.signal.Update(macd); !ready {
+		return math.NaN(), math.NaN(), math.NaN(), false
+	}
+	return macd, signal, macd - signal, true
+}
+
+// Ready reports whether the signal line has left its warm-up period.
+func (s *VolumeWeightedMACDStream) Ready() bool {
+	if s.fast != nil {
+		return s.signal.Ready()
+	}
+	m := s.macd
+	return m.valid && m.seen >= m.slow+m.signal-1
+}
+
+// Reset clears all averages.
+func (s *VolumeWeightedMACDStream) Reset() {
+	if s.fast != nil {
+		s.fast.Reset()
+		s.slow.Reset()
+		s.signal.Reset()
+		return
+	}
+	s.macd.reset()
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *VolumeWeightedMACDStream) Lookback() int {
+	if s.fast != nil {
+		return max(s.fast.Lookback(), s.slow.Lookback()) + s.signal.Lookback()
+	}
+	return s.macd.slow - 1 + s.macd.signal - 1
 }
@@ -1,6 +1,9 @@
 package indicators
 
+import "math"
+
 // VWRSI implements the Volume Weighted Relative Strength Index (VWRSI) indicator.
+// Values during warm-up are NaN.
 func VWRSI(prices []float64, volumes []float64, period int) []float64 {
 	if len(prices) != len(volumes) {
 		return nil
@@ -10,11 +13,10 @@ func VWRSI(prices []float64, volumes []float64, period int) []float64 {
 		return nil
 	}
 
-	if len(prices) < period {
-		return nil
-	}
-
 	vwrsis := make([]float64, len(prices))
+	for i := 0; i < len(prices) && i < VWRSILookback(period); i++ {
+		vwrsis[i] = math.NaN()
+	}
 	gains := make([]float64, len(prices))
 	losses := make([]float64, len(prices))
 
@@ -29,14 +31,15 @@ func VWRSI(prices []float64, volumes []float64, period int) []float64 {
 		}
 	}
 
-	for i := period; i < len(prices); i++ {
-		sumGain := 0.0
-		sumLoss := 0.0
-
-		for j := i - period + 1; j <= i; j++ {
-			sumGain += gains[j]
-			sumLoss += losses[j]
+	sumGains, sumLosses := newRollingSum(period), newRollingSum(period)
+	for i := 1; i < len(prices); i++ {
+		sumGains.push(gains[i])
+		sumLosses.push(losses[i])
+		if i < period {
+			continue
 		}
+		sumGain := sumGains.value()
+		sumLoss := sumLosses.value()
 
 		if sumLoss == 0 {
 			vwrsis[i] = 100
@@ -48,3 +51,84 @@ func VWRSI(prices []float64, volumes []float64, period int) []float64 {
 
 	return vwrsis
 }
+
+// VWRSIChecked is like VWRSI but reports invalid input as an error instead
+// of returning nil.
+func VWRSIChecked(prices []float64, volumes []float64, period int) ([]float64, error) {
+	if err := firstError(
+		checkLengths("VWRSI", len(prices), len(volumes)),
+		checkPeriod("VWRSI", "period", period, 1),
+		checkData("VWRSI", len(prices), VWRSILookback(period)),
+	); err != nil {
+		return nil, err
+	}
+	return VWRSI(prices, volumes, period), nil
+}
+
+// VWRSIBars calculates VWRSI over the closes and volumes of b.
+func VWRSIBars(b *Bars, period int) ([]float64, error) {
+	return VWRSIChecked(b.close, b.volume, period)
+}
+
+// VWRSILookback returns the number of leading warm-up values of VWRSI.
+func VWRSILookback(period int) int {
+	return period
+}
+
+// VWRSIStream is the streaming counterpart of VWRSI.
+type VWRSIStream struct {
+	period    int
+	gains     *rollingSum
+	losses    *rollingSum
+	prevPrice float64
+	seen      int
+}
+
+// NewVWRSI returns a streaming Volume Weighted RSI over period bars.
+func NewVWRSI(period int) *VWRSIStream {
+	if period <= 0 {
+		panic("period must be greater than 0")
+	}
+	return &VWRSIStream{period: period, gains: newRollingSum(period), losses: newRollingSum(period)}
+}
+
+// Update adds a bar and returns the current VWRSI, or NaN during warm-up.
+func (s *VWRSIStream) Update(price, volume float64) (float64, bool) {
+	if s.seen > 0 {
+		delta := price - s.prevPrice
+		if delta > 0 {
+			s.gains.push(delta * volume)
+			s.losses.push(0)
+		} else {
+			s.gains.push(0)
+			s.losses.push(-delta * volume)
+		}
+	}
+	s.prevPrice = price
+	s.seen++
+
+	if !s.Ready() {
+		return math.NaN(), false
+	}
+	sumGain := s.gains.value()
+	sumLoss := s.losses.value()
+	if sumLoss == 0 {
+		return 100, true
+	}
+	rs := sumGain / sumLoss
+	return 100 - (100 / (1 + rs)), true
+}
+
+// Ready reports whether period price changes have been seen.
+func (s *VWRSIStream) Ready() bool { return s.gains.full() }
+
+// Reset clears the window.
+func (s *VWRSIStream) Reset() {
+	s.gains.reset()
+	s.losses.reset()
+	s.prevPrice = 0
+	s.seen = 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *VWRSIStream) Lookback() int { return s.period }
@@ -10,14 +10,18 @@ type VortexResult struct {
 	VIMinus []float64
 }
 
-// Vortex calculates the Vortex Indicator (VI+ and VI-) for given high, low, close data
+// Vortex calculates the Vortex Indicator (VI+ and VI-) for given high, low, close data.
+// Values during warm-up are NaN.
 func Vortex(highs, lows, closes []float64, period int) *VortexResult {
 	n := len(highs)
 	if n != len(lows) || n != len(closes) {
 		return &VortexResult{}
 	}
-	if n < period+1 {
-		return &VortexResult{}
+	if period <= 0 || n < period+1 {
+		return &VortexResult{
+			VIPlus:  constant(n, math.NaN()),
+			VIMinus: constant(n, math.NaN()),
+		}
 	}
 
 	vmPlus := make([]float64, n)
@@ -39,17 +43,20 @@ func Vortex(highs, lows, closes []float64, period int) *VortexResult {
 
 	viPlus := make([]float64, n)
 	viMinus := make([]float64, n)
+	viPlus[0], viMinus[0] = math.NaN(), math.NaN()
 
 	// Rolling sums
-	for i := period; i < n; i++ {
-		var sumVMPlus, sumVMMinus, sumTR float64
-		for j := i - period + 1; j <= i; j++ {
-			sumVMPlus += vmPlus[j]
-			sumVMMinus += vmMinus[j]
-			sumTR += tr[j]
+	sumVMPlus, sumVMMinus, sumTR := newRollingSum(period), newRollingSum(period), newRollingSum(period)
+	for i := 1; i < n; i++ {
+		sumVMPlus.push(vmPlus[i])
+		sumVMMinus.push(vmMinus[i])
+		sumTR.push(tr[i])
+		if i < period {
+			viPlus[i], viMinus[i] = math.NaN(), math.NaN()
+			continue
 		}
-		viPlus[i] = sumVMPlus / sumTR
-		viMinus[i] = sumVMMinus / sumTR
+		viPlus[i] = sumVMPlus.value() / sumTR.value()
+		viMinus[i] = sumVMMinus.value() / sumTR.value()
 	}
 
 	return &VortexResult{
@@ -57,3 +64,97 @@ func Vortex(highs, lows, closes []float64, period int) *VortexResult {
 		VIMinus: viMinus,
 	}
 }
+
+// VortexChecked is like Vortex but reports invalid input as an error instead
+// of returning an empty result.
+func VortexChecked(highs, lows, closes []float64, period int) (*VortexResult, error) {
+	if err := firstError(
+		checkLengths("Vortex", len(highs), len(lows), len(closes)),
+		checkPeriod("Vortex", "period", period, 1),
+		checkData("Vortex", len(highs), VortexLookback(period)),
+	); err != nil {
+		return nil, err
+	}
+	return Vortex(highs, lows, closes, period), nil
+}
+
+// VortexBars calculates Vortex over b.
+func VortexBars(b *Bars, period int) (*VortexResult, error) {
+	return VortexChecked(b.high, b.low, b.close, period)
+}
+
+// Columns returns VI+ and VI- as named columns.
+func (r *VortexResult) Columns() []Column {
+	return []Column{
+		{Name: "vi_plus", Values: r.VIPlus},
+		{Name: "vi_minus", Values: r.VIMinus},
+	}
+}
+
+// VortexLookback returns the number of leading warm-up values of Vortex.
+func VortexLookback(period int) int {
+	return period
+}
+
+// VortexStream is the streaming counterpart of Vortex.
+type VortexStream struct {
+	period  int
+	vmPlus  *rollingSum
+	vmMinus *rollingSum
+	tr      *rollingSum
+
+	prevHigh, prevLow, prevClose float64
+	seen                         int
+}
+
+// NewVortex returns a streaming Vortex Indicator over period bars.
+func NewVortex(period int) *VortexStream {
+	if period <= 0 {
+		panic("period must be greater than 0")
+	}
+	return &VortexStream{
+		period:  period,
+		vmPlus:  newRollingSum(period),
+		vmMinus: newRollingSum(period),
+		tr:      newRollingSum(period),
+	}
+}
+
+// Update adds a bar and returns VI+ and VI-, or NaNs during warm-up.
+func (s *VortexStream) Update(high, low, close float64) (viPlus, viMinus float64, ready bool) {
+	if s.seen > 0 {
+		upMove := math.Abs(high - s.prevLow)
+		downMove := math.Abs(low - s.prevHigh)
+		trueRange := math.Max(
+			math.Max(high-low, math.Abs(high-s.prevClose)),
+			math.Abs(low-s.prevClose),
+		)
+
+		s.vmPlus.push(upMove)
+		s.vmMinus.push(downMove)
+		s.tr.push(trueRange)
+	}
+	s.prevHigh, s.prevLow, s.prevClose = high, low, close
+	s.seen++
+
+	if !s.Ready() {
+		return math.NaN(), math.NaN(), false
+	}
+	sumTR := s.tr.value()
+	return s.vmPlus.value() / sumTR, s.vmMinus.value() / sumTR, true
+}
+
+// Ready reports whether period bar-to-bar movements have been seen.
+func (s *VortexStream) Ready() bool { return s.tr.full() }
+
+// Reset clears the window.
+func (s *VortexStream) Reset() {
+	s.vmPlus.reset()
+	s.vmMinus.reset()
+	s.tr.reset()
+	s.prevHigh, s.prevLow, s.prevClose = 0, 0, 0
+	s.seen = 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *VortexStream) Lookback() int { return s.period }
@@ -0,0 +1,68 @@
+package indicators
+
+import "math"
+
+// Warmup selects how the values of an indicator's warm-up period are
+// reported.
+type Warmup int
+
+const (
+	// WarmupNaN replaces warm-up values with NaN.
+	WarmupNaN Warmup = iota
+	// WarmupZero replaces warm-up values with 0.
+	WarmupZero
+	// WarmupTrim drops warm-up values from the start of the series.
+	WarmupTrim
+)
+
+// String returns the policy name.
+func (w Warmup) String() string {
+	switch w {
+	case WarmupNaN:
+		return "nan"
+	case WarmupZero:
+		return "zero"
+	case WarmupTrim:
+		return "trim"
+	}
+	return "unknown"
+}
+
+// ApplyWarmup applies policy to the first lookback values of series, as
+// reported by the indicator's Lookback function. Every batch indicator
+// returns one value per input bar, NaN where it is not yet defined, so the
+// same call normalizes any of them. WarmupNaN leaves series as it is, and
+// WarmupZero replaces the NaNs among the first lookback values with 0 in
+// place; both return series. Outputs of a multi-output indicator that are
+// defined before its lookback, such as the Ichimoku tenkan-sen, keep those
+// values, and undefined values past the lookback, such as the trailing
+// chikou span, are left alone. WarmupTrim returns the sub-slice following
+// the warm-up period, so that all outputs stay aligned.
+func ApplyWarmup(series []float64, lookback int, policy Warmup) []float64 {
+	if lookback > len(series) {
+		lookback = len(series)
+	}
+	if lookback < 0 {
+		lookback = 0
+	}
+	switch policy {
+	case WarmupTrim:
+		return series[lookback:]
+	case WarmupZero:
+		for i := 0; i < lookback; i++ {
+			if math.IsNaN(series[i]) {
+				series[i] = 0
+			}
+		}
+	}
+	return series
+}
+
+// constant returns a series of n copies of v.
+func constant(n int, v float64) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = v
+	}
+	return out
+}
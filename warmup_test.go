@@ -0,0 +1,101 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+// earlyOutputs are outputs of multi-output indicators that are defined
+// before the lookback of the indicator, which is that of its slowest output.
+var earlyOutputs = map[string]bool{
+	"tenkan_sen":    true,
+	"kijun_sen":     true,
+	"senkou_span_a": true,
+	"chikou_span":   true,
+	"stoch_k":       true,
+	"kvo":           true,
+}
+
+func TestRegistryWarmupIsNaN(t *testing.T) {
+	b := testBars(400)
+	for _, name := range Names() {
+		d, _ := Lookup(name)
+		// The defaults, and every choice of every choice parameter.
+		variants := []Params{nil}
+		for _, p := range d.Params {
+			for _, c := range p.Choices {
+				variants = append(variants, Params{p.Name: c})
+			}
+		}
+		for _, v := range variants {
+			p, err := d.Resolve(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cols, err := d.Compute(b, p)
+			if err != nil {
+				// Such as an HMA signal line shorter than 4 bars.
+				continue
+			}
+			lookback := d.Lookback(p)
+			for _, c := range cols {
+				if len(c.Values) != b.Len() {
+					t.Errorf("%s %v: %s has %d values, want %d", name, v, c.Name, len(c.Values), b.Len())
+					continue
+				}
+				for i, x := range c.Values {
+					if i < lookback && !math.IsNaN(x) && !earlyOutputs[c.Name] {
+						t.Errorf("%s %v: %s[%d] = %v during warm-up of %d bars", name, v, c.Name, i, x, lookback)
+						break
+					}
+					if math.IsInf(x, 0) {
+						t.Errorf("%s %v: %s[%d] = %v", name, v, c.Name, i, x)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	for name, got := range map[string][]float64{
+		"SMA":        SMA(nil, 3),
+		"EMA":        EMA(nil, 3),
+		"RollingStd": RollingStd(nil, 3),
+		"ZScore":     ZScore(nil, 3),
+		"HMA":        HMA(nil, 4),
+	} {
+		if got == nil || len(got) != 0 {
+			t.Errorf("%s of no values = %#v, want an empty slice", name, got)
+		}
+	}
+}
+
+func TestApplyWarmup(t *testing.T) {
+	nan := math.NaN()
+	series := func() []float64 { return []float64{nan, 5, nan, 1, 2, nan} }
+
+	assertClose(t, "nan", ApplyWarmup(series(), 3, WarmupNaN), series(), 0)
+	// Only the undefined values of the warm-up become 0; a value defined
+	// early and the trailing NaN are kept.
+	assertClose(t, "zero", ApplyWarmup(series(), 3, WarmupZero), []float64{0, 5, 0, 1, 2, nan}, 0)
+	assertClose(t, "trim", ApplyWarmup(series(), 3, WarmupTrim), []float64{1, 2, nan}, 0)
+	if got := ApplyWarmup(series(), 10, WarmupTrim); len(got) != 0 {
+		t.Errorf("trim past the end = %v, want empty", got)
+	}
+	assertClose(t, "negative", ApplyWarmup(series(), -1, WarmupZero), series(), 0)
+}
+
+func TestIchimokuChikouSurvivesWarmup(t *testing.T) {
+	_, high, low, close, _ := testOHLCV(200)
+	r := Ichimoku(high, low, close)
+	got := ApplyWarmup(r.ChikouSpan, IchimokuLookback(), WarmupZero)
+	// The chikou span is the close plotted 26 bars back.
+	if got[0] != close[26] {
+		t.Errorf("chikou_span[0] = %v, want the close 26 bars later, %v", got[0], close[26])
+	}
+	if last := got[len(got)-1]; !math.IsNaN(last) {
+		t.Errorf("chikou_span of the last bar = %v, want NaN", last)
+	}
+}
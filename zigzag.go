@@ -0,0 +1,176 @@
+package indicators
+
+import "fmt"
+
+// ZigZagOptions sets the reversal threshold of ZigZag: a move of Percent
+// percent of the extreme price, or, if ATRPeriod is positive, of
+// ATRMultiplier times the Wilder ATR over ATRPeriod bars.
+type ZigZagOptions struct {
+	Percent       float64 // defaults to 5
+	ATRPeriod     int
+	ATRMultiplier float64 // defaults to 3
+}
+
+func (o ZigZagOptions) withDefaults() ZigZagOptions {
+	if o.Percent <= 0 {
+		o.Percent = 5
+	}
+	if o.ATRMultiplier <= 0 {
+		o.ATRMultiplier = 3
+	}
+	return o
+}
+
+// ZigZag finds alternating swing highs and lows separated by moves of at
+// least the reversal threshold. A swing is confirmed at the bar whose low
+// (or high) reverses from it by the threshold. The extreme of the last leg
+// is never confirmed and is left out; see ZigZagStream.Pending.
+func ZigZag(high, low, close []float64, opt ZigZagOptions) []Swing {
+	n := len(close)
+	if len(high) != n || len(low) != n {
+		return nil
+	}
+	var swings []Swing
+	s := NewZigZag(opt)
+	for i := 0; i < n; i++ {
+		if sw, ok := s.Update(high[i], low[i], close[i]); ok {
+			swings = append(swings, sw)
+		}
+	}
+	return swings
+}
+
+// ZigZagChecked is like ZigZag but reports invalid input as an error.
+func ZigZagChecked(high, low, close []float64, opt ZigZagOptions) ([]Swing, error) {
+	if opt.Percent < 0 || opt.ATRPeriod < 0 || opt.ATRMultiplier < 0 {
+		return nil, &InputError{Func: "ZigZag", Err: ErrInvalidPeriod, Detail: fmt.Sprintf("%+v", opt)}
+	}
+	if err := firstError(
+		checkLengths("ZigZag", len(close), len(high), len(low)),
+		checkData("ZigZag", len(close), ZigZagLookback(opt)),
+	); err != nil {
+		return nil, err
+	}
+	return ZigZag(high, low, close, opt), nil
+}
+
+// ZigZagBars calculates ZigZag over b.
+func ZigZagBars(b *Bars, opt ZigZagOptions) ([]Swing, error) {
+	return ZigZagChecked(b.high, b.low, b.close, opt)
+}
+
+// ZigZagLookback returns the number of bars before the reversal threshold is
+// defined: the lookback of the ATR, or none for a percentage.
+func ZigZagLookback(opt ZigZagOptions) int {
+	if opt.ATRPeriod > 0 {
+		return ATRWilder.lookback(opt.ATRPeriod)
+	}
+	return 0
+}
+
+// ZigZagStream is the streaming counterpart of ZigZag.
+type ZigZagStream struct {
+	opt ZigZagOptions
+	atr atrKernel
+
+	// Before the first swing both extremes are tracked.
+	trend               int // 1 in an up leg, -1 in a down leg, 0 before the first swing
+	high, low           float64
+	highIndex, lowIndex int
+	seen                int
+}
+
+// NewZigZag returns a streaming ZigZag.
+func NewZigZag(opt ZigZagOptions) *ZigZagStream {
+	opt = opt.withDefaults()
+	s := &ZigZagStream{opt: opt}
+	if opt.ATRPeriod > 0 {
+		s.atr = newATRKernel(ATRWilder, opt.ATRPeriod)
+	}
+	return s
+}
+
+// Update adds a bar and returns the swing confirmed at it, if any.
+func (s *ZigZagStream) Update(high, low, close float64) (Swing, bool) {
+	i := s.seen
+	s.seen++
+	threshold := func(price float64) (float64, bool) {
+		return price * s.opt.Percent / 100, true
+	}
+	if s.atr != nil {
+		atr, ok := s.atr.update(high, low, close)
+		threshold = func(float64) (float64, bool) { return s.opt.ATRMultiplier * atr, ok }
+	}
+
+	if i == 0 {
+		s.high, s.highIndex, s.low, s.lowIndex = high, i, low, i
+		return Swing{}, false
+	}
+
+	switch s.trend {
+	case 0:
+		if high > s.high {
+			s.high, s.highIndex = high, i
+		}
+		if low < s.low {
+			s.low, s.lowIndex = low, i
+		}
+		// The first swing is whichever extreme came first once the range
+		// between them exceeds the threshold.
+		if t, ok := threshold(s.low); ok && s.high-s.low >= t {
+			if s.lowIndex < s.highIndex {
+				s.trend = 1
+				return Swing{Index: s.lowIndex, Confirmed: i, Price: s.low}, true
+			}
+			s.trend = -1
+			return Swing{Index: s.highIndex, Confirmed: i, Price: s.high, High: true}, true
+		}
+	case 1:
+		if high > s.high {
+			s.high, s.highIndex = high, i
+			return Swing{}, false
+		}
+		if t, ok := threshold(s.high); ok && s.high-low >= t {
+			sw := Swing{Index: s.highIndex, Confirmed: i, Price: s.high, High: true}
+			s.trend, s.low, s.lowIndex = -1, low, i
+			return sw, true
+		}
+	case -1:
+		if low < s.low {
+			s.low, s.lowIndex = low, i
+			return Swing{}, false
+		}
+		if t, ok := threshold(s.low); ok && high-s.low >= t {
+			sw := Swing{Index: s.lowIndex, Confirmed: i, Price: s.low}
+			s.trend, s.high, s.highIndex = 1, high, i
+			return sw, true
+		}
+	}
+	return Swing{}, false
+}
+
+// Pending returns the extreme of the current leg, which becomes the next
+// swing if price reverses from it by the threshold.
+func (s *ZigZagStream) Pending() (Swing, bool) {
+	switch s.trend {
+	case 1:
+		return Swing{Index: s.highIndex, Confirmed: -1, Price: s.high, High: true}, true
+	case -1:
+		return Swing{Index: s.lowIndex, Confirmed: -1, Price: s.low}, true
+	}
+	return Swing{}, false
+}
+
+// Ready reports whether the reversal threshold is defined.
+func (s *ZigZagStream) Ready() bool { return s.seen > s.Lookback() }
+
+// Reset clears the legs and the ATR.
+func (s *ZigZagStream) Reset() {
+	if s.atr != nil {
+		s.atr.reset()
+	}
+	s.trend, s.seen = 0, 0
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *ZigZagStream) Lookback() int { return ZigZagLookback(s.opt) }
@@ -0,0 +1,86 @@
+package indicators
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestZigZagPercent(t *testing.T) {
+	close := waypoints([2]float64{0, 100}, [2]float64{10, 110}, [2]float64{16, 104},
+		[2]float64{26, 115}, [2]float64{40, 100})
+	// Each reversal is confirmed once price has moved 5% from the extreme:
+	// 100 to 105, 110 to 104.5, 104 to 109.2 and 115 to 109.25.
+	want := []Swing{
+		{Index: 0, Confirmed: 5, Price: 100},
+		{Index: 10, Confirmed: 16, Price: 110, High: true},
+		{Index: 16, Confirmed: 21, Price: 104},
+		{Index: 26, Confirmed: 32, Price: 115, High: true},
+	}
+	if got := ZigZag(close, close, close, ZigZagOptions{}); !slices.Equal(got, want) {
+		t.Errorf("ZigZag = %+v, want %+v", got, want)
+	}
+	// At 10% the pullback to 104 is ignored.
+	got := ZigZag(close, close, close, ZigZagOptions{Percent: 10})
+	if len(got) != 2 || got[0].Index != 0 || got[1].Index != 26 {
+		t.Errorf("ZigZag at 10%% = %+v", got)
+	}
+
+	s := NewZigZag(ZigZagOptions{})
+	for i := range close {
+		s.Update(close[i], close[i], close[i])
+	}
+	if p, ok := s.Pending(); !ok || p.Index != 40 || p.High || p.Confirmed != -1 {
+		t.Errorf("Pending = %+v, %v", p, ok)
+	}
+}
+
+func TestZigZagAlternates(t *testing.T) {
+	_, high, low, close, _ := testOHLCV(500)
+	for _, opt := range []ZigZagOptions{{Percent: 1}, {ATRPeriod: 14, ATRMultiplier: 2}} {
+		swings := ZigZag(high, low, close, opt)
+		if len(swings) < 4 {
+			t.Fatalf("%+v: only %d swings", opt, len(swings))
+		}
+		for k, sw := range swings {
+			if sw.Confirmed < sw.Index || sw.Confirmed < ZigZagLookback(opt) {
+				t.Errorf("%+v: swing %+v confirmed too early", opt, sw)
+			}
+			if k > 0 && sw.High == swings[k-1].High {
+				t.Errorf("%+v: swings %d and %d are both highs or both lows", opt, k-1, k)
+			}
+		}
+	}
+}
+
+func TestZigZagStreamReset(t *testing.T) {
+	_, high, low, close, _ := testOHLCV(300)
+	opt := ZigZagOptions{ATRPeriod: 10}
+	want := ZigZag(high, low, close, opt)
+	s := NewZigZag(opt)
+	for pass := 0; pass < 2; pass++ {
+		var got []Swing
+		for i := range close {
+			if sw, ok := s.Update(high[i], low[i], close[i]); ok {
+				got = append(got, sw)
+			}
+			if s.Ready() != (i >= s.Lookback()) {
+				t.Fatalf("bar %d: ready %v", i, s.Ready())
+			}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("pass %d: stream differs from batch", pass)
+		}
+		s.Reset()
+	}
+}
+
+func TestZigZagChecked(t *testing.T) {
+	_, high, low, close, _ := testOHLCV(10)
+	if _, err := ZigZagChecked(high, low, close, ZigZagOptions{Percent: -1}); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("negative percent: %v", err)
+	}
+	if _, err := ZigZagChecked(high, low, close, ZigZagOptions{ATRPeriod: 14}); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("10 bars for a 14-bar ATR: %v", err)
+	}
+}
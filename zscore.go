@@ -1,20 +1,111 @@
 package indicators
 
+import "math"
+
 // CalculateZScore calculates the Z-Score for a given time series.
 func ZScore(data []float64, window int) []float64 {
-	std := RollingStd(data, window)
-	sma := SMA(data, window)
+	return ZScoreMA(data, window, MASMA)
+}
 
+// ZScoreMA is like ZScore with the mean taken by an average of type t. The
+// standard deviation is still that of the window around its simple mean.
+// Values during warm-up are NaN, and so is every value for windows the
+// average is not defined for.
+func ZScoreMA(data []float64, window int, t MAType) []float64 {
+	if window < t.minPeriod() {
+		return constant(len(data), math.NaN())
+	}
 	zScore := make([]float64, len(data))
 
-	// Calculate Z-Score
-	for i := range data {
-		if std[i] == 0 {
-			zScore[i] = 0 // Avoid division by zero
-		} else {
-			zScore[i] = (data[i] - sma[i]) / std[i]
-		}
+	s := NewZScoreMA(window, t)
+	for i, v := range data {
+		zScore[i], _ = s.Update(v)
 	}
 
 	return zScore
 }
+
+// ZScoreChecked is like ZScore but reports invalid input as an error.
+func ZScoreChecked(data []float64, window int) ([]float64, error) {
+	if err := firstError(
+		checkPeriod("ZScore", "window", window, 2),
+		checkData("ZScore", len(data), ZScoreLookback(window)),
+	); err != nil {
+		return nil, err
+	}
+	return ZScore(data, window), nil
+}
+
+// ZScoreMAChecked is like ZScoreMA but reports invalid input as an error.
+func ZScoreMAChecked(data []float64, window int, t MAType) ([]float64, error) {
+	if err := firstError(
+		checkPeriod("ZScore", "window", window, 2),
+		checkMA("ZScore", "window", window, t),
+		checkData("ZScore", len(data), ZScoreMALookback(window, t)),
+	); err != nil {
+		return nil, err
+	}
+	return ZScoreMA(data, window, t), nil
+}
+
+// ZScoreBars calculates ZScore over the closes of b.
+func ZScoreBars(b *Bars, window int) ([]float64, error) {
+	return ZScoreChecked(b.close, window)
+}
+
+// ZScoreMABars calculates ZScoreMA over the closes of b.
+func ZScoreMABars(b *Bars, window int, t MAType) ([]float64, error) {
+	return ZScoreMAChecked(b.close, window, t)
+}
+
+// ZScoreLookback returns the number of leading warm-up values of ZScore.
+func ZScoreLookback(window int) int {
+	return window - 1
+}
+
+// ZScoreMALookback returns the number of leading warm-up values of ZScoreMA.
+func ZScoreMALookback(window int, t MAType) int {
+	return max(window-1, MALookback(window, t))
+}
+
+// ZScoreStream is the streaming counterpart of ZScore.
+type ZScoreStream struct {
+	std  *RollingStdStream
+	mean *MAStream
+}
+
+// NewZScore returns a streaming Z-Score over window values.
+func NewZScore(window int) *ZScoreStream {
+	return NewZScoreMA(window, MASMA)
+}
+
+// NewZScoreMA returns a streaming Z-Score over window values with the mean
+// taken by an average of type t.
+func NewZScoreMA(window int, t MAType) *ZScoreStream {
+	return &ZScoreStream{std: NewRollingStd(window), mean: NewMA(t, window)}
+}
+
+// Update adds v and returns its current Z-Score, or NaN during warm-up.
+func (s *ZScoreStream) Update(v float64) (float64, bool) {
+	std, stdReady := s.std.Update(v)
+	mean, meanReady := s.mean.Update(v)
+	if !stdReady || !meanReady {
+		return math.NaN(), false
+	}
+	if std == 0 {
+		return 0, true // Avoid division by zero
+	}
+	return (v - mean) / std, true
+}
+
+// Ready reports whether a full window has been seen.
+func (s *ZScoreStream) Ready() bool { return s.std.Ready() && s.mean.Ready() }
+
+// Reset clears the window.
+func (s *ZScoreStream) Reset() {
+	s.std.Reset()
+	s.mean.Reset()
+}
+
+// Lookback returns the number of warm-up bars.
+func (s *ZScoreStream) Lookback() int { return max(s.std.Lookback(), s.mean.Lookback()) }